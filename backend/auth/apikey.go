@@ -0,0 +1,54 @@
+package auth
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"strings"
+)
+
+// APIKeyPrefix marks bearer credentials that are API keys rather than session tokens
+const APIKeyPrefix = "ek_"
+
+// GenerateAPIKey returns a new key of the form ek_<id>.<secret> along with its
+// id and the hash of its secret. Only the id and hash should be stored.
+func GenerateAPIKey() (key, id, secretHash string, err error) {
+	id, err = RandomID(8)
+	if err != nil {
+		return "", "", "", err
+	}
+	secret, err := RandomID(32)
+	if err != nil {
+		return "", "", "", err
+	}
+	return APIKeyPrefix + id + "." + secret, id, HashAPIKeySecret(secret), nil
+}
+
+// IsAPIKey reports whether a bearer credential looks like an API key
+func IsAPIKey(credential string) bool {
+	return strings.HasPrefix(credential, APIKeyPrefix)
+}
+
+// ParseAPIKey splits an API key into its id and secret
+func ParseAPIKey(key string) (id, secret string, ok bool) {
+	if !IsAPIKey(key) {
+		return "", "", false
+	}
+	id, secret, ok = strings.Cut(strings.TrimPrefix(key, APIKeyPrefix), ".")
+	if !ok || id == "" || secret == "" {
+		return "", "", false
+	}
+	return id, secret, true
+}
+
+// HashAPIKeySecret returns the hex SHA-256 of secret. API key secrets are
+// random and high-entropy, so a fast hash is sufficient.
+func HashAPIKeySecret(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
+
+// CheckAPIKeySecret compares secret against a stored hash in constant time
+func CheckAPIKeySecret(secretHash, secret string) bool {
+	return subtle.ConstantTimeCompare([]byte(secretHash), []byte(HashAPIKeySecret(secret))) == 1
+}
@@ -0,0 +1,38 @@
+package auth
+
+import "testing"
+
+func TestGenerateAndParseAPIKey(t *testing.T) {
+	key, id, secretHash, err := GenerateAPIKey()
+	if err != nil {
+		t.Fatalf("Failed to generate API key: %v", err)
+	}
+
+	if !IsAPIKey(key) {
+		t.Errorf("Expected %q to be recognised as an API key", key)
+	}
+
+	parsedID, secret, ok := ParseAPIKey(key)
+	if !ok {
+		t.Fatal("Expected generated key to parse")
+	}
+	if parsedID != id {
+		t.Errorf("Expected id %s, got %s", id, parsedID)
+	}
+	if !CheckAPIKeySecret(secretHash, secret) {
+		t.Error("Expected secret to match its hash")
+	}
+	if CheckAPIKeySecret(secretHash, secret+"x") {
+		t.Error("Expected altered secret not to match")
+	}
+}
+
+func TestParseAPIKey_Malformed(t *testing.T) {
+	testCases := []string{"", "ek_", "ek_abc", "ek_.secret", "ek_abc.", "abc.def"}
+
+	for _, tc := range testCases {
+		if _, _, ok := ParseAPIKey(tc); ok {
+			t.Errorf("Expected %q not to parse", tc)
+		}
+	}
+}
@@ -0,0 +1,30 @@
+package auth
+
+import "strings"
+
+// GenerateManageToken returns a new token of the form <attendeeID>.<secret>
+// that lets an attendee view, change or cancel their own registration, along
+// with the hash of its secret. Only the hash should be stored. Secrets are
+// hashed the same way as API key secrets.
+func GenerateManageToken(attendeeID string) (token, secretHash string, err error) {
+	secret, err := RandomID(32)
+	if err != nil {
+		return "", "", err
+	}
+	return attendeeID + "." + secret, HashAPIKeySecret(secret), nil
+}
+
+// ParseManageToken splits a manage token into the attendee ID and secret
+func ParseManageToken(token string) (attendeeID, secret string, ok bool) {
+	attendeeID, secret, ok = strings.Cut(token, ".")
+	if !ok || attendeeID == "" || secret == "" {
+		return "", "", false
+	}
+	return attendeeID, secret, true
+}
+
+// CheckManageToken compares secret against a stored hash in constant time.
+// Registrations made before manage tokens existed have no hash and never match.
+func CheckManageToken(secretHash, secret string) bool {
+	return secretHash != "" && CheckAPIKeySecret(secretHash, secret)
+}
@@ -0,0 +1,32 @@
+package auth
+
+import "testing"
+
+func TestGenerateAndParseManageToken(t *testing.T) {
+	token, secretHash, err := GenerateManageToken("attendee-1")
+	if err != nil {
+		t.Fatalf("Failed to generate manage token: %v", err)
+	}
+
+	id, secret, ok := ParseManageToken(token)
+	if !ok || id != "attendee-1" {
+		t.Fatalf("Expected token to parse to attendee-1, got %q (ok %v)", id, ok)
+	}
+	if !CheckManageToken(secretHash, secret) {
+		t.Error("Expected secret to match its hash")
+	}
+	if CheckManageToken(secretHash, secret+"x") {
+		t.Error("Expected altered secret not to match")
+	}
+	if CheckManageToken("", "") {
+		t.Error("Expected a missing hash never to match")
+	}
+}
+
+func TestParseManageToken_Malformed(t *testing.T) {
+	for _, token := range []string{"", "no-secret", ".secret", "id."} {
+		if _, _, ok := ParseManageToken(token); ok {
+			t.Errorf("Expected %q to be rejected", token)
+		}
+	}
+}
@@ -0,0 +1,169 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/coreos/go-oidc/v3/oidc"
+	"golang.org/x/oauth2"
+)
+
+// ErrOIDCAccessDenied is returned when a verified identity is not allowed to administer the event
+var ErrOIDCAccessDenied = errors.New("identity is not allowed admin access")
+
+// OIDCConfig configures single sign-on against an OpenID Connect provider
+type OIDCConfig struct {
+	IssuerURL      string
+	ClientID       string
+	ClientSecret   string
+	RedirectURL    string
+	AllowedDomains []string
+	// GroupsClaim names the ID-token claim holding the user's groups
+	GroupsClaim string
+	// RoleMapping maps a group name to the admin role it grants
+	RoleMapping map[string]Role
+	// DefaultRole is granted to allowed users in no mapped group; empty denies them
+	DefaultRole Role
+}
+
+// OIDCIdentity is the verified subset of ID-token claims used for admin access
+type OIDCIdentity struct {
+	Subject string
+	Email   string
+	Groups  []string
+	Role    Role
+}
+
+// OIDCProvider runs the authorization-code flow with PKCE against one issuer
+type OIDCProvider struct {
+	config   OIDCConfig
+	oauth2   oauth2.Config
+	verifier *oidc.IDTokenVerifier
+}
+
+// NewOIDCProvider fetches the issuer's discovery document and prepares the flow
+func NewOIDCProvider(ctx context.Context, config OIDCConfig) (*OIDCProvider, error) {
+	provider, err := oidc.NewProvider(ctx, config.IssuerURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to discover OIDC issuer: %w", err)
+	}
+	if config.GroupsClaim == "" {
+		config.GroupsClaim = "groups"
+	}
+
+	return &OIDCProvider{
+		config: config,
+		oauth2: oauth2.Config{
+			ClientID:     config.ClientID,
+			ClientSecret: config.ClientSecret,
+			RedirectURL:  config.RedirectURL,
+			Endpoint:     provider.Endpoint(),
+			Scopes:       []string{oidc.ScopeOpenID, "email", "profile"},
+		},
+		verifier: provider.Verifier(&oidc.Config{ClientID: config.ClientID}),
+	}, nil
+}
+
+// NewPKCEVerifier returns a fresh PKCE code verifier
+func NewPKCEVerifier() string {
+	return oauth2.GenerateVerifier()
+}
+
+// AuthCodeURL returns the provider URL to send the browser to. The verifier must
+// be kept server-side and passed back to Exchange.
+func (p *OIDCProvider) AuthCodeURL(state, nonce, verifier string) string {
+	return p.oauth2.AuthCodeURL(state, oidc.Nonce(nonce), oauth2.S256ChallengeOption(verifier))
+}
+
+// Exchange redeems an authorization code, verifies the ID token and nonce, and
+// maps the identity to an admin role
+func (p *OIDCProvider) Exchange(ctx context.Context, code, verifier, nonce string) (*OIDCIdentity, error) {
+	token, err := p.oauth2.Exchange(ctx, code, oauth2.VerifierOption(verifier))
+	if err != nil {
+		return nil, fmt.Errorf("failed to exchange authorization code: %w", err)
+	}
+
+	rawIDToken, ok := token.Extra("id_token").(string)
+	if !ok || rawIDToken == "" {
+		return nil, fmt.Errorf("token response did not include an id_token")
+	}
+
+	idToken, err := p.verifier.Verify(ctx, rawIDToken)
+	if err != nil {
+		return nil, fmt.Errorf("failed to verify id_token: %w", err)
+	}
+	if idToken.Nonce != nonce {
+		return nil, fmt.Errorf("id_token nonce mismatch")
+	}
+
+	var claims map[string]interface{}
+	if err := idToken.Claims(&claims); err != nil {
+		return nil, fmt.Errorf("failed to decode id_token claims: %w", err)
+	}
+
+	identity := &OIDCIdentity{Subject: idToken.Subject}
+	identity.Email, _ = claims["email"].(string)
+	if groups, ok := claims[p.config.GroupsClaim].([]interface{}); ok {
+		for _, g := range groups {
+			if name, ok := g.(string); ok {
+				identity.Groups = append(identity.Groups, name)
+			}
+		}
+	}
+
+	if verified, ok := claims["email_verified"].(bool); ok && !verified {
+		return nil, fmt.Errorf("%w: email address is not verified", ErrOIDCAccessDenied)
+	}
+	if !p.domainAllowed(identity.Email) {
+		return nil, fmt.Errorf("%w: email domain is not allowed", ErrOIDCAccessDenied)
+	}
+
+	identity.Role = p.roleFor(identity.Groups)
+	if identity.Role == "" {
+		return nil, fmt.Errorf("%w: no admin role mapped for this account", ErrOIDCAccessDenied)
+	}
+	return identity, nil
+}
+
+// domainAllowed reports whether email belongs to one of the allowed domains
+func (p *OIDCProvider) domainAllowed(email string) bool {
+	at := strings.LastIndex(email, "@")
+	if at < 0 {
+		return false
+	}
+	domain := strings.ToLower(email[at+1:])
+	for _, allowed := range p.config.AllowedDomains {
+		if domain == strings.ToLower(allowed) {
+			return true
+		}
+	}
+	return false
+}
+
+// roleFor returns the most privileged role mapped from groups, or the default role
+func (p *OIDCProvider) roleFor(groups []string) Role {
+	best := p.config.DefaultRole
+	for _, group := range groups {
+		if role, ok := p.config.RoleMapping[group]; ok && roleRank(role) > roleRank(best) {
+			best = role
+		}
+	}
+	return best
+}
+
+// roleRank orders roles from least to most privileged
+func roleRank(role Role) int {
+	switch role {
+	case RoleViewer:
+		return 1
+	case RoleDoorStaff:
+		return 2
+	case RoleEditor:
+		return 3
+	case RoleOwner:
+		return 4
+	}
+	return 0
+}
@@ -0,0 +1,112 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"testing"
+
+	"event-registration-backend/auth/oidctest"
+)
+
+// newTestOIDCProvider starts a fake issuer and a provider configured against it
+func newTestOIDCProvider(t *testing.T) (*OIDCProvider, *oidctest.Issuer) {
+	t.Helper()
+	issuer := oidctest.NewIssuer(t, "event-admin")
+	provider, err := NewOIDCProvider(context.Background(), OIDCConfig{
+		IssuerURL:      issuer.URL(),
+		ClientID:       "event-admin",
+		RedirectURL:    "http://localhost:8080/api/admin/oidc/callback",
+		AllowedDomains: []string{"example.com"},
+		RoleMapping: map[string]Role{
+			"event-volunteers": RoleViewer,
+			"event-organizers": RoleOwner,
+		},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create provider: %v", err)
+	}
+	return provider, issuer
+}
+
+// signIn runs the browser side of the flow and exchanges the resulting code
+func signIn(t *testing.T, provider *OIDCProvider, issuer *oidctest.Issuer, nonce string) (*OIDCIdentity, error) {
+	t.Helper()
+	verifier := NewPKCEVerifier()
+	redirect, err := issuer.Authorize(provider.AuthCodeURL("state-1", "expected-nonce", verifier))
+	if err != nil {
+		t.Fatalf("Authorize failed: %v", err)
+	}
+	u, err := url.Parse(redirect)
+	if err != nil {
+		t.Fatalf("Invalid redirect %q: %v", redirect, err)
+	}
+	if u.Query().Get("state") != "state-1" {
+		t.Errorf("Expected state to round-trip, got %q", u.Query().Get("state"))
+	}
+	return provider.Exchange(context.Background(), u.Query().Get("code"), verifier, nonce)
+}
+
+func TestOIDC_RoleFromGroups(t *testing.T) {
+	provider, issuer := newTestOIDCProvider(t)
+	issuer.SetUser(map[string]interface{}{
+		"sub":            "user-1",
+		"email":          "Ada@Example.com",
+		"email_verified": true,
+		"groups":         []string{"event-volunteers", "event-organizers"},
+	})
+
+	identity, err := signIn(t, provider, issuer, "expected-nonce")
+	if err != nil {
+		t.Fatalf("Exchange failed: %v", err)
+	}
+	if identity.Role != RoleOwner {
+		t.Errorf("Expected most privileged mapped role owner, got %q", identity.Role)
+	}
+	if identity.Subject != "user-1" {
+		t.Errorf("Expected subject user-1, got %q", identity.Subject)
+	}
+}
+
+func TestOIDC_Denied(t *testing.T) {
+	testCases := []struct {
+		name   string
+		claims map[string]interface{}
+	}{
+		{"Wrong domain", map[string]interface{}{"sub": "u", "email": "eve@evil.com", "groups": []string{"event-organizers"}}},
+		{"Unverified email", map[string]interface{}{"sub": "u", "email": "ada@example.com", "email_verified": false, "groups": []string{"event-organizers"}}},
+		{"No mapped group", map[string]interface{}{"sub": "u", "email": "ada@example.com", "groups": []string{"marketing"}}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			provider, issuer := newTestOIDCProvider(t)
+			issuer.SetUser(tc.claims)
+
+			_, err := signIn(t, provider, issuer, "expected-nonce")
+			if !errors.Is(err, ErrOIDCAccessDenied) {
+				t.Errorf("Expected ErrOIDCAccessDenied, got %v", err)
+			}
+		})
+	}
+}
+
+func TestOIDC_NonceMismatch(t *testing.T) {
+	provider, issuer := newTestOIDCProvider(t)
+	issuer.SetUser(map[string]interface{}{"sub": "u", "email": "ada@example.com", "groups": []string{"event-organizers"}})
+
+	if _, err := signIn(t, provider, issuer, "other-nonce"); err == nil {
+		t.Error("Expected nonce mismatch to fail")
+	}
+}
+
+func TestOIDC_WrongVerifier(t *testing.T) {
+	provider, issuer := newTestOIDCProvider(t)
+	issuer.SetUser(map[string]interface{}{"sub": "u", "email": "ada@example.com", "groups": []string{"event-organizers"}})
+
+	redirect, _ := issuer.Authorize(provider.AuthCodeURL("state-1", "n", NewPKCEVerifier()))
+	u, _ := url.Parse(redirect)
+	if _, err := provider.Exchange(context.Background(), u.Query().Get("code"), NewPKCEVerifier(), "n"); err == nil {
+		t.Error("Expected exchange with the wrong PKCE verifier to fail")
+	}
+}
@@ -0,0 +1,32 @@
+package auth
+
+import "testing"
+
+func TestHashAndCheckPassword(t *testing.T) {
+	hash, err := HashPassword("correct-horse")
+	if err != nil {
+		t.Fatalf("Failed to hash password: %v", err)
+	}
+
+	if hash == "correct-horse" {
+		t.Error("Expected hash to differ from the plaintext password")
+	}
+	if !CheckPassword(hash, "correct-horse") {
+		t.Error("Expected correct password to match")
+	}
+	if CheckPassword(hash, "wrong-horse") {
+		t.Error("Expected wrong password not to match")
+	}
+}
+
+func TestHashPassword_TooShort(t *testing.T) {
+	if _, err := HashPassword("short"); err == nil {
+		t.Error("Expected error for short password")
+	}
+}
+
+func TestCheckPassword_EmptyHash(t *testing.T) {
+	if CheckPassword("", "anything") {
+		t.Error("Expected empty hash never to match")
+	}
+}
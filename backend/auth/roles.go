@@ -0,0 +1,98 @@
+package auth
+
+import "fmt"
+
+// Role is a named bundle of permissions assigned to an admin account
+type Role string
+
+// Permission is a single capability checked on an admin route
+type Permission string
+
+const (
+	RoleViewer Role = "viewer"
+	// RoleDoorStaff can read attendees and check them in at the door
+	RoleDoorStaff Role = "door-staff"
+	RoleEditor    Role = "editor"
+	RoleOwner     Role = "owner"
+)
+
+const (
+	PermissionAttendeesRead  Permission = "attendees:read"
+	PermissionAttendeesWrite Permission = "attendees:write"
+	// PermissionCheckIn lets door staff check attendees in without any
+	// other write access
+	PermissionCheckIn       Permission = "attendees:checkin"
+	PermissionSpeakersWrite Permission = "speakers:write"
+	PermissionSessionsWrite Permission = "sessions:write"
+	PermissionFieldsWrite   Permission = "fields:write"
+	PermissionAdminsManage  Permission = "admins:manage"
+	PermissionAPIKeysManage Permission = "apikeys:manage"
+	PermissionAuditRead     Permission = "audit:read"
+)
+
+var rolePermissions = map[Role][]Permission{
+	RoleViewer: {
+		PermissionAttendeesRead,
+	},
+	RoleDoorStaff: {
+		PermissionAttendeesRead,
+		PermissionCheckIn,
+	},
+	RoleEditor: {
+		PermissionAttendeesRead,
+		PermissionAttendeesWrite,
+		PermissionCheckIn,
+		PermissionSpeakersWrite,
+		PermissionSessionsWrite,
+		PermissionFieldsWrite,
+	},
+	RoleOwner: {
+		PermissionAttendeesRead,
+		PermissionAttendeesWrite,
+		PermissionCheckIn,
+		PermissionSpeakersWrite,
+		PermissionSessionsWrite,
+		PermissionFieldsWrite,
+		PermissionAdminsManage,
+		PermissionAPIKeysManage,
+		PermissionAuditRead,
+	},
+}
+
+// ParsePermission validates a permission name
+func ParsePermission(name string) (Permission, error) {
+	permission := Permission(name)
+	for _, p := range rolePermissions[RoleOwner] {
+		if p == permission {
+			return permission, nil
+		}
+	}
+	return "", fmt.Errorf("unknown permission %q", name)
+}
+
+// ParseRole validates a role name. An empty name defaults to viewer.
+func ParseRole(name string) (Role, error) {
+	if name == "" {
+		return RoleViewer, nil
+	}
+	role := Role(name)
+	if _, ok := rolePermissions[role]; !ok {
+		return "", fmt.Errorf("unknown role %q; expected viewer, door-staff, editor or owner", name)
+	}
+	return role, nil
+}
+
+// Permissions returns the permissions granted to the role
+func (r Role) Permissions() []Permission {
+	return rolePermissions[r]
+}
+
+// Has reports whether the role grants permission
+func (r Role) Has(permission Permission) bool {
+	for _, p := range rolePermissions[r] {
+		if p == permission {
+			return true
+		}
+	}
+	return false
+}
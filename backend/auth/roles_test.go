@@ -0,0 +1,41 @@
+package auth
+
+import "testing"
+
+func TestRoleHas(t *testing.T) {
+	testCases := []struct {
+		role       Role
+		permission Permission
+		expected   bool
+	}{
+		{RoleViewer, PermissionAttendeesRead, true},
+		{RoleViewer, PermissionSessionsWrite, false},
+		{RoleViewer, PermissionCheckIn, false},
+		{RoleDoorStaff, PermissionCheckIn, true},
+		{RoleDoorStaff, PermissionAttendeesWrite, false},
+		{RoleEditor, PermissionSpeakersWrite, true},
+		{RoleEditor, PermissionFieldsWrite, true},
+		{RoleViewer, PermissionFieldsWrite, false},
+		{RoleEditor, PermissionAdminsManage, false},
+		{RoleOwner, PermissionAdminsManage, true},
+		{Role("unknown"), PermissionAttendeesRead, false},
+	}
+
+	for _, tc := range testCases {
+		if got := tc.role.Has(tc.permission); got != tc.expected {
+			t.Errorf("%s.Has(%s): expected %v, got %v", tc.role, tc.permission, tc.expected, got)
+		}
+	}
+}
+
+func TestParseRole(t *testing.T) {
+	if role, err := ParseRole(""); err != nil || role != RoleViewer {
+		t.Errorf("Expected empty role to default to viewer, got %q, %v", role, err)
+	}
+	if role, err := ParseRole("editor"); err != nil || role != RoleEditor {
+		t.Errorf("Expected editor, got %q, %v", role, err)
+	}
+	if _, err := ParseRole("superuser"); err == nil {
+		t.Error("Expected error for unknown role")
+	}
+}
@@ -0,0 +1,157 @@
+package auth
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+)
+
+// AttemptRecord tracks failed login attempts for one key (an account or a client IP)
+type AttemptRecord struct {
+	Failures    int       `json:"failures" firestore:"failures"`
+	LastFailure time.Time `json:"lastFailure" firestore:"lastFailure"`
+	LockedUntil time.Time `json:"lockedUntil" firestore:"lockedUntil"`
+}
+
+// AttemptStore persists failed-attempt counters. Get returns nil, nil for unknown keys.
+type AttemptStore interface {
+	Get(ctx context.Context, key string) (*AttemptRecord, error)
+	// Update replaces the record for key with fn's result atomically, so
+	// concurrent updates are not lost. fn receives nil for unknown keys and
+	// may run more than once.
+	Update(ctx context.Context, key string, fn func(record *AttemptRecord) *AttemptRecord) error
+	Reset(ctx context.Context, key string) error
+}
+
+// Throttle applies exponential-backoff lockouts once a key reaches MaxFailures
+// consecutive failed attempts. The first lockout lasts BaseLockout and each
+// further failure doubles it, up to MaxLockout. Counters are forgotten once a
+// key has had no failures for MaxLockout.
+type Throttle struct {
+	store       AttemptStore
+	MaxFailures int
+	BaseLockout time.Duration
+	MaxLockout  time.Duration
+	now         func() time.Time
+}
+
+// NewThrottle creates a Throttle backed by store
+func NewThrottle(store AttemptStore, maxFailures int, baseLockout, maxLockout time.Duration) *Throttle {
+	if maxLockout < baseLockout {
+		maxLockout = baseLockout
+	}
+	return &Throttle{
+		store:       store,
+		MaxFailures: maxFailures,
+		BaseLockout: baseLockout,
+		MaxLockout:  maxLockout,
+		now:         time.Now,
+	}
+}
+
+// Check returns how long the caller must wait before trying again, or zero
+// if none of keys is currently locked out
+func (t *Throttle) Check(ctx context.Context, keys ...string) (time.Duration, error) {
+	now := t.now()
+	var wait time.Duration
+	for _, key := range keys {
+		record, err := t.store.Get(ctx, key)
+		if err != nil {
+			return 0, err
+		}
+		if record != nil && record.LockedUntil.After(now) {
+			if d := record.LockedUntil.Sub(now); d > wait {
+				wait = d
+			}
+		}
+	}
+	return wait, nil
+}
+
+// RecordFailure counts a failed attempt against key and returns the lockout
+// it triggered, or zero if the key is still under the limit. The count is
+// incremented and compared with the limit atomically, so attempts made in
+// parallel each count towards the lockout.
+func (t *Throttle) RecordFailure(ctx context.Context, key string) (time.Duration, error) {
+	now := t.now()
+	var lockout time.Duration
+	var failures int
+	err := t.store.Update(ctx, key, func(record *AttemptRecord) *AttemptRecord {
+		if record == nil || now.Sub(record.LastFailure) > t.MaxLockout {
+			record = &AttemptRecord{}
+		}
+		record.Failures++
+		record.LastFailure = now
+
+		lockout, failures = 0, record.Failures
+		if record.Failures >= t.MaxFailures {
+			lockout = t.lockoutFor(record.Failures)
+			record.LockedUntil = now.Add(lockout)
+		}
+		return record
+	})
+	if err != nil {
+		return 0, err
+	}
+	if lockout > 0 {
+		log.Printf("Admin login lockout: %s locked for %s after %d failed attempts", key, lockout, failures)
+	}
+	return lockout, nil
+}
+
+// RecordSuccess clears the failure counter for key
+func (t *Throttle) RecordSuccess(ctx context.Context, key string) error {
+	return t.store.Reset(ctx, key)
+}
+
+// lockoutFor returns BaseLockout doubled for every failure past MaxFailures, capped at MaxLockout
+func (t *Throttle) lockoutFor(failures int) time.Duration {
+	lockout := t.BaseLockout
+	for i := t.MaxFailures; i < failures && lockout < t.MaxLockout; i++ {
+		lockout *= 2
+	}
+	if lockout > t.MaxLockout {
+		lockout = t.MaxLockout
+	}
+	return lockout
+}
+
+// MemoryAttemptStore is an in-process AttemptStore, suitable for tests and single-instance development
+type MemoryAttemptStore struct {
+	mu      sync.Mutex
+	records map[string]AttemptRecord
+}
+
+// NewMemoryAttemptStore creates an empty MemoryAttemptStore
+func NewMemoryAttemptStore() *MemoryAttemptStore {
+	return &MemoryAttemptStore{records: make(map[string]AttemptRecord)}
+}
+
+func (s *MemoryAttemptStore) Get(ctx context.Context, key string) (*AttemptRecord, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	record, ok := s.records[key]
+	if !ok {
+		return nil, nil
+	}
+	return &record, nil
+}
+
+func (s *MemoryAttemptStore) Update(ctx context.Context, key string, fn func(record *AttemptRecord) *AttemptRecord) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var current *AttemptRecord
+	if record, ok := s.records[key]; ok {
+		current = &record
+	}
+	s.records[key] = *fn(current)
+	return nil
+}
+
+func (s *MemoryAttemptStore) Reset(ctx context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.records, key)
+	return nil
+}
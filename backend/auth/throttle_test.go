@@ -0,0 +1,125 @@
+package auth
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+)
+
+// newTestThrottle returns a throttle with a controllable clock
+func newTestThrottle() (*Throttle, *time.Time) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	throttle := NewThrottle(NewMemoryAttemptStore(), 3, time.Minute, 10*time.Minute)
+	throttle.now = func() time.Time { return now }
+	return throttle, &now
+}
+
+func TestThrottle_LocksAfterMaxFailures(t *testing.T) {
+	ctx := context.Background()
+	throttle, _ := newTestThrottle()
+
+	for i := 1; i < 3; i++ {
+		lockout, err := throttle.RecordFailure(ctx, "user:alice")
+		if err != nil {
+			t.Fatalf("RecordFailure failed: %v", err)
+		}
+		if lockout != 0 {
+			t.Errorf("Failure %d: expected no lockout, got %s", i, lockout)
+		}
+	}
+
+	lockout, _ := throttle.RecordFailure(ctx, "user:alice")
+	if lockout != time.Minute {
+		t.Errorf("Expected 1m lockout on third failure, got %s", lockout)
+	}
+
+	wait, err := throttle.Check(ctx, "ip:1.2.3.4", "user:alice")
+	if err != nil {
+		t.Fatalf("Check failed: %v", err)
+	}
+	if wait != time.Minute {
+		t.Errorf("Expected to wait 1m, got %s", wait)
+	}
+}
+
+func TestThrottle_ExponentialBackoff(t *testing.T) {
+	ctx := context.Background()
+	throttle, _ := newTestThrottle()
+
+	expected := []time.Duration{0, 0, time.Minute, 2 * time.Minute, 4 * time.Minute, 8 * time.Minute, 10 * time.Minute, 10 * time.Minute}
+	for i, want := range expected {
+		got, _ := throttle.RecordFailure(ctx, "user:alice")
+		if got != want {
+			t.Errorf("Failure %d: expected lockout %s, got %s", i+1, want, got)
+		}
+	}
+}
+
+func TestThrottle_LockoutExpires(t *testing.T) {
+	ctx := context.Background()
+	throttle, now := newTestThrottle()
+
+	for i := 0; i < 3; i++ {
+		throttle.RecordFailure(ctx, "user:alice")
+	}
+
+	*now = now.Add(61 * time.Second)
+	wait, _ := throttle.Check(ctx, "user:alice")
+	if wait != 0 {
+		t.Errorf("Expected lockout to have expired, still waiting %s", wait)
+	}
+}
+
+func TestThrottle_SuccessResets(t *testing.T) {
+	ctx := context.Background()
+	throttle, _ := newTestThrottle()
+
+	throttle.RecordFailure(ctx, "user:alice")
+	throttle.RecordFailure(ctx, "user:alice")
+	throttle.RecordSuccess(ctx, "user:alice")
+
+	lockout, _ := throttle.RecordFailure(ctx, "user:alice")
+	if lockout != 0 {
+		t.Errorf("Expected counter reset after success, got lockout %s", lockout)
+	}
+}
+
+func TestThrottle_StaleFailuresForgotten(t *testing.T) {
+	ctx := context.Background()
+	throttle, now := newTestThrottle()
+
+	throttle.RecordFailure(ctx, "user:alice")
+	throttle.RecordFailure(ctx, "user:alice")
+
+	*now = now.Add(11 * time.Minute)
+	lockout, _ := throttle.RecordFailure(ctx, "user:alice")
+	if lockout != 0 {
+		t.Errorf("Expected stale failures to be forgotten, got lockout %s", lockout)
+	}
+}
+
+func TestThrottle_ConcurrentFailures(t *testing.T) {
+	ctx := context.Background()
+	throttle, _ := newTestThrottle()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := throttle.RecordFailure(ctx, "user:alice"); err != nil {
+				t.Errorf("RecordFailure failed: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	record, err := throttle.store.Get(ctx, "user:alice")
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	if record == nil || record.Failures != 20 {
+		t.Errorf("Expected every parallel failure to be counted, got %+v", record)
+	}
+}
@@ -0,0 +1,32 @@
+package auth
+
+import (
+	"crypto/hmac"
+	"strings"
+)
+
+// ticketPurpose separates the ticket key from other keys derived from the
+// same secret
+const ticketPurpose = "ticket"
+
+// IssueTicket returns the code printed as a QR ticket for attendeeID, of the
+// form <attendeeID>.<signature>. Tickets do not expire; cancelling the
+// registration is what invalidates them.
+func IssueTicket(secret []byte, attendeeID string) (string, error) {
+	if len(secret) == 0 {
+		return "", ErrInvalidToken
+	}
+	return attendeeID + "." + sign(derivedKey(secret, ticketPurpose), attendeeID), nil
+}
+
+// ParseTicket verifies a ticket code and returns its attendee ID
+func ParseTicket(secret []byte, code string) (string, error) {
+	attendeeID, signature, ok := strings.Cut(strings.TrimSpace(code), ".")
+	if len(secret) == 0 || !ok || attendeeID == "" {
+		return "", ErrInvalidToken
+	}
+	if !hmac.Equal([]byte(sign(derivedKey(secret, ticketPurpose), attendeeID)), []byte(signature)) {
+		return "", ErrInvalidToken
+	}
+	return attendeeID, nil
+}
@@ -0,0 +1,36 @@
+package auth
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTicket_RoundTrip(t *testing.T) {
+	secret := []byte("test-secret")
+	code, err := IssueTicket(secret, "attendee-1")
+	if err != nil {
+		t.Fatalf("Failed to issue ticket: %v", err)
+	}
+	if !strings.HasPrefix(code, "attendee-1.") {
+		t.Errorf("Expected the code to start with the attendee ID, got %q", code)
+	}
+
+	if id, err := ParseTicket(secret, " "+code+"\n"); err != nil || id != "attendee-1" {
+		t.Errorf("Expected attendee-1, got %q (err %v)", id, err)
+	}
+	if _, err := ParseTicket([]byte("other-secret"), code); err != ErrInvalidToken {
+		t.Errorf("Expected ErrInvalidToken with another secret, got %v", err)
+	}
+	if _, err := ParseTicket(secret, "attendee-2"+strings.TrimPrefix(code, "attendee-1")); err != ErrInvalidToken {
+		t.Errorf("Expected ErrInvalidToken for a forged attendee ID, got %v", err)
+	}
+}
+
+func TestTicket_NotAVerificationToken(t *testing.T) {
+	secret := []byte("test-secret")
+	token, _ := IssueVerificationToken(secret, "attendee-1", time.Now().Add(time.Hour))
+	if _, err := ParseTicket(secret, token); err != ErrInvalidToken {
+		t.Errorf("Expected a verification token to be rejected as a ticket, got %v", err)
+	}
+}
@@ -0,0 +1,84 @@
+package auth
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestIssueAndParseToken(t *testing.T) {
+	secret := []byte("test-secret")
+
+	token, claims, err := IssueToken(secret, "admin", RoleOwner, 0, time.Hour)
+	if err != nil {
+		t.Fatalf("Failed to issue token: %v", err)
+	}
+
+	parsed, err := ParseToken(secret, token)
+	if err != nil {
+		t.Fatalf("Failed to parse token: %v", err)
+	}
+
+	if parsed.Subject != "admin" {
+		t.Errorf("Expected subject 'admin', got '%s'", parsed.Subject)
+	}
+	if parsed.Role != RoleOwner {
+		t.Errorf("Expected role owner, got '%s'", parsed.Role)
+	}
+	if parsed.TokenID != claims.TokenID {
+		t.Errorf("Expected token ID %s, got %s", claims.TokenID, parsed.TokenID)
+	}
+}
+
+func TestParseToken_WrongSecret(t *testing.T) {
+	token, _, err := IssueToken([]byte("secret-a"), "admin", RoleOwner, 0, time.Hour)
+	if err != nil {
+		t.Fatalf("Failed to issue token: %v", err)
+	}
+
+	if _, err := ParseToken([]byte("secret-b"), token); err != ErrInvalidToken {
+		t.Errorf("Expected ErrInvalidToken, got %v", err)
+	}
+}
+
+func TestParseToken_TamperedPayload(t *testing.T) {
+	secret := []byte("test-secret")
+	token, _, err := IssueToken(secret, "admin", RoleOwner, 0, time.Hour)
+	if err != nil {
+		t.Fatalf("Failed to issue token: %v", err)
+	}
+
+	parts := strings.Split(token, ".")
+	forged := parts[0] + "x." + parts[1]
+	if _, err := ParseToken(secret, forged); err != ErrInvalidToken {
+		t.Errorf("Expected ErrInvalidToken, got %v", err)
+	}
+}
+
+func TestParseToken_Expired(t *testing.T) {
+	secret := []byte("test-secret")
+	token, _, err := IssueToken(secret, "admin", RoleOwner, 0, -time.Minute)
+	if err != nil {
+		t.Fatalf("Failed to issue token: %v", err)
+	}
+
+	if _, err := ParseToken(secret, token); err != ErrExpiredToken {
+		t.Errorf("Expected ErrExpiredToken, got %v", err)
+	}
+}
+
+func TestParseToken_Malformed(t *testing.T) {
+	testCases := []string{"", "abc", "a.b.c", "not-base64!.sig"}
+
+	for _, tc := range testCases {
+		if _, err := ParseToken([]byte("test-secret"), tc); err != ErrInvalidToken {
+			t.Errorf("Token %q: expected ErrInvalidToken, got %v", tc, err)
+		}
+	}
+}
+
+func TestIssueToken_NoSecret(t *testing.T) {
+	if _, _, err := IssueToken(nil, "admin", RoleOwner, 0, time.Hour); err == nil {
+		t.Error("Expected error when secret is empty")
+	}
+}
@@ -0,0 +1,60 @@
+package auth
+
+import (
+	"crypto/hmac"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// verificationClaims is the payload of an email verification token
+type verificationClaims struct {
+	AttendeeID string `json:"aid"`
+	ExpiresAt  int64  `json:"exp"`
+}
+
+// verificationPurpose separates the verification token key from other keys
+// derived from the same secret
+const verificationPurpose = "email-verification"
+
+// IssueVerificationToken creates a signed token confirming that whoever holds
+// it received email for attendeeID. It has the same form as session tokens.
+func IssueVerificationToken(secret []byte, attendeeID string, expiresAt time.Time) (string, error) {
+	if len(secret) == 0 {
+		return "", fmt.Errorf("token secret is not configured")
+	}
+	payload, err := json.Marshal(verificationClaims{AttendeeID: attendeeID, ExpiresAt: expiresAt.Unix()})
+	if err != nil {
+		return "", fmt.Errorf("failed to encode verification token: %w", err)
+	}
+	encodedPayload := base64.RawURLEncoding.EncodeToString(payload)
+	return encodedPayload + "." + sign(derivedKey(secret, verificationPurpose), encodedPayload), nil
+}
+
+// ParseVerificationToken verifies token and returns its attendee ID. Expired
+// tokens return the ID together with ErrExpiredToken.
+func ParseVerificationToken(secret []byte, token string) (string, error) {
+	if len(secret) == 0 {
+		return "", ErrInvalidToken
+	}
+
+	encodedPayload, signature, ok := strings.Cut(token, ".")
+	if !ok || !hmac.Equal([]byte(sign(derivedKey(secret, verificationPurpose), encodedPayload)), []byte(signature)) {
+		return "", ErrInvalidToken
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(encodedPayload)
+	if err != nil {
+		return "", ErrInvalidToken
+	}
+	var claims verificationClaims
+	if err := json.Unmarshal(payload, &claims); err != nil || claims.AttendeeID == "" {
+		return "", ErrInvalidToken
+	}
+	if time.Now().Unix() >= claims.ExpiresAt {
+		return claims.AttendeeID, ErrExpiredToken
+	}
+	return claims.AttendeeID, nil
+}
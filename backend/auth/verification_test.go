@@ -0,0 +1,47 @@
+package auth
+
+import (
+	"testing"
+	"time"
+)
+
+func TestVerificationToken_RoundTrip(t *testing.T) {
+	secret := []byte("test-secret")
+	token, err := IssueVerificationToken(secret, "attendee-1", time.Now().Add(time.Hour))
+	if err != nil {
+		t.Fatalf("Failed to issue token: %v", err)
+	}
+
+	id, err := ParseVerificationToken(secret, token)
+	if err != nil || id != "attendee-1" {
+		t.Errorf("Expected attendee-1, got %q (err %v)", id, err)
+	}
+	if _, err := ParseVerificationToken([]byte("other-secret"), token); err != ErrInvalidToken {
+		t.Errorf("Expected ErrInvalidToken with another secret, got %v", err)
+	}
+	if _, err := ParseVerificationToken(secret, token+"x"); err != ErrInvalidToken {
+		t.Errorf("Expected ErrInvalidToken for a tampered token, got %v", err)
+	}
+}
+
+func TestVerificationToken_Expired(t *testing.T) {
+	secret := []byte("test-secret")
+	token, _ := IssueVerificationToken(secret, "attendee-1", time.Now().Add(-time.Minute))
+
+	if id, err := ParseVerificationToken(secret, token); err != ErrExpiredToken || id != "attendee-1" {
+		t.Errorf("Expected ErrExpiredToken for attendee-1, got %q (err %v)", id, err)
+	}
+}
+
+func TestVerificationToken_NotASessionToken(t *testing.T) {
+	secret := []byte("test-secret")
+	token, _ := IssueVerificationToken(secret, "admin", time.Now().Add(time.Hour))
+	if _, err := ParseToken(secret, token); err != ErrInvalidToken {
+		t.Errorf("Expected a verification token to be rejected as a session token, got %v", err)
+	}
+
+	session, _, _ := IssueToken(secret, "admin", RoleOwner, 0, time.Hour)
+	if _, err := ParseVerificationToken(secret, session); err != ErrInvalidToken {
+		t.Errorf("Expected a session token to be rejected as a verification token, got %v", err)
+	}
+}
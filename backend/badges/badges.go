@@ -0,0 +1,116 @@
+// Package badges lays out printable attendee name badges as a PDF, several
+// to an A4 page, with cut lines around each badge.
+package badges
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+
+	"github.com/go-pdf/fpdf"
+)
+
+// Page layout in millimetres: a grid of Columns x Rows badges on A4 portrait
+const (
+	Columns = 2
+	Rows    = 4
+
+	pageWidth  = 210.0
+	pageHeight = 297.0
+	margin     = 10.0
+	padding    = 5.0
+	qrSize     = 32.0
+
+	badgeWidth  = (pageWidth - 2*margin) / Columns
+	badgeHeight = (pageHeight - 2*margin) / Rows
+)
+
+// PerPage is the number of badges on each page
+const PerPage = Columns * Rows
+
+// Badge is the content of one name badge
+type Badge struct {
+	Name        string
+	Designation string
+	// QR is the PNG image of the attendee's ticket; badges without one are
+	// printed without a code
+	QR []byte
+}
+
+// Render writes a PDF with one badge per entry, headed with the event name
+func Render(w io.Writer, event string, badges []Badge) error {
+	pdf := fpdf.New("P", "mm", "A4", "")
+	pdf.SetTitle(event+" badges", true)
+	pdf.SetAutoPageBreak(false, 0)
+	pdf.SetMargins(0, 0, 0)
+	// The core fonts only cover Windows-1252; other characters print as '?'
+	tr := pdf.UnicodeTranslatorFromDescriptor("")
+
+	if len(badges) == 0 {
+		pdf.AddPage()
+	}
+	for i, badge := range badges {
+		if i%PerPage == 0 {
+			pdf.AddPage()
+		}
+		slot := i % PerPage
+		x := margin + float64(slot%Columns)*badgeWidth
+		y := margin + float64(slot/Columns)*badgeHeight
+		drawBadge(pdf, tr, x, y, event, badge, fmt.Sprintf("qr%d", i))
+	}
+
+	if err := pdf.Error(); err != nil {
+		return err
+	}
+	return pdf.Output(w)
+}
+
+// drawBadge draws one badge with its top-left corner at x, y
+func drawBadge(pdf *fpdf.Fpdf, tr func(string) string, x, y float64, event string, badge Badge, imageName string) {
+	pdf.SetDrawColor(180, 180, 180)
+	pdf.SetLineWidth(0.2)
+	pdf.SetDashPattern([]float64{2, 2}, 0)
+	pdf.Rect(x, y, badgeWidth, badgeHeight, "D")
+	pdf.SetDashPattern(nil, 0)
+
+	textWidth := badgeWidth - 2*padding
+	if badge.QR != nil {
+		textWidth -= qrSize + padding
+	}
+
+	pdf.SetTextColor(110, 110, 110)
+	pdf.SetFont("Helvetica", "", 9)
+	pdf.SetXY(x+padding, y+padding)
+	pdf.CellFormat(badgeWidth-2*padding, 5, tr(event), "", 0, "L", false, 0, "")
+
+	pdf.SetTextColor(20, 20, 20)
+	pdf.SetFont("Helvetica", "B", 22)
+	pdf.SetFontSize(fitFontSize(pdf, tr(badge.Name), textWidth, 22, 12))
+	pdf.SetXY(x+padding, y+badgeHeight/2-9)
+	pdf.MultiCell(textWidth, 9, tr(badge.Name), "", "L", false)
+
+	pdf.SetTextColor(60, 60, 60)
+	pdf.SetFont("Helvetica", "", 12)
+	pdf.SetX(x + padding)
+	pdf.MultiCell(textWidth, 6, tr(badge.Designation), "", "L", false)
+
+	if badge.QR != nil {
+		options := fpdf.ImageOptions{ImageType: "PNG"}
+		pdf.RegisterImageOptionsReader(imageName, options, bytes.NewReader(badge.QR))
+		qrX := x + badgeWidth - padding - qrSize
+		qrY := y + (badgeHeight-qrSize)/2
+		pdf.ImageOptions(imageName, qrX, qrY, qrSize, qrSize, false, options, 0, "")
+	}
+}
+
+// fitFontSize returns the largest size from max down to min at which text
+// fits on one line of width, or min if it never does
+func fitFontSize(pdf *fpdf.Fpdf, text string, width, max, min float64) float64 {
+	for size := max; size > min; size-- {
+		pdf.SetFontSize(size)
+		if pdf.GetStringWidth(text) <= width {
+			return size
+		}
+	}
+	return min
+}
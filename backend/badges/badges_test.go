@@ -0,0 +1,46 @@
+package badges
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/skip2/go-qrcode"
+)
+
+func TestRender_PagesAndImages(t *testing.T) {
+	var entries []Badge
+	for i := 0; i < PerPage+1; i++ {
+		qr, err := qrcode.Encode(fmt.Sprintf("ticket-%d", i), qrcode.Medium, 128)
+		if err != nil {
+			t.Fatalf("Failed to encode QR: %v", err)
+		}
+		entries = append(entries, Badge{Name: fmt.Sprintf("Attendee %d", i), Designation: "Engineer", QR: qr})
+	}
+
+	var buf bytes.Buffer
+	if err := Render(&buf, "Go Meetup", entries); err != nil {
+		t.Fatalf("Render failed: %v", err)
+	}
+	pdf := buf.String()
+	if !strings.HasPrefix(pdf, "%PDF-") {
+		t.Fatalf("Expected a PDF, got %q", pdf[:20])
+	}
+	if pages := strings.Count(pdf, "/Type /Page\n"); pages != 2 {
+		t.Errorf("Expected 2 pages for %d badges, got %d", len(entries), pages)
+	}
+	if images := strings.Count(pdf, "/Subtype /Image"); images != len(entries) {
+		t.Errorf("Expected %d QR images, got %d", len(entries), images)
+	}
+}
+
+func TestRender_Empty(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Render(&buf, "Go Meetup", nil); err != nil {
+		t.Fatalf("Render failed: %v", err)
+	}
+	if !strings.HasPrefix(buf.String(), "%PDF-") {
+		t.Error("Expected a PDF with a blank page")
+	}
+}
@@ -1,19 +1,113 @@
 package config
 
 import (
+	"fmt"
 	"log"
 	"os"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
 )
 
+// Storage backends selectable with STORAGE_BACKEND
+const (
+	StorageFirestore = "firestore"
+	StorageLocal     = "local"
+	StorageSQLite    = "sqlite"
+	StoragePostgres  = "postgres"
+)
+
+// DefaultLocalDataPath is the snapshot file of the embedded local store when LOCAL_DATA_PATH is unset
+const DefaultLocalDataPath = "./local-data.json"
+
+// DefaultLocalClientID is the tenant used by the local store when CLIENT_ID is unset
+const DefaultLocalClientID = "local"
+
+// DefaultSQLitePath is the database file used when STORAGE_BACKEND=sqlite and DATABASE_URL is unset
+const DefaultSQLitePath = "./event-registration.db"
+
+// Default event details shown in attendee emails, matching the website
+const (
+	DefaultEventName    = "AppDirect India Hands-On Tech Meetup"
+	DefaultEventVenue   = "AppDirect India"
+	DefaultEventAddress = "Pune, Maharashtra, India"
+)
+
+// DefaultAdminPassword is the well-known development password. It is only
+// used outside production and the server refuses to start with it in production.
+const DefaultAdminPassword = "admin123"
+
 type Config struct {
+	Environment        string
+	AdminUsername      string
 	AdminPassword      string
 	Port               string
 	ServiceAccountPath string
 	FrontendDir        string
 	GCPProjectID       string
 	ClientID           string
+	SessionSecret      string
+	SessionTTL         time.Duration
+	LoginMaxFailures   int
+	LoginLockoutBase   time.Duration
+	LoginLockoutMax    time.Duration
+	TrustProxyHeaders  bool
+
+	// EventCapacity is the number of confirmed seats; further registrations
+	// are waitlisted. 0 means unlimited.
+	EventCapacity int
+
+	// Attendee email handling: EmailFoldLocalPart lowercases the part before
+	// the @ as well as the domain, BlockDisposableEmails refuses well-known
+	// throwaway providers and BlockedEmailDomains lists further domains to refuse
+	EmailFoldLocalPart    bool
+	BlockDisposableEmails bool
+	BlockedEmailDomains   []string
+
+	// RequireEmailVerification holds new registrations as pending until the
+	// attendee follows the emailed link; unverified ones expire after
+	// VerificationTTL. PublicURL is where the link points.
+	RequireEmailVerification bool
+	VerificationTTL          time.Duration
+	PublicURL                string
+
+	// ResponseCacheTTL is how long the public sessions, speakers and attendee
+	// count responses are served from memory; 0 disables caching
+	ResponseCacheTTL time.Duration
+
+	// Event details for attendee emails
+	EventName    string
+	EventDate    string
+	EventVenue   string
+	EventAddress string
+
+	// Outgoing mail; emails are disabled unless SMTPHost is set
+	SMTPHost     string
+	SMTPPort     int
+	SMTPUsername string
+	SMTPPassword string
+	MailFrom     string
+
+	// StorageBackend selects where attendees, sessions and speakers are kept;
+	// DatabaseURL is the SQLite path or PostgreSQL connection string.
+	// LocalDataPath is the snapshot file of the embedded local store, which
+	// also holds admin data when a SQL backend is used.
+	StorageBackend string
+	DatabaseURL    string
+	LocalDataPath  string
+
+	// OIDC single sign-on for admins; disabled unless OIDCIssuerURL is set
+	OIDCIssuerURL         string
+	OIDCClientID          string
+	OIDCClientSecret      string
+	OIDCRedirectURL       string
+	OIDCAllowedDomains    []string
+	OIDCGroupsClaim       string
+	OIDCRoleMapping       map[string]string
+	OIDCDefaultRole       string
+	OIDCPostLoginRedirect string
 }
 
 func LoadConfig() *Config {
@@ -22,9 +116,21 @@ func LoadConfig() *Config {
 		log.Println("No .env file found, using environment variables")
 	}
 
+	environment := os.Getenv("APP_ENV")
+	if environment == "" {
+		environment = "development"
+	}
+
+	adminUsername := os.Getenv("ADMIN_USERNAME")
+	if adminUsername == "" {
+		adminUsername = "admin"
+	}
+
+	// ADMIN_PASSWORD is only used to bootstrap the first admin account
 	adminPassword := os.Getenv("ADMIN_PASSWORD")
-	if adminPassword == "" {
-		adminPassword = "admin123" // Default for development
+	if adminPassword == "" && environment != "production" {
+		log.Printf("ADMIN_PASSWORD not set, using default development password")
+		adminPassword = DefaultAdminPassword
 	}
 
 	port := os.Getenv("PORT")
@@ -49,13 +155,240 @@ func LoadConfig() *Config {
 	clientID := os.Getenv("CLIENT_ID")
 	// CLIENT_ID is required when using ADC (Cloud Run), optional when using service account file
 
+	sessionSecret := os.Getenv("SESSION_SECRET")
+	// Required in production and whenever attendee emails are sent; elsewhere
+	// empty means main.go generates a random secret, and sessions, tickets
+	// and verification links do not survive restarts
+
+	sessionTTL := durationEnv("SESSION_TTL", 12*time.Hour)
+
+	// Failed admin logins per account or IP before a lockout, and the lockout bounds
+	loginMaxFailures := intEnv("LOGIN_MAX_FAILURES", 5)
+	loginLockoutBase := durationEnv("LOGIN_LOCKOUT_BASE", time.Minute)
+	loginLockoutMax := durationEnv("LOGIN_LOCKOUT_MAX", time.Hour)
+
+	// Set when running behind a proxy (e.g. Cloud Run) that appends the client IP to X-Forwarded-For
+	trustProxyHeaders := os.Getenv("TRUST_PROXY_HEADERS") == "true"
+
+	// Seats at the venue; unset means unlimited
+	eventCapacity := intEnv("EVENT_CAPACITY", 0)
+
+	// Disposable email providers are refused unless BLOCK_DISPOSABLE_EMAILS=false
+	emailFoldLocalPart := os.Getenv("EMAIL_FOLD_LOCAL_PART") == "true"
+	blockDisposableEmails := os.Getenv("BLOCK_DISPOSABLE_EMAILS") != "false"
+
+	// Double opt-in is off unless REQUIRE_EMAIL_VERIFICATION=true
+	requireEmailVerification := os.Getenv("REQUIRE_EMAIL_VERIFICATION") == "true"
+	verificationTTL := durationEnv("VERIFICATION_TTL", 48*time.Hour)
+	publicURL := strings.TrimSuffix(os.Getenv("PUBLIC_URL"), "/")
+	if publicURL == "" {
+		publicURL = "http://localhost:" + port
+	}
+
+	// Public read responses are cached for 30s unless RESPONSE_CACHE_TTL is
+	// zero, written as 0 or as a duration such as 0s
+	var responseCacheTTL time.Duration
+	if d, err := time.ParseDuration(os.Getenv("RESPONSE_CACHE_TTL")); err != nil || d != 0 {
+		responseCacheTTL = durationEnv("RESPONSE_CACHE_TTL", 30*time.Second)
+	}
+
+	eventName := os.Getenv("EVENT_NAME")
+	if eventName == "" {
+		eventName = DefaultEventName
+	}
+	eventVenue := os.Getenv("EVENT_VENUE")
+	if eventVenue == "" {
+		eventVenue = DefaultEventVenue
+	}
+	eventAddress := os.Getenv("EVENT_ADDRESS")
+	if eventAddress == "" {
+		eventAddress = DefaultEventAddress
+	}
+
+	// SMTP_PORT defaults to 1025, MailHog's SMTP port
+	smtpPort := intEnv("SMTP_PORT", 1025)
+	mailFrom := os.Getenv("MAIL_FROM")
+	if mailFrom == "" {
+		mailFrom = "no-reply@localhost"
+	}
+
+	storageBackend := strings.ToLower(os.Getenv("STORAGE_BACKEND"))
+	if storageBackend == "" {
+		storageBackend = StorageFirestore
+		// Without any Google Cloud credentials, development runs use the local store
+		if environment != "production" && gcpProjectID == "" && !fileExists(serviceAccountPath) && os.Getenv("FIRESTORE_EMULATOR_HOST") == "" {
+			log.Printf("No Google Cloud credentials found, using the local store")
+			storageBackend = StorageLocal
+		}
+	}
+	databaseURL := os.Getenv("DATABASE_URL")
+	if databaseURL == "" && storageBackend == StorageSQLite {
+		databaseURL = DefaultSQLitePath
+	}
+	localDataPath := os.Getenv("LOCAL_DATA_PATH")
+	if localDataPath == "" {
+		localDataPath = DefaultLocalDataPath
+	}
+	if clientID == "" && storageBackend == StorageLocal {
+		clientID = DefaultLocalClientID
+	}
+
+	// OIDC_ROLE_MAPPING is a comma-separated list of group:role pairs, e.g. "event-admins:owner,volunteers:viewer"
+	oidcRoleMapping := make(map[string]string)
+	for _, pair := range splitList(os.Getenv("OIDC_ROLE_MAPPING")) {
+		group, role, ok := strings.Cut(pair, ":")
+		if !ok {
+			log.Printf("Ignoring malformed OIDC_ROLE_MAPPING entry %q", pair)
+			continue
+		}
+		oidcRoleMapping[strings.TrimSpace(group)] = strings.TrimSpace(role)
+	}
+
 	return &Config{
+		Environment:        environment,
+		AdminUsername:      adminUsername,
 		AdminPassword:      adminPassword,
 		Port:               port,
 		ServiceAccountPath: serviceAccountPath,
 		FrontendDir:        frontendDir,
 		GCPProjectID:       gcpProjectID,
 		ClientID:           clientID,
+		SessionSecret:      sessionSecret,
+		SessionTTL:         sessionTTL,
+		LoginMaxFailures:   loginMaxFailures,
+		LoginLockoutBase:   loginLockoutBase,
+		LoginLockoutMax:    loginLockoutMax,
+		TrustProxyHeaders:  trustProxyHeaders,
+
+		EventCapacity: eventCapacity,
+
+		EmailFoldLocalPart:    emailFoldLocalPart,
+		BlockDisposableEmails: blockDisposableEmails,
+		BlockedEmailDomains:   splitList(os.Getenv("BLOCKED_EMAIL_DOMAINS")),
+
+		RequireEmailVerification: requireEmailVerification,
+		VerificationTTL:          verificationTTL,
+		PublicURL:                publicURL,
+
+		ResponseCacheTTL: responseCacheTTL,
+
+		EventName:    eventName,
+		EventDate:    os.Getenv("EVENT_DATE"),
+		EventVenue:   eventVenue,
+		EventAddress: eventAddress,
+
+		SMTPHost:     os.Getenv("SMTP_HOST"),
+		SMTPPort:     smtpPort,
+		SMTPUsername: os.Getenv("SMTP_USERNAME"),
+		SMTPPassword: os.Getenv("SMTP_PASSWORD"),
+		MailFrom:     mailFrom,
+
+		StorageBackend: storageBackend,
+		DatabaseURL:    databaseURL,
+		LocalDataPath:  localDataPath,
+
+		OIDCIssuerURL:         os.Getenv("OIDC_ISSUER_URL"),
+		OIDCClientID:          os.Getenv("OIDC_CLIENT_ID"),
+		OIDCClientSecret:      os.Getenv("OIDC_CLIENT_SECRET"),
+		OIDCRedirectURL:       os.Getenv("OIDC_REDIRECT_URL"),
+		OIDCAllowedDomains:    splitList(os.Getenv("OIDC_ALLOWED_DOMAINS")),
+		OIDCGroupsClaim:       os.Getenv("OIDC_GROUPS_CLAIM"),
+		OIDCRoleMapping:       oidcRoleMapping,
+		OIDCDefaultRole:       os.Getenv("OIDC_DEFAULT_ROLE"),
+		OIDCPostLoginRedirect: os.Getenv("OIDC_POST_LOGIN_REDIRECT"),
+	}
+}
+
+// durationEnv parses a positive duration from the named variable, falling back to def
+func durationEnv(name string, def time.Duration) time.Duration {
+	v := os.Getenv(name)
+	if v == "" {
+		return def
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil || d <= 0 {
+		log.Printf("Invalid %s %q, using default %s", name, v, def)
+		return def
+	}
+	return d
+}
+
+// intEnv parses a positive integer from the named variable, falling back to def
+func intEnv(name string, def int) int {
+	v := os.Getenv(name)
+	if v == "" {
+		return def
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil || n <= 0 {
+		log.Printf("Invalid %s %q, using default %d", name, v, def)
+		return def
+	}
+	return n
+}
+
+// fileExists reports whether path names an existing file
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+// splitList splits a comma-separated variable into trimmed, non-empty entries
+func splitList(v string) []string {
+	var items []string
+	for _, item := range strings.Split(v, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
 	}
+	return items
 }
 
+// MailEnabled reports whether attendee emails are sent
+func (c *Config) MailEnabled() bool {
+	return c.SMTPHost != ""
+}
+
+// OIDCEnabled reports whether admin single sign-on is configured
+func (c *Config) OIDCEnabled() bool {
+	return c.OIDCIssuerURL != ""
+}
+
+// IsProduction reports whether APP_ENV is set to production
+func (c *Config) IsProduction() bool {
+	return c.Environment == "production"
+}
+
+// Validate rejects configurations that are unsafe to run with
+func (c *Config) Validate() error {
+	if c.IsProduction() && c.AdminPassword == DefaultAdminPassword {
+		return fmt.Errorf("refusing to start in production with the default admin password; set ADMIN_PASSWORD")
+	}
+	if c.IsProduction() && c.SessionSecret == "" {
+		return fmt.Errorf("SESSION_SECRET is required in production so that admin sessions, tickets and verification links survive restarts and work across instances")
+	}
+	if (c.MailEnabled() || c.RequireEmailVerification) && c.SessionSecret == "" {
+		return fmt.Errorf("SESSION_SECRET is required when attendee emails are sent, since the tickets and verification links they carry are signed with it")
+	}
+	switch c.StorageBackend {
+	case "", StorageFirestore, StorageLocal:
+	case StorageSQLite, StoragePostgres:
+		if c.DatabaseURL == "" {
+			return fmt.Errorf("DATABASE_URL is required when STORAGE_BACKEND is %s", c.StorageBackend)
+		}
+		if c.ClientID == "" {
+			return fmt.Errorf("CLIENT_ID is required when STORAGE_BACKEND is %s", c.StorageBackend)
+		}
+	default:
+		return fmt.Errorf("unknown STORAGE_BACKEND %q; use %s, %s, %s or %s", c.StorageBackend, StorageFirestore, StorageLocal, StorageSQLite, StoragePostgres)
+	}
+	if c.OIDCEnabled() {
+		if c.OIDCClientID == "" || c.OIDCRedirectURL == "" {
+			return fmt.Errorf("OIDC_CLIENT_ID and OIDC_REDIRECT_URL are required when OIDC_ISSUER_URL is set")
+		}
+		if len(c.OIDCAllowedDomains) == 0 {
+			return fmt.Errorf("OIDC_ALLOWED_DOMAINS is required when OIDC_ISSUER_URL is set")
+		}
+	}
+	return nil
+}
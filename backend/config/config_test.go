@@ -3,6 +3,7 @@ package config
 import (
 	"os"
 	"testing"
+	"time"
 )
 
 func TestLoadConfig_Defaults(t *testing.T) {
@@ -65,3 +66,201 @@ func TestLoadConfig_PartialEnv(t *testing.T) {
 	}
 }
 
+
+func TestLoadConfig_ProductionNoDefaultPassword(t *testing.T) {
+	os.Setenv("APP_ENV", "production")
+	os.Setenv("SESSION_SECRET", "production-secret")
+	os.Unsetenv("ADMIN_PASSWORD")
+	defer os.Unsetenv("APP_ENV")
+	defer os.Unsetenv("SESSION_SECRET")
+
+	cfg := LoadConfig()
+
+	if cfg.AdminPassword != "" {
+		t.Errorf("Expected no admin password fallback in production, got '%s'", cfg.AdminPassword)
+	}
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("Expected valid config, got %v", err)
+	}
+}
+
+func TestValidate_ProductionDefaultPassword(t *testing.T) {
+	cfg := &Config{Environment: "production", AdminPassword: DefaultAdminPassword, SessionSecret: "production-secret"}
+	if err := cfg.Validate(); err == nil {
+		t.Error("Expected error for default password in production")
+	}
+
+	cfg.Environment = "development"
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("Expected default password to be allowed in development, got %v", err)
+	}
+}
+
+func TestValidate_ProductionSessionSecret(t *testing.T) {
+	cfg := &Config{Environment: "production", AdminPassword: "s3cret"}
+	if err := cfg.Validate(); err == nil {
+		t.Error("Expected error for a missing session secret in production")
+	}
+
+	cfg.SessionSecret = "production-secret"
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("Expected valid config, got %v", err)
+	}
+}
+
+func TestValidate_MailSessionSecret(t *testing.T) {
+	for _, cfg := range []*Config{{SMTPHost: "mailhog"}, {RequireEmailVerification: true}} {
+		if err := cfg.Validate(); err == nil {
+			t.Errorf("Expected error for a missing session secret with %+v", cfg)
+		}
+		cfg.SessionSecret = "dev-secret"
+		if err := cfg.Validate(); err != nil {
+			t.Errorf("Expected valid config, got %v", err)
+		}
+	}
+	if err := (&Config{}).Validate(); err != nil {
+		t.Errorf("Expected a random session secret to be allowed without email, got %v", err)
+	}
+}
+
+func TestLoadConfig_OIDC(t *testing.T) {
+	os.Setenv("OIDC_ISSUER_URL", "https://idp.example.com")
+	os.Setenv("OIDC_ALLOWED_DOMAINS", "example.com, example.org")
+	os.Setenv("OIDC_ROLE_MAPPING", "event-admins:owner, volunteers:viewer,broken")
+	defer func() {
+		os.Unsetenv("OIDC_ISSUER_URL")
+		os.Unsetenv("OIDC_ALLOWED_DOMAINS")
+		os.Unsetenv("OIDC_ROLE_MAPPING")
+	}()
+
+	cfg := LoadConfig()
+
+	if !cfg.OIDCEnabled() {
+		t.Error("Expected OIDC to be enabled")
+	}
+	if len(cfg.OIDCAllowedDomains) != 2 || cfg.OIDCAllowedDomains[1] != "example.org" {
+		t.Errorf("Unexpected allowed domains: %v", cfg.OIDCAllowedDomains)
+	}
+	if cfg.OIDCRoleMapping["event-admins"] != "owner" || cfg.OIDCRoleMapping["volunteers"] != "viewer" || len(cfg.OIDCRoleMapping) != 2 {
+		t.Errorf("Unexpected role mapping: %v", cfg.OIDCRoleMapping)
+	}
+	if err := cfg.Validate(); err == nil {
+		t.Error("Expected validation error without OIDC_CLIENT_ID and OIDC_REDIRECT_URL")
+	}
+}
+
+func TestLoadConfig_EmailPolicy(t *testing.T) {
+	cfg := LoadConfig()
+	if !cfg.BlockDisposableEmails || cfg.EmailFoldLocalPart {
+		t.Errorf("Expected disposable emails blocked and local part kept by default, got %+v", cfg)
+	}
+
+	os.Setenv("BLOCK_DISPOSABLE_EMAILS", "false")
+	os.Setenv("EMAIL_FOLD_LOCAL_PART", "true")
+	os.Setenv("BLOCKED_EMAIL_DOMAINS", "competitor.com, spam.example")
+	defer func() {
+		os.Unsetenv("BLOCK_DISPOSABLE_EMAILS")
+		os.Unsetenv("EMAIL_FOLD_LOCAL_PART")
+		os.Unsetenv("BLOCKED_EMAIL_DOMAINS")
+	}()
+
+	cfg = LoadConfig()
+
+	if cfg.BlockDisposableEmails || !cfg.EmailFoldLocalPart {
+		t.Errorf("Expected env to override email policy, got %+v", cfg)
+	}
+	if len(cfg.BlockedEmailDomains) != 2 || cfg.BlockedEmailDomains[1] != "spam.example" {
+		t.Errorf("Unexpected blocked domains: %v", cfg.BlockedEmailDomains)
+	}
+}
+
+func TestLoadConfig_StorageBackend(t *testing.T) {
+	os.Setenv("STORAGE_BACKEND", "SQLite")
+	defer os.Unsetenv("STORAGE_BACKEND")
+
+	cfg := LoadConfig()
+
+	if cfg.StorageBackend != StorageSQLite {
+		t.Errorf("Expected storage backend %q, got %q", StorageSQLite, cfg.StorageBackend)
+	}
+	if cfg.DatabaseURL != DefaultSQLitePath {
+		t.Errorf("Expected default SQLite path %q, got %q", DefaultSQLitePath, cfg.DatabaseURL)
+	}
+}
+
+func TestValidate_StorageBackend(t *testing.T) {
+	if err := (&Config{StorageBackend: "mongodb"}).Validate(); err == nil {
+		t.Error("Expected error for unknown storage backend")
+	}
+	if err := (&Config{StorageBackend: StoragePostgres, ClientID: "acme"}).Validate(); err == nil {
+		t.Error("Expected error for postgres without DATABASE_URL")
+	}
+	if err := (&Config{StorageBackend: StorageSQLite, DatabaseURL: DefaultSQLitePath}).Validate(); err == nil {
+		t.Error("Expected error for SQL backend without CLIENT_ID")
+	}
+	if err := (&Config{StorageBackend: StorageSQLite, DatabaseURL: DefaultSQLitePath, ClientID: "acme"}).Validate(); err != nil {
+		t.Errorf("Expected valid SQLite config, got %v", err)
+	}
+}
+
+func TestLoadConfig_LocalStoreWithoutCredentials(t *testing.T) {
+	os.Unsetenv("STORAGE_BACKEND")
+	os.Unsetenv("CLIENT_ID")
+	os.Setenv("SERVICE_ACCOUNT_PATH", "/nonexistent/service-account.json")
+	defer os.Unsetenv("SERVICE_ACCOUNT_PATH")
+	t.Setenv("FIRESTORE_EMULATOR_HOST", "")
+
+	cfg := LoadConfig()
+
+	if cfg.StorageBackend != StorageLocal {
+		t.Errorf("Expected storage backend %q without credentials, got %q", StorageLocal, cfg.StorageBackend)
+	}
+	if cfg.ClientID != DefaultLocalClientID || cfg.LocalDataPath != DefaultLocalDataPath {
+		t.Errorf("Expected local defaults, got client %q and path %q", cfg.ClientID, cfg.LocalDataPath)
+	}
+}
+
+func TestLoadConfig_EmulatorKeepsFirestore(t *testing.T) {
+	os.Unsetenv("STORAGE_BACKEND")
+	t.Setenv("FIRESTORE_EMULATOR_HOST", "localhost:8086")
+
+	if cfg := LoadConfig(); cfg.StorageBackend != StorageFirestore {
+		t.Errorf("Expected storage backend %q with the emulator, got %q", StorageFirestore, cfg.StorageBackend)
+	}
+}
+
+func TestLoadConfig_EmailVerification(t *testing.T) {
+	os.Unsetenv("PORT")
+	cfg := LoadConfig()
+	if cfg.RequireEmailVerification || cfg.VerificationTTL != 48*time.Hour || cfg.PublicURL != "http://localhost:8080" {
+		t.Errorf("Unexpected verification defaults: %v %v %q", cfg.RequireEmailVerification, cfg.VerificationTTL, cfg.PublicURL)
+	}
+
+	t.Setenv("REQUIRE_EMAIL_VERIFICATION", "true")
+	t.Setenv("VERIFICATION_TTL", "30m")
+	t.Setenv("PUBLIC_URL", "https://meetup.example.com/")
+
+	cfg = LoadConfig()
+
+	if !cfg.RequireEmailVerification || cfg.VerificationTTL != 30*time.Minute || cfg.PublicURL != "https://meetup.example.com" {
+		t.Errorf("Expected env to configure verification, got %v %v %q", cfg.RequireEmailVerification, cfg.VerificationTTL, cfg.PublicURL)
+	}
+}
+
+func TestLoadConfig_ResponseCacheTTL(t *testing.T) {
+	if cfg := LoadConfig(); cfg.ResponseCacheTTL != 30*time.Second {
+		t.Errorf("Expected a 30s default, got %v", cfg.ResponseCacheTTL)
+	}
+	t.Setenv("RESPONSE_CACHE_TTL", "2m")
+	if cfg := LoadConfig(); cfg.ResponseCacheTTL != 2*time.Minute {
+		t.Errorf("Expected 2m, got %v", cfg.ResponseCacheTTL)
+	}
+	t.Setenv("RESPONSE_CACHE_TTL", "0")
+	if cfg := LoadConfig(); cfg.ResponseCacheTTL != 0 {
+		t.Errorf("Expected 0 to disable caching, got %v", cfg.ResponseCacheTTL)
+	}
+	t.Setenv("RESPONSE_CACHE_TTL", "0s")
+	if cfg := LoadConfig(); cfg.ResponseCacheTTL != 0 {
+		t.Errorf("Expected 0s to disable caching, got %v", cfg.ResponseCacheTTL)
+	}
+}
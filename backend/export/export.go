@@ -0,0 +1,48 @@
+// Package export writes tables as CSV or XLSX one row at a time, so large
+// exports stream to the client instead of being built in memory.
+package export
+
+import (
+	"encoding/csv"
+	"io"
+	"strings"
+)
+
+// Writer writes the rows of one table. Close must be called after the last
+// row to complete the file.
+type Writer interface {
+	WriteRow(cells []string) error
+	Close() error
+}
+
+// NewCSV returns a Writer producing RFC 4180 CSV
+func NewCSV(w io.Writer) Writer {
+	return &csvWriter{w: csv.NewWriter(w)}
+}
+
+type csvWriter struct {
+	w *csv.Writer
+}
+
+func (c *csvWriter) WriteRow(cells []string) error {
+	safe := make([]string, len(cells))
+	for i, cell := range cells {
+		safe[i] = neutralizeFormula(cell)
+	}
+	return c.w.Write(safe)
+}
+
+func (c *csvWriter) Close() error {
+	c.w.Flush()
+	return c.w.Error()
+}
+
+// neutralizeFormula prefixes cells that spreadsheet applications would
+// evaluate as formulas with a quote, so user-supplied values such as names
+// cannot run formulas on the machine opening the export
+func neutralizeFormula(cell string) string {
+	if cell != "" && strings.ContainsRune("=+-@\t\r", rune(cell[0])) {
+		return "'" + cell
+	}
+	return cell
+}
@@ -0,0 +1,91 @@
+package export
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/csv"
+	"encoding/xml"
+	"io"
+	"testing"
+)
+
+func TestCSV_NeutralizesFormulas(t *testing.T) {
+	var buf bytes.Buffer
+	w := NewCSV(&buf)
+	w.WriteRow([]string{"name", "note"})
+	w.WriteRow([]string{"=HYPERLINK(\"http://evil\")", "a, \"quoted\" value"})
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	records, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatalf("Invalid CSV: %v", err)
+	}
+	if records[1][0] != "'=HYPERLINK(\"http://evil\")" || records[1][1] != "a, \"quoted\" value" {
+		t.Errorf("Unexpected row: %q", records[1])
+	}
+}
+
+// xlsxCell is one cell of a worksheet as read back from the XML
+type xlsxCell struct {
+	Ref   string `xml:"r,attr"`
+	Style string `xml:"s,attr"`
+	Text  string `xml:"is>t"`
+}
+
+func TestXLSX_Workbook(t *testing.T) {
+	var buf bytes.Buffer
+	w, err := NewXLSX(&buf, "Attendees")
+	if err != nil {
+		t.Fatalf("NewXLSX failed: %v", err)
+	}
+	w.WriteRow([]string{"name", "email"})
+	w.WriteRow([]string{"Ada <Lovelace> & co", "ada@example.com"})
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	z, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("Not a zip file: %v", err)
+	}
+	files := make(map[string][]byte)
+	for _, f := range z.File {
+		r, _ := f.Open()
+		files[f.Name], _ = io.ReadAll(r)
+		r.Close()
+	}
+	for _, name := range []string{"[Content_Types].xml", "_rels/.rels", "xl/workbook.xml", "xl/_rels/workbook.xml.rels", "xl/styles.xml"} {
+		if files[name] == nil {
+			t.Errorf("Missing part %s", name)
+		}
+	}
+
+	var sheet struct {
+		Rows []struct {
+			Cells []xlsxCell `xml:"c"`
+		} `xml:"sheetData>row"`
+	}
+	if err := xml.Unmarshal(files["xl/worksheets/sheet1.xml"], &sheet); err != nil {
+		t.Fatalf("Invalid worksheet XML: %v", err)
+	}
+	if len(sheet.Rows) != 2 {
+		t.Fatalf("Expected 2 rows, got %d", len(sheet.Rows))
+	}
+	header, row := sheet.Rows[0].Cells, sheet.Rows[1].Cells
+	if header[0].Style != "1" || row[0].Style != "" {
+		t.Errorf("Expected only the header row to be bold")
+	}
+	if row[0].Ref != "A2" || row[0].Text != "Ada <Lovelace> & co" || row[1].Ref != "B2" {
+		t.Errorf("Unexpected cells: %+v", row)
+	}
+}
+
+func TestColumnName(t *testing.T) {
+	for i, want := range map[int]string{0: "A", 25: "Z", 26: "AA", 27: "AB", 51: "AZ", 52: "BA", 701: "ZZ", 702: "AAA"} {
+		if got := columnName(i); got != want {
+			t.Errorf("columnName(%d): expected %s, got %s", i, want, got)
+		}
+	}
+}
@@ -0,0 +1,64 @@
+package firestore
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+
+	"event-registration-backend/auth"
+)
+
+// AttemptStore implements auth.AttemptStore on the tenant's loginAttempts collection
+type AttemptStore struct{}
+
+// NewAttemptStore creates a Firestore-backed auth.AttemptStore
+func NewAttemptStore() *AttemptStore {
+	return &AttemptStore{}
+}
+
+func (s *AttemptStore) Get(ctx context.Context, key string) (*auth.AttemptRecord, error) {
+	doc, err := GetLoginAttemptsCollection().Doc(attemptDocID(key)).Get(ctx)
+	if err == ErrNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var record auth.AttemptRecord
+	if err := doc.DataTo(&record); err != nil {
+		return nil, err
+	}
+	return &record, nil
+}
+
+// Update reads and writes the record in a transaction, which Firestore
+// retries when a concurrent update wins
+func (s *AttemptStore) Update(ctx context.Context, key string, fn func(record *auth.AttemptRecord) *auth.AttemptRecord) error {
+	doc := GetLoginAttemptsCollection().Doc(attemptDocID(key))
+	return RunTransaction(ctx, func(ctx context.Context, tx TransactionInterface) error {
+		var current *auth.AttemptRecord
+		snapshot, err := tx.Get(doc)
+		switch {
+		case err == ErrNotFound:
+		case err != nil:
+			return err
+		default:
+			var record auth.AttemptRecord
+			if err := snapshot.DataTo(&record); err != nil {
+				return err
+			}
+			current = &record
+		}
+		return tx.Set(doc, fn(current))
+	})
+}
+
+func (s *AttemptStore) Reset(ctx context.Context, key string) error {
+	return GetLoginAttemptsCollection().Doc(attemptDocID(key)).Delete(ctx)
+}
+
+// attemptDocID hashes key so arbitrary usernames and IPv6 addresses are valid document IDs
+func attemptDocID(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
@@ -14,12 +14,36 @@ var (
 	Client    *firestore.Client
 	ClientID  string
 	projectID string
+
+	// localClient serves the collections from the embedded local store
+	// instead of Firestore
+	localClient *LocalClient
+)
+
+// EmulatorHostEnv names the variable the Firestore SDK reads to connect to a local emulator
+const EmulatorHostEnv = "FIRESTORE_EMULATOR_HOST"
+
+// Project and client IDs used against the emulator when none are configured.
+// The "demo-" prefix tells the emulator the project has no real backing.
+const (
+	EmulatorProjectID = "demo-event-registration"
+	EmulatorClientID  = "emulator"
 )
 
+// EmulatorHost returns the Firestore emulator address, or "" when not using the emulator
+func EmulatorHost() string {
+	return os.Getenv(EmulatorHostEnv)
+}
+
 // InitializeFirestore initializes the Firestore client and extracts client_id
+// If FIRESTORE_EMULATOR_HOST is set, connects to the emulator without credentials
 // If serviceAccountPath is provided and file exists, uses file-based authentication
 // Otherwise, uses Application Default Credentials (ADC) - suitable for Cloud Run
 func InitializeFirestore(ctx context.Context, serviceAccountPath string, gcpProjectID string, clientID string) error {
+	if EmulatorHost() != "" {
+		return initializeEmulator(ctx, gcpProjectID, clientID)
+	}
+
 	// Check if service account file exists
 	if serviceAccountPath != "" {
 		if _, err := os.Stat(serviceAccountPath); err == nil {
@@ -50,6 +74,55 @@ func InitializeFirestore(ctx context.Context, serviceAccountPath string, gcpProj
 	return nil
 }
 
+// InitializeLocal serves all collections for clientID from the embedded local
+// store snapshotted at path, instead of Firestore. An empty path keeps data in
+// memory only.
+func InitializeLocal(clientID, path string) error {
+	client := NewLocalClient()
+	if path != "" {
+		var err error
+		if client, err = OpenLocalClient(path); err != nil {
+			return err
+		}
+	}
+	ClientID = clientID
+	localClient = client
+	return nil
+}
+
+// initializeEmulator connects to the Firestore emulator, which needs no
+// credentials; the project and client IDs fall back to fixed defaults
+func initializeEmulator(ctx context.Context, gcpProjectID string, clientID string) error {
+	if gcpProjectID == "" {
+		gcpProjectID = EmulatorProjectID
+	}
+	if clientID == "" {
+		clientID = EmulatorClientID
+	}
+
+	client, err := NewEmulatorClient(ctx, gcpProjectID)
+	if err != nil {
+		return err
+	}
+	projectID = gcpProjectID
+	ClientID = clientID
+	Client = client
+	return nil
+}
+
+// NewEmulatorClient connects to the emulator at FIRESTORE_EMULATOR_HOST
+func NewEmulatorClient(ctx context.Context, gcpProjectID string) (*firestore.Client, error) {
+	if EmulatorHost() == "" {
+		return nil, fmt.Errorf("%s is not set", EmulatorHostEnv)
+	}
+	// The SDK detects the emulator from the environment and skips authentication
+	client, err := firestore.NewClient(ctx, gcpProjectID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to Firestore emulator at %s: %w", EmulatorHost(), err)
+	}
+	return client, nil
+}
+
 // initializeWithFile initializes Firestore using a service account file
 func initializeWithFile(ctx context.Context, serviceAccountPath string) error {
 	// Read service account JSON
@@ -104,37 +177,66 @@ func GetSpeakersPath() string {
 
 // GetAttendeesCollection returns the attendees collection reference
 func GetAttendeesCollection() CollectionRefInterface {
-	mockClient := getMockClient()
-	if mockClient != nil {
-		clientID := getClientID()
-		return mockClient.Collection("clients").Doc(clientID).Collection("attendees")
-	}
-	client := getClient()
-	clientID := getClientID()
-	return &RealCollectionRef{ref: client.Collection("clients").Doc(clientID).Collection("attendees")}
+	return getTenantCollection("attendees")
+}
+
+// GetAttendeeEmailsCollection returns the collection reserving the addresses
+// of attendees stored under random IDs, keyed by store.AttendeeID
+func GetAttendeeEmailsCollection() CollectionRefInterface {
+	return getTenantCollection("attendeeEmails")
 }
 
 // GetSessionsCollection returns the sessions collection reference
 func GetSessionsCollection() CollectionRefInterface {
-	mockClient := getMockClient()
-	if mockClient != nil {
-		clientID := getClientID()
-		return mockClient.Collection("clients").Doc(clientID).Collection("sessions")
-	}
-	client := getClient()
-	clientID := getClientID()
-	return &RealCollectionRef{ref: client.Collection("clients").Doc(clientID).Collection("sessions")}
+	return getTenantCollection("sessions")
 }
 
 // GetSpeakersCollection returns the speakers collection reference
 func GetSpeakersCollection() CollectionRefInterface {
-	mockClient := getMockClient()
-	if mockClient != nil {
-		clientID := getClientID()
-		return mockClient.Collection("clients").Doc(clientID).Collection("speakers")
+	return getTenantCollection("speakers")
+}
+
+// GetSettingsCollection returns the collection of event-wide settings documents
+func GetSettingsCollection() CollectionRefInterface {
+	return getTenantCollection("settings")
+}
+
+// GetRevokedTokensCollection returns the collection of revoked admin session tokens
+func GetRevokedTokensCollection() CollectionRefInterface {
+	return getTenantCollection("revokedTokens")
+}
+
+// GetAdminsCollection returns the admin accounts collection reference
+func GetAdminsCollection() CollectionRefInterface {
+	return getTenantCollection("admins")
+}
+
+// GetLoginAttemptsCollection returns the failed admin login counters collection reference
+func GetLoginAttemptsCollection() CollectionRefInterface {
+	return getTenantCollection("loginAttempts")
+}
+
+// GetAPIKeysCollection returns the admin API keys collection reference
+func GetAPIKeysCollection() CollectionRefInterface {
+	return getTenantCollection("apiKeys")
+}
+
+// GetOIDCFlowsCollection returns the in-progress single sign-on flows collection reference
+func GetOIDCFlowsCollection() CollectionRefInterface {
+	return getTenantCollection("oidcFlows")
+}
+
+// GetAuditLogCollection returns the append-only admin audit log collection reference
+func GetAuditLogCollection() CollectionRefInterface {
+	return getTenantCollection("auditLog")
+}
+
+// getTenantCollection returns the named subcollection under clients/{clientID}
+func getTenantCollection(name string) CollectionRefInterface {
+	if local := getLocalClient(); local != nil {
+		return local.Collection("clients").Doc(getClientID()).Collection(name)
 	}
 	client := getClient()
 	clientID := getClientID()
-	return &RealCollectionRef{ref: client.Collection("clients").Doc(clientID).Collection("speakers")}
+	return &RealCollectionRef{ref: client.Collection("clients").Doc(clientID).Collection(name)}
 }
-
@@ -0,0 +1,244 @@
+package firestore
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// forEachBackend runs fn against an empty collection in the local store and,
+// when FIRESTORE_EMULATOR_HOST is set, in the Firestore emulator, so the two
+// implementations of CollectionRefInterface cannot drift apart
+func forEachBackend(t *testing.T, fn func(t *testing.T, coll CollectionRefInterface)) {
+	t.Run("local", func(t *testing.T) {
+		fn(t, NewLocalClient().Collection("clients").Doc("acme").Collection("people"))
+	})
+	t.Run("emulator", func(t *testing.T) {
+		if EmulatorHost() == "" {
+			t.Skipf("%s not set", EmulatorHostEnv)
+		}
+		ctx := context.Background()
+		client, err := NewEmulatorClient(ctx, EmulatorProjectID)
+		if err != nil {
+			t.Fatalf("Failed to connect to emulator: %v", err)
+		}
+		defer client.Close()
+		// A fresh tenant per run keeps earlier data out of the results
+		tenant := "conformance-" + newLocalID()
+		fn(t, &RealCollectionRef{ref: client.Collection("clients").Doc(tenant).Collection("people")})
+	})
+}
+
+type testDoc struct {
+	ID     string            `firestore:"-"`
+	Name   string            `firestore:"name"`
+	Score  int               `firestore:"score"`
+	Tags   []string          `firestore:"tags"`
+	Seen   time.Time         `firestore:"seen"`
+	Extra  map[string]string `firestore:"extra,omitempty"`
+	Legacy *time.Time        `firestore:"legacy"`
+}
+
+func seedDocs(t *testing.T, coll CollectionRefInterface) {
+	t.Helper()
+	base := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	docs := map[string]testDoc{
+		"a": {Name: "ada", Score: 30, Tags: []string{"math"}, Seen: base},
+		"b": {Name: "bob", Score: 10, Tags: []string{"ops", "go"}, Seen: base.Add(time.Hour)},
+		"c": {Name: "cat", Score: 20, Tags: []string{"go"}, Seen: base.Add(2 * time.Hour)},
+	}
+	for id, doc := range docs {
+		if _, err := coll.Doc(id).Set(context.Background(), doc); err != nil {
+			t.Fatalf("Set %s failed: %v", id, err)
+		}
+	}
+}
+
+func docIDs(t *testing.T, q CollectionRefInterface) []string {
+	t.Helper()
+	docs, err := q.Documents(context.Background()).GetAll()
+	if err != nil {
+		t.Fatalf("Query failed: %v", err)
+	}
+	ids := make([]string, len(docs))
+	for i, doc := range docs {
+		ids[i] = doc.GetID()
+	}
+	return ids
+}
+
+func assertIDs(t *testing.T, label string, got []string, want ...string) {
+	t.Helper()
+	if len(got) != len(want) {
+		t.Errorf("%s: expected %v, got %v", label, want, got)
+		return
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("%s: expected %v, got %v", label, want, got)
+			return
+		}
+	}
+}
+
+func TestConformance_Operators(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, coll CollectionRefInterface) {
+		testOperators(t, coll)
+	})
+}
+
+func testOperators(t *testing.T, coll CollectionRefInterface) {
+	seedDocs(t, coll)
+
+	cases := []struct {
+		field string
+		op    string
+		value interface{}
+		want  []string
+	}{
+		{"name", "==", "bob", []string{"b"}},
+		{"name", "!=", "bob", []string{"a", "c"}},
+		{"score", "<", 20, []string{"b"}},
+		{"score", "<=", 20, []string{"b", "c"}},
+		{"score", ">", 10.5, []string{"a", "c"}},
+		{"score", ">=", 30, []string{"a"}},
+		{"score", ">", "10", nil},
+		{"tags", "array-contains", "go", []string{"b", "c"}},
+		{"tags", "array-contains-any", []string{"math", "ops"}, []string{"a", "b"}},
+		{"name", "in", []string{"ada", "cat"}, []string{"a", "c"}},
+		{"name", "not-in", []string{"ada", "cat"}, []string{"b"}},
+		{"seen", ">", time.Date(2026, 1, 1, 0, 30, 0, 0, time.UTC), []string{"b", "c"}},
+	}
+	for _, tc := range cases {
+		assertIDs(t, tc.field+" "+tc.op, docIDs(t, coll.Where(tc.field, tc.op, tc.value)), tc.want...)
+	}
+
+	if _, err := coll.Where("name", "~=", "x").Documents(context.Background()).GetAll(); err == nil {
+		t.Error("Expected error for unsupported operator")
+	}
+}
+
+func TestConformance_OrderAndLimit(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, coll CollectionRefInterface) {
+		testOrderAndLimit(t, coll)
+	})
+}
+
+func testOrderAndLimit(t *testing.T, coll CollectionRefInterface) {
+	seedDocs(t, coll)
+
+	assertIDs(t, "default order", docIDs(t, coll), "a", "b", "c")
+	assertIDs(t, "score asc", docIDs(t, coll.OrderBy("score", Asc)), "b", "c", "a")
+	assertIDs(t, "seen desc limit", docIDs(t, coll.OrderBy("seen", Desc).Limit(2)), "c", "b")
+	assertIDs(t, "filter then order", docIDs(t, coll.Where("tags", "array-contains", "go").OrderBy("name", Desc)), "c", "b")
+	assertIDs(t, "missing order field", docIDs(t, coll.OrderBy("extra", Asc)))
+}
+
+func TestConformance_Next(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, coll CollectionRefInterface) {
+		seedDocs(t, coll)
+
+		iter := coll.OrderBy("score", Desc).Documents(context.Background())
+		defer iter.Stop()
+		var ids []string
+		for {
+			doc, err := iter.Next()
+			if err == Done {
+				break
+			}
+			if err != nil {
+				t.Fatalf("Next failed: %v", err)
+			}
+			ids = append(ids, doc.GetID())
+		}
+		assertIDs(t, "next", ids, "a", "c", "b")
+	})
+}
+
+func TestConformance_StartAfter(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, coll CollectionRefInterface) {
+		seedDocs(t, coll)
+		// d ties with c on score, so only the document ID separates them
+		if _, err := coll.Doc("d").Set(context.Background(), testDoc{Name: "dan", Score: 20}); err != nil {
+			t.Fatalf("Set d failed: %v", err)
+		}
+
+		byScore := coll.OrderBy("score", Asc).OrderBy(DocumentID, Asc)
+		assertIDs(t, "after b", docIDs(t, byScore.StartAfter(10, "b")), "c", "d", "a")
+		assertIDs(t, "after c", docIDs(t, byScore.StartAfter(20, "c").Limit(1)), "d")
+		assertIDs(t, "after score only", docIDs(t, byScore.StartAfter(20)), "a")
+		assertIDs(t, "desc after d", docIDs(t, coll.OrderBy("score", Desc).OrderBy(DocumentID, Desc).StartAfter(20, "d")), "c", "b")
+		assertIDs(t, "filtered", docIDs(t, coll.Where("tags", "array-contains", "go").OrderBy("seen", Asc).StartAfter(time.Date(2026, 1, 1, 1, 0, 0, 0, time.UTC))), "c")
+	})
+}
+
+func TestConformance_Count(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, coll CollectionRefInterface) {
+		seedDocs(t, coll)
+		ctx := context.Background()
+		for name, tc := range map[string]struct {
+			query    CollectionRefInterface
+			expected int
+		}{
+			"all":      {coll, 3},
+			"filtered": {coll.Where("tags", "array-contains", "go"), 2},
+			"limited":  {coll.OrderBy("score", Asc).Limit(1), 1},
+			"none":     {coll.Where("score", ">", 100), 0},
+		} {
+			count, err := tc.query.Count(ctx)
+			if err != nil {
+				t.Fatalf("%s: Count failed: %v", name, err)
+			}
+			if count != tc.expected {
+				t.Errorf("%s: expected %d, got %d", name, tc.expected, count)
+			}
+		}
+	})
+}
+
+func TestConformance_RoundTripTypes(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, coll CollectionRefInterface) {
+		testRoundTripTypes(t, coll)
+	})
+}
+
+func testRoundTripTypes(t *testing.T, coll CollectionRefInterface) {
+	ctx := context.Background()
+
+	legacy := time.Date(2025, 5, 1, 12, 0, 0, 0, time.UTC)
+	in := testDoc{ID: "ignored", Name: "ada", Score: 7, Tags: []string{"x"}, Seen: legacy, Extra: map[string]string{"k": "v"}, Legacy: &legacy}
+	ref, _, err := coll.Add(ctx, in)
+	if err != nil {
+		t.Fatalf("Add failed: %v", err)
+	}
+	if len(ref.GetID()) != 20 {
+		t.Errorf("Expected a 20-character auto ID, got %q", ref.GetID())
+	}
+
+	snap, err := ref.Get(ctx)
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	var out testDoc
+	if err := snap.DataTo(&out); err != nil {
+		t.Fatalf("DataTo failed: %v", err)
+	}
+	if out.ID != "" || out.Name != "ada" || out.Score != 7 || out.Tags[0] != "x" || out.Extra["k"] != "v" || !out.Legacy.Equal(legacy) {
+		t.Errorf("Unexpected round trip: %+v", out)
+	}
+
+	// Mutating the decoded value must not change the stored document
+	out.Tags[0] = "changed"
+	snap, _ = ref.Get(ctx)
+	snap.DataTo(&out)
+	if out.Tags[0] != "x" {
+		t.Error("Expected stored document to be isolated from callers")
+	}
+
+	if err := ref.Delete(ctx); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	if _, err := ref.Get(ctx); err != ErrNotFound {
+		t.Errorf("Expected ErrNotFound after delete, got %v", err)
+	}
+}
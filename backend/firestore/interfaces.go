@@ -0,0 +1,247 @@
+package firestore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"cloud.google.com/go/firestore"
+	"cloud.google.com/go/firestore/apiv1/firestorepb"
+	"google.golang.org/api/iterator"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+var (
+	// ErrNotFound is returned by DocumentRefInterface.Get when the document does not exist
+	ErrNotFound = errors.New("document not found")
+	// ErrAlreadyExists is returned by DocumentRefInterface.Create when the document exists
+	ErrAlreadyExists = errors.New("document already exists")
+	// Done is returned by DocumentIteratorInterface.Next after the last document
+	Done = iterator.Done
+)
+
+// Direction is the sort order passed to OrderBy
+type Direction = firestore.Direction
+
+// Sort orders for OrderBy
+const (
+	Asc  = firestore.Asc
+	Desc = firestore.Desc
+)
+
+// DocumentID orders by document ID when passed to OrderBy; the matching
+// StartAfter value is the ID string
+const DocumentID = firestore.DocumentID
+
+// CollectionRefInterface defines the interface for collection operations
+type CollectionRefInterface interface {
+	Documents(ctx context.Context) DocumentIteratorInterface
+	Where(field, op string, value interface{}) CollectionRefInterface
+	OrderBy(field string, dir Direction) CollectionRefInterface
+	// StartAfter starts the results after the document with the given values
+	// of the OrderBy fields, in order
+	StartAfter(values ...interface{}) CollectionRefInterface
+	Limit(n int) CollectionRefInterface
+	// Count returns the number of documents the query matches using a
+	// server-side aggregation, without reading the documents
+	Count(ctx context.Context) (int, error)
+	Add(ctx context.Context, data interface{}) (DocumentRefInterface, *firestore.WriteResult, error)
+	Doc(id string) DocumentRefInterface
+}
+
+// DocumentIteratorInterface defines the interface for document iteration.
+// Next returns the documents one at a time, then Done; call Stop when
+// abandoning an iterator before Done.
+type DocumentIteratorInterface interface {
+	GetAll() ([]DocumentSnapshotInterface, error)
+	Next() (DocumentSnapshotInterface, error)
+	Stop()
+}
+
+// DocumentSnapshotInterface defines the interface for document snapshots
+type DocumentSnapshotInterface interface {
+	DataTo(dest interface{}) error
+	GetID() string
+	GetRef() DocumentRefInterface
+}
+
+// DocumentRefInterface defines the interface for document references
+type DocumentRefInterface interface {
+	Get(ctx context.Context) (DocumentSnapshotInterface, error)
+	// Create writes the document only if it does not exist yet
+	Create(ctx context.Context, data interface{}) (*firestore.WriteResult, error)
+	Set(ctx context.Context, data interface{}) (*firestore.WriteResult, error)
+	Delete(ctx context.Context) error
+	Collection(name string) CollectionRefInterface
+	GetID() string
+}
+
+// RealCollectionRef wraps a real Firestore collection reference
+type RealCollectionRef struct {
+	ref      *firestore.CollectionRef
+	query    firestore.Query
+	hasQuery bool
+}
+
+func (r *RealCollectionRef) Documents(ctx context.Context) DocumentIteratorInterface {
+	if r.hasQuery {
+		return &RealDocumentIterator{iter: r.query.Documents(ctx)}
+	}
+	return &RealDocumentIterator{iter: r.ref.Documents(ctx)}
+}
+
+func (r *RealCollectionRef) Where(field, op string, value interface{}) CollectionRefInterface {
+	var q firestore.Query
+	if r.hasQuery {
+		q = r.query.Where(field, op, value)
+	} else {
+		q = r.ref.Where(field, op, value)
+	}
+	return &RealCollectionRef{ref: r.ref, query: q, hasQuery: true}
+}
+
+func (r *RealCollectionRef) OrderBy(field string, dir Direction) CollectionRefInterface {
+	var q firestore.Query
+	if r.hasQuery {
+		q = r.query.OrderBy(field, dir)
+	} else {
+		q = r.ref.OrderBy(field, dir)
+	}
+	return &RealCollectionRef{ref: r.ref, query: q, hasQuery: true}
+}
+
+func (r *RealCollectionRef) StartAfter(values ...interface{}) CollectionRefInterface {
+	var q firestore.Query
+	if r.hasQuery {
+		q = r.query.StartAfter(values...)
+	} else {
+		q = r.ref.StartAfter(values...)
+	}
+	return &RealCollectionRef{ref: r.ref, query: q, hasQuery: true}
+}
+
+func (r *RealCollectionRef) Limit(n int) CollectionRefInterface {
+	var q firestore.Query
+	if r.hasQuery {
+		q = r.query.Limit(n)
+	} else {
+		q = r.ref.Limit(n)
+	}
+	return &RealCollectionRef{ref: r.ref, query: q, hasQuery: true}
+}
+
+func (r *RealCollectionRef) Count(ctx context.Context) (int, error) {
+	q := r.ref.Query
+	if r.hasQuery {
+		q = r.query
+	}
+	result, err := q.NewAggregationQuery().WithCount("count").Get(ctx)
+	if err != nil {
+		return 0, err
+	}
+	count, ok := result["count"].(*firestorepb.Value)
+	if !ok {
+		return 0, fmt.Errorf("unexpected count result %T", result["count"])
+	}
+	return int(count.GetIntegerValue()), nil
+}
+
+func (r *RealCollectionRef) Add(ctx context.Context, data interface{}) (DocumentRefInterface, *firestore.WriteResult, error) {
+	docRef, wr, err := r.ref.Add(ctx, data)
+	if err != nil {
+		return nil, nil, err
+	}
+	return &RealDocumentRef{ref: docRef}, wr, nil
+}
+
+func (r *RealCollectionRef) Doc(id string) DocumentRefInterface {
+	return &RealDocumentRef{ref: r.ref.Doc(id)}
+}
+
+// RealDocumentIterator wraps a real Firestore document iterator
+type RealDocumentIterator struct {
+	iter *firestore.DocumentIterator
+}
+
+func (r *RealDocumentIterator) GetAll() ([]DocumentSnapshotInterface, error) {
+	docs, err := r.iter.GetAll()
+	if err != nil {
+		return nil, err
+	}
+	result := make([]DocumentSnapshotInterface, len(docs))
+	for i, doc := range docs {
+		result[i] = &RealDocumentSnapshot{snapshot: doc}
+	}
+	return result, nil
+}
+
+func (r *RealDocumentIterator) Next() (DocumentSnapshotInterface, error) {
+	doc, err := r.iter.Next()
+	if err != nil {
+		return nil, err
+	}
+	return &RealDocumentSnapshot{snapshot: doc}, nil
+}
+
+func (r *RealDocumentIterator) Stop() {
+	r.iter.Stop()
+}
+
+// RealDocumentSnapshot wraps a real Firestore document snapshot
+type RealDocumentSnapshot struct {
+	snapshot *firestore.DocumentSnapshot
+}
+
+func (r *RealDocumentSnapshot) DataTo(dest interface{}) error {
+	return r.snapshot.DataTo(dest)
+}
+
+func (r *RealDocumentSnapshot) GetID() string {
+	return r.snapshot.Ref.ID
+}
+
+func (r *RealDocumentSnapshot) GetRef() DocumentRefInterface {
+	return &RealDocumentRef{ref: r.snapshot.Ref}
+}
+
+// RealDocumentRef wraps a real Firestore document reference
+type RealDocumentRef struct {
+	ref *firestore.DocumentRef
+}
+
+func (r *RealDocumentRef) Get(ctx context.Context) (DocumentSnapshotInterface, error) {
+	snapshot, err := r.ref.Get(ctx)
+	if err != nil {
+		if status.Code(err) == codes.NotFound {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return &RealDocumentSnapshot{snapshot: snapshot}, nil
+}
+
+func (r *RealDocumentRef) Create(ctx context.Context, data interface{}) (*firestore.WriteResult, error) {
+	wr, err := r.ref.Create(ctx, data)
+	if status.Code(err) == codes.AlreadyExists {
+		return nil, ErrAlreadyExists
+	}
+	return wr, err
+}
+
+func (r *RealDocumentRef) Set(ctx context.Context, data interface{}) (*firestore.WriteResult, error) {
+	return r.ref.Set(ctx, data)
+}
+
+func (r *RealDocumentRef) Delete(ctx context.Context) error {
+	_, err := r.ref.Delete(ctx)
+	return err
+}
+
+func (r *RealDocumentRef) Collection(name string) CollectionRefInterface {
+	return &RealCollectionRef{ref: r.ref.Collection(name)}
+}
+
+func (r *RealDocumentRef) GetID() string {
+	return r.ref.ID
+}
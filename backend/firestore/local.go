@@ -0,0 +1,833 @@
+package firestore
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+
+	"cloud.google.com/go/firestore"
+)
+
+// LocalClient is an embedded document store with Firestore query semantics.
+// Collections are created on first use at any path, queries support every
+// Firestore comparison operator and ordering, and when opened with a file
+// path each commit is appended to a log beside the snapshot so data survives
+// restarts. The log is folded into the snapshot once it outgrows the store.
+type LocalClient struct {
+	mu sync.RWMutex
+	// collections maps a collection path to its documents by ID
+	collections map[string]map[string]map[string]interface{}
+	// path is the snapshot file; empty keeps data in memory only
+	path string
+	// log is the append-only log of commits since the snapshot, opened on
+	// first write
+	log *os.File
+	// logSize is the length of the log's complete entries
+	logSize int64
+	// logged counts the document writes in the log
+	logged int
+	// version counts committed writes so transactions can detect interference
+	version uint64
+	// txMu serializes transactions
+	txMu sync.Mutex
+}
+
+// localSnapshot is the on-disk format of a LocalClient
+type localSnapshot struct {
+	Version     int                                          `json:"version"`
+	Collections map[string]map[string]map[string]interface{} `json:"collections"`
+}
+
+const localSnapshotVersion = 1
+
+// localLogEntry is one commit in the log, written as a line of JSON
+type localLogEntry struct {
+	Writes []localLogWrite `json:"writes"`
+}
+
+// localLogWrite is one document change in a localLogEntry; a null doc
+// deletes the document
+type localLogWrite struct {
+	Collection string                 `json:"collection"`
+	ID         string                 `json:"id"`
+	Doc        map[string]interface{} `json:"doc"`
+}
+
+// minLocalCompactWrites is the log length below which it is never compacted
+const minLocalCompactWrites = 1000
+
+// NewLocalClient creates an empty in-memory store
+func NewLocalClient() *LocalClient {
+	return &LocalClient{collections: make(map[string]map[string]map[string]interface{})}
+}
+
+// OpenLocalClient loads the store snapshotted at path and replays its log,
+// creating both on first write
+func OpenLocalClient(path string) (*LocalClient, error) {
+	c := NewLocalClient()
+	c.path = path
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create local store directory: %w", err)
+	}
+	if err := c.loadSnapshot(); err != nil {
+		return nil, err
+	}
+	if err := c.replayLog(); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// logPath returns the path of the log beside the snapshot
+func (c *LocalClient) logPath() string {
+	return c.path + ".log"
+}
+
+// loadSnapshot reads the snapshot file, if there is one
+func (c *LocalClient) loadSnapshot() error {
+	path := c.path
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read local store: %w", err)
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var snapshot localSnapshot
+	if err := decoder.Decode(&snapshot); err != nil {
+		return fmt.Errorf("failed to parse local store %s: %w", path, err)
+	}
+	if snapshot.Version != localSnapshotVersion {
+		return fmt.Errorf("unsupported local store version %d in %s", snapshot.Version, path)
+	}
+	for collection, docs := range snapshot.Collections {
+		c.collections[collection] = make(map[string]map[string]interface{}, len(docs))
+		for id, doc := range docs {
+			decoded, err := fromJSONValue(doc)
+			if err != nil {
+				return fmt.Errorf("failed to decode %s/%s: %w", collection, id, err)
+			}
+			c.collections[collection][id] = decoded.(map[string]interface{})
+		}
+	}
+	return nil
+}
+
+// replayLog applies the commits logged after the snapshot. Replaying a commit
+// the snapshot already holds is harmless, since each write stores or deletes
+// a whole document. A last line cut short by a crash is dropped from the log.
+func (c *LocalClient) replayLog() error {
+	f, err := os.Open(c.logPath())
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read local store log: %w", err)
+	}
+	defer f.Close()
+
+	reader := bufio.NewReader(f)
+	for {
+		line, err := reader.ReadBytes('\n')
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read local store log: %w", err)
+		}
+
+		decoder := json.NewDecoder(bytes.NewReader(line))
+		decoder.UseNumber()
+		var entry localLogEntry
+		if err := decoder.Decode(&entry); err != nil {
+			return fmt.Errorf("failed to parse local store log %s at offset %d: %w", c.logPath(), c.logSize, err)
+		}
+		for _, w := range entry.Writes {
+			docs := c.collections[w.Collection]
+			if docs == nil {
+				docs = make(map[string]map[string]interface{})
+				c.collections[w.Collection] = docs
+			}
+			if w.Doc == nil {
+				delete(docs, w.ID)
+				continue
+			}
+			decoded, err := fromJSONValue(w.Doc)
+			if err != nil {
+				return fmt.Errorf("failed to decode %s/%s: %w", w.Collection, w.ID, err)
+			}
+			docs[w.ID] = decoded.(map[string]interface{})
+		}
+		c.logSize += int64(len(line))
+		c.logged += len(entry.Writes)
+	}
+
+	if err := os.Truncate(c.logPath(), c.logSize); err != nil {
+		return fmt.Errorf("failed to repair local store log: %w", err)
+	}
+	return nil
+}
+
+// Collection returns a reference to the top-level collection name
+func (c *LocalClient) Collection(name string) CollectionRefInterface {
+	return &LocalCollectionRef{client: c, path: name}
+}
+
+// localWrite is one pending document change; a nil doc deletes the document
+type localWrite struct {
+	collection string
+	id         string
+	doc        map[string]interface{}
+	create     bool
+}
+
+// errLocalConflict aborts a transaction commit when another write landed after its reads
+var errLocalConflict = errors.New("transaction conflict")
+
+// maxLocalTransactionAttempts matches the Firestore client's default retry count
+const maxLocalTransactionAttempts = 5
+
+// write stores doc, or deletes the document when doc is nil, and appends the
+// change to the log. With create set it fails with ErrAlreadyExists instead of
+// replacing a document. The change is rolled back if it cannot be logged.
+func (c *LocalClient) write(collection, id string, doc map[string]interface{}, create bool) error {
+	return c.commit([]localWrite{{collection: collection, id: id, doc: doc, create: create}}, nil)
+}
+
+// commit applies writes all together and appends them to the log as one
+// entry, or applies none of them if they cannot be logged. The log is
+// compacted into the snapshot once it holds at least minLocalCompactWrites
+// writes and more than the store has documents. If readVersion is set and the
+// store changed since, it fails with errLocalConflict.
+func (c *LocalClient) commit(writes []localWrite, readVersion *uint64) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if readVersion != nil && *readVersion != c.version {
+		return errLocalConflict
+	}
+
+	type undo struct {
+		docs    map[string]map[string]interface{}
+		id      string
+		prev    map[string]interface{}
+		existed bool
+	}
+	var undos []undo
+	rollback := func() {
+		for i := len(undos) - 1; i >= 0; i-- {
+			u := undos[i]
+			if u.existed {
+				u.docs[u.id] = u.prev
+			} else {
+				delete(u.docs, u.id)
+			}
+		}
+	}
+
+	for _, w := range writes {
+		docs := c.collections[w.collection]
+		if docs == nil {
+			docs = make(map[string]map[string]interface{})
+			c.collections[w.collection] = docs
+		}
+		prev, existed := docs[w.id]
+		if w.create && existed {
+			rollback()
+			return ErrAlreadyExists
+		}
+		if w.doc == nil && !existed {
+			continue
+		}
+		undos = append(undos, undo{docs: docs, id: w.id, prev: prev, existed: existed})
+		if w.doc == nil {
+			delete(docs, w.id)
+		} else {
+			docs[w.id] = w.doc
+		}
+	}
+	if len(undos) == 0 {
+		return nil
+	}
+
+	if err := c.persist(writes); err != nil {
+		rollback()
+		return err
+	}
+	c.version++
+	return nil
+}
+
+// RunTransaction runs fn with reads of committed data and buffers its writes,
+// committing them together once fn succeeds. Transactions are serialized with
+// each other; if a write outside a transaction lands in between, fn is retried.
+func (c *LocalClient) RunTransaction(ctx context.Context, fn func(ctx context.Context, tx TransactionInterface) error) error {
+	c.txMu.Lock()
+	defer c.txMu.Unlock()
+
+	for attempt := 0; attempt < maxLocalTransactionAttempts; attempt++ {
+		c.mu.RLock()
+		version := c.version
+		c.mu.RUnlock()
+
+		tx := &localTransaction{ctx: ctx, client: c}
+		if err := fn(ctx, tx); err != nil {
+			return err
+		}
+		if err := c.commit(tx.writes, &version); err != errLocalConflict {
+			return err
+		}
+	}
+	return fmt.Errorf("transaction failed after %d attempts: %w", maxLocalTransactionAttempts, errLocalConflict)
+}
+
+// localTransaction buffers the writes of one LocalClient transaction attempt
+type localTransaction struct {
+	ctx    context.Context
+	client *LocalClient
+	writes []localWrite
+}
+
+func (t *localTransaction) Get(doc DocumentRefInterface) (DocumentSnapshotInterface, error) {
+	ref, err := t.docRef(doc)
+	if err != nil {
+		return nil, err
+	}
+	return ref.Get(t.ctx)
+}
+
+func (t *localTransaction) Documents(query CollectionRefInterface) ([]DocumentSnapshotInterface, error) {
+	q, ok := query.(*LocalCollectionRef)
+	if !ok || q.client != t.client {
+		return nil, fmt.Errorf("query %T does not belong to this local store", query)
+	}
+	return q.Documents(t.ctx).GetAll()
+}
+
+func (t *localTransaction) Create(doc DocumentRefInterface, data interface{}) error {
+	return t.add(doc, data, true)
+}
+
+func (t *localTransaction) Set(doc DocumentRefInterface, data interface{}) error {
+	return t.add(doc, data, false)
+}
+
+func (t *localTransaction) Delete(doc DocumentRefInterface) error {
+	ref, err := t.docRef(doc)
+	if err != nil {
+		return err
+	}
+	t.writes = append(t.writes, localWrite{collection: ref.collection, id: ref.ID})
+	return nil
+}
+
+func (t *localTransaction) add(doc DocumentRefInterface, data interface{}, create bool) error {
+	ref, err := t.docRef(doc)
+	if err != nil {
+		return err
+	}
+	encoded, err := encodeDocument(data)
+	if err != nil {
+		return err
+	}
+	t.writes = append(t.writes, localWrite{collection: ref.collection, id: ref.ID, doc: encoded, create: create})
+	return nil
+}
+
+// docRef unwraps a document reference, which must belong to the transaction's store
+func (t *localTransaction) docRef(doc DocumentRefInterface) (*LocalDocumentRef, error) {
+	ref, ok := doc.(*LocalDocumentRef)
+	if !ok || ref.client != t.client {
+		return nil, fmt.Errorf("document %T does not belong to this local store", doc)
+	}
+	return ref, nil
+}
+
+// persist appends the committed writes to the log and compacts the log once
+// it holds more writes than the store has documents. Callers must hold c.mu.
+func (c *LocalClient) persist(writes []localWrite) error {
+	if c.path == "" {
+		return nil
+	}
+
+	entry := localLogEntry{Writes: make([]localLogWrite, len(writes))}
+	for i, w := range writes {
+		entry.Writes[i] = localLogWrite{Collection: w.collection, ID: w.id}
+		if w.doc != nil {
+			entry.Writes[i].Doc = toJSONValue(w.doc).(map[string]interface{})
+		}
+	}
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("failed to encode local store write: %w", err)
+	}
+	line = append(line, '\n')
+
+	if c.log == nil {
+		if c.log, err = os.OpenFile(c.logPath(), os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o644); err != nil {
+			c.log = nil
+			return fmt.Errorf("failed to open local store log: %w", err)
+		}
+	}
+	if _, err := c.log.Write(line); err != nil {
+		// Drop a partly written entry so that it is not replayed
+		c.log.Truncate(c.logSize)
+		return fmt.Errorf("failed to write local store log: %w", err)
+	}
+	if err := c.log.Sync(); err != nil {
+		c.log.Truncate(c.logSize)
+		return fmt.Errorf("failed to write local store log: %w", err)
+	}
+	c.logSize += int64(len(line))
+	c.logged += len(writes)
+
+	if c.logged >= minLocalCompactWrites && c.logged > c.documentCount() {
+		// The commit is already durable in the log, which stays valid if
+		// compaction fails
+		if err := c.compact(); err != nil {
+			log.Printf("Failed to compact local store: %v", err)
+		}
+	}
+	return nil
+}
+
+// documentCount returns the number of documents in the store. Callers must
+// hold c.mu.
+func (c *LocalClient) documentCount() int {
+	n := 0
+	for _, docs := range c.collections {
+		n += len(docs)
+	}
+	return n
+}
+
+// compact atomically replaces the snapshot file with the whole store and
+// empties the log. Callers must hold c.mu.
+func (c *LocalClient) compact() error {
+	snapshot := localSnapshot{
+		Version:     localSnapshotVersion,
+		Collections: make(map[string]map[string]map[string]interface{}, len(c.collections)),
+	}
+	for collection, docs := range c.collections {
+		if len(docs) == 0 {
+			continue
+		}
+		out := make(map[string]map[string]interface{}, len(docs))
+		for id, doc := range docs {
+			out[id] = toJSONValue(doc).(map[string]interface{})
+		}
+		snapshot.Collections[collection] = out
+	}
+	data, err := json.Marshal(snapshot)
+	if err != nil {
+		return fmt.Errorf("failed to encode local store: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(c.path), filepath.Base(c.path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to write local store: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write local store: %w", err)
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write local store: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write local store: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), c.path); err != nil {
+		return fmt.Errorf("failed to write local store: %w", err)
+	}
+
+	// A crash before the log is emptied only replays writes the new snapshot
+	// already holds
+	if err := c.log.Truncate(0); err != nil {
+		return fmt.Errorf("failed to empty local store log: %w", err)
+	}
+	c.logSize, c.logged = 0, 0
+	return nil
+}
+
+// LocalCollectionRef is a collection, or a query over one, in a LocalClient.
+// Queries are evaluated when Documents is called.
+type LocalCollectionRef struct {
+	client  *LocalClient
+	path    string
+	filters []localFilter
+	orders  []localOrder
+	// startAfter holds the encoded StartAfter values, one per leading order
+	startAfter []interface{}
+	limit      int
+	err        error
+}
+
+type localFilter struct {
+	field string
+	op    string
+	value interface{}
+}
+
+type localOrder struct {
+	field string
+	dir   Direction
+}
+
+// query returns a copy of c that can be refined without affecting c
+func (c *LocalCollectionRef) query() *LocalCollectionRef {
+	q := *c
+	q.filters = append([]localFilter(nil), c.filters...)
+	q.orders = append([]localOrder(nil), c.orders...)
+	q.startAfter = append([]interface{}(nil), c.startAfter...)
+	return &q
+}
+
+func (c *LocalCollectionRef) Documents(ctx context.Context) DocumentIteratorInterface {
+	if c.err != nil {
+		return &localIterator{err: c.err}
+	}
+	if len(c.startAfter) > len(c.orders) {
+		return &localIterator{err: fmt.Errorf("StartAfter has %d values for %d OrderBy fields", len(c.startAfter), len(c.orders))}
+	}
+
+	c.client.mu.RLock()
+	defer c.client.mu.RUnlock()
+
+	var docs []*LocalDocumentSnapshot
+	for id, data := range c.client.collections[c.path] {
+		if !c.matches(data) {
+			continue
+		}
+		docs = append(docs, &LocalDocumentSnapshot{
+			ID:   id,
+			Data: copyValue(data).(map[string]interface{}),
+			Ref:  c.doc(id),
+		})
+	}
+
+	sort.Slice(docs, func(i, j int) bool {
+		return c.less(docs[i], docs[j])
+	})
+	if c.startAfter != nil {
+		start := sort.Search(len(docs), func(i int) bool {
+			return c.afterCursor(docs[i])
+		})
+		docs = docs[start:]
+	}
+	if c.limit > 0 && len(docs) > c.limit {
+		docs = docs[:c.limit]
+	}
+	return &localIterator{docs: docs}
+}
+
+// matches reports whether data passes every filter and has every ordered field
+func (c *LocalCollectionRef) matches(data map[string]interface{}) bool {
+	for _, f := range c.filters {
+		value, ok := lookupField(data, f.field)
+		if !ok || !matchFilter(value, f.op, f.value) {
+			return false
+		}
+	}
+	for _, o := range c.orders {
+		if _, ok := lookupField(data, o.field); !ok && o.field != DocumentID {
+			return false
+		}
+	}
+	return true
+}
+
+// orderValue returns the value doc is ordered by for field
+func orderValue(doc *LocalDocumentSnapshot, field string) interface{} {
+	if field == DocumentID {
+		return doc.ID
+	}
+	value, _ := lookupField(doc.Data, field)
+	return value
+}
+
+// afterCursor reports whether doc sorts strictly after the StartAfter values.
+// A document equal on every cursor value is not after it.
+func (c *LocalCollectionRef) afterCursor(doc *LocalDocumentSnapshot) bool {
+	for i, value := range c.startAfter {
+		o := c.orders[i]
+		if cmp := compareValues(orderValue(doc, o.field), value); cmp != 0 {
+			return (cmp > 0) == (o.dir != Desc)
+		}
+	}
+	return false
+}
+
+// less orders documents by the OrderBy fields, then by ID in the direction of
+// the last ordering, as Firestore does
+func (c *LocalCollectionRef) less(a, b *LocalDocumentSnapshot) bool {
+	idDir := Asc
+	for _, o := range c.orders {
+		av, bv := orderValue(a, o.field), orderValue(b, o.field)
+		if cmp := compareValues(av, bv); cmp != 0 {
+			return (cmp < 0) == (o.dir != Desc)
+		}
+		idDir = o.dir
+	}
+	if idDir == Desc {
+		return a.ID > b.ID
+	}
+	return a.ID < b.ID
+}
+
+// matchFilter applies one Firestore comparison operator
+func matchFilter(value interface{}, op string, operand interface{}) bool {
+	switch op {
+	case "==":
+		return compareValues(value, operand) == 0
+	case "!=":
+		return value != nil && compareValues(value, operand) != 0
+	case "<", "<=", ">", ">=":
+		if valueClass(value) != valueClass(operand) {
+			return false
+		}
+		cmp := compareValues(value, operand)
+		switch op {
+		case "<":
+			return cmp < 0
+		case "<=":
+			return cmp <= 0
+		case ">":
+			return cmp > 0
+		}
+		return cmp >= 0
+	case "array-contains":
+		return containsValue(value, operand)
+	case "array-contains-any":
+		candidates, _ := operand.([]interface{})
+		for _, candidate := range candidates {
+			if containsValue(value, candidate) {
+				return true
+			}
+		}
+		return false
+	case "in":
+		return containsValue(operand, value)
+	case "not-in":
+		return value != nil && !containsValue(operand, value)
+	}
+	return false
+}
+
+// containsValue reports whether list is an array holding value
+func containsValue(list, value interface{}) bool {
+	items, ok := list.([]interface{})
+	if !ok {
+		return false
+	}
+	for _, item := range items {
+		if compareValues(item, value) == 0 {
+			return true
+		}
+	}
+	return false
+}
+
+var localOperators = map[string]bool{
+	"==": true, "!=": true, "<": true, "<=": true, ">": true, ">=": true,
+	"array-contains": true, "array-contains-any": true, "in": true, "not-in": true,
+}
+
+func (c *LocalCollectionRef) Where(field, op string, value interface{}) CollectionRefInterface {
+	q := c.query()
+	if !localOperators[op] {
+		q.err = fmt.Errorf("unsupported operator %q", op)
+		return q
+	}
+	operand, err := encodeValue(reflect.ValueOf(value))
+	if err != nil {
+		q.err = fmt.Errorf("invalid value for %s: %w", field, err)
+		return q
+	}
+	if _, isList := operand.([]interface{}); (op == "in" || op == "not-in" || op == "array-contains-any") && !isList {
+		q.err = fmt.Errorf("operator %q requires a slice value", op)
+		return q
+	}
+	q.filters = append(q.filters, localFilter{field: field, op: op, value: operand})
+	return q
+}
+
+func (c *LocalCollectionRef) OrderBy(field string, dir Direction) CollectionRefInterface {
+	q := c.query()
+	q.orders = append(q.orders, localOrder{field: field, dir: dir})
+	return q
+}
+
+func (c *LocalCollectionRef) StartAfter(values ...interface{}) CollectionRefInterface {
+	q := c.query()
+	q.startAfter = make([]interface{}, len(values))
+	for i, value := range values {
+		encoded, err := encodeValue(reflect.ValueOf(value))
+		if err != nil {
+			q.err = fmt.Errorf("invalid StartAfter value: %w", err)
+			return q
+		}
+		q.startAfter[i] = encoded
+	}
+	return q
+}
+
+func (c *LocalCollectionRef) Limit(n int) CollectionRefInterface {
+	q := c.query()
+	q.limit = n
+	return q
+}
+
+func (c *LocalCollectionRef) Count(ctx context.Context) (int, error) {
+	docs, err := c.Documents(ctx).GetAll()
+	return len(docs), err
+}
+
+func (c *LocalCollectionRef) Add(ctx context.Context, data interface{}) (DocumentRefInterface, *firestore.WriteResult, error) {
+	ref := c.doc(newLocalID())
+	if _, err := ref.Create(ctx, data); err != nil {
+		return nil, nil, err
+	}
+	return ref, &firestore.WriteResult{}, nil
+}
+
+func (c *LocalCollectionRef) Doc(id string) DocumentRefInterface {
+	return c.doc(id)
+}
+
+func (c *LocalCollectionRef) doc(id string) *LocalDocumentRef {
+	return &LocalDocumentRef{client: c.client, collection: c.path, ID: id}
+}
+
+// LocalDocumentRef is a document in a LocalClient
+type LocalDocumentRef struct {
+	client     *LocalClient
+	collection string
+	ID         string
+}
+
+func (d *LocalDocumentRef) Get(ctx context.Context) (DocumentSnapshotInterface, error) {
+	d.client.mu.RLock()
+	defer d.client.mu.RUnlock()
+
+	data, ok := d.client.collections[d.collection][d.ID]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return &LocalDocumentSnapshot{ID: d.ID, Data: copyValue(data).(map[string]interface{}), Ref: d}, nil
+}
+
+func (d *LocalDocumentRef) Create(ctx context.Context, data interface{}) (*firestore.WriteResult, error) {
+	return d.set(data, true)
+}
+
+func (d *LocalDocumentRef) Set(ctx context.Context, data interface{}) (*firestore.WriteResult, error) {
+	return d.set(data, false)
+}
+
+func (d *LocalDocumentRef) set(data interface{}, create bool) (*firestore.WriteResult, error) {
+	doc, err := encodeDocument(data)
+	if err != nil {
+		return nil, err
+	}
+	if err := d.client.write(d.collection, d.ID, doc, create); err != nil {
+		return nil, err
+	}
+	return &firestore.WriteResult{}, nil
+}
+
+func (d *LocalDocumentRef) Delete(ctx context.Context) error {
+	return d.client.write(d.collection, d.ID, nil, false)
+}
+
+func (d *LocalDocumentRef) Collection(name string) CollectionRefInterface {
+	return &LocalCollectionRef{client: d.client, path: d.collection + "/" + d.ID + "/" + name}
+}
+
+func (d *LocalDocumentRef) GetID() string {
+	return d.ID
+}
+
+// LocalDocumentSnapshot is a copy of a document read from a LocalClient
+type LocalDocumentSnapshot struct {
+	ID   string
+	Data map[string]interface{}
+	Ref  *LocalDocumentRef
+}
+
+func (s *LocalDocumentSnapshot) DataTo(dest interface{}) error {
+	return decodeDocument(s.Data, dest)
+}
+
+func (s *LocalDocumentSnapshot) GetID() string {
+	return s.ID
+}
+
+func (s *LocalDocumentSnapshot) GetRef() DocumentRefInterface {
+	return s.Ref
+}
+
+// localIterator returns the result of a LocalCollectionRef query
+type localIterator struct {
+	docs []*LocalDocumentSnapshot
+	err  error
+	next int
+}
+
+func (it *localIterator) Next() (DocumentSnapshotInterface, error) {
+	if it.err != nil {
+		return nil, it.err
+	}
+	if it.next >= len(it.docs) {
+		return nil, Done
+	}
+	it.next++
+	return it.docs[it.next-1], nil
+}
+
+func (it *localIterator) Stop() {
+	it.docs = nil
+}
+
+func (it *localIterator) GetAll() ([]DocumentSnapshotInterface, error) {
+	if it.err != nil {
+		return nil, it.err
+	}
+	result := make([]DocumentSnapshotInterface, len(it.docs))
+	for i, doc := range it.docs {
+		result[i] = doc
+	}
+	return result, nil
+}
+
+// newLocalID returns a random 20-character alphanumeric ID like Firestore's auto IDs
+func newLocalID() string {
+	const alphabet = "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789"
+	b := make([]byte, 20)
+	if _, err := rand.Read(b); err != nil {
+		panic(fmt.Sprintf("failed to generate document ID: %v", err))
+	}
+	var id strings.Builder
+	for _, c := range b {
+		id.WriteByte(alphabet[int(c)%len(alphabet)])
+	}
+	return id.String()
+}
@@ -0,0 +1,214 @@
+package firestore
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestLocalClient_Persistence(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "store.json")
+
+	client, err := OpenLocalClient(path)
+	if err != nil {
+		t.Fatalf("OpenLocalClient failed: %v", err)
+	}
+	seedDocs(t, client.Collection("clients").Doc("acme").Collection("people"))
+	client.Collection("clients").Doc("acme").Collection("people").Doc("c").Delete(ctx)
+
+	reopened, err := OpenLocalClient(path)
+	if err != nil {
+		t.Fatalf("Reopen failed: %v", err)
+	}
+	coll := reopened.Collection("clients").Doc("acme").Collection("people")
+	assertIDs(t, "reloaded", docIDs(t, coll.OrderBy("seen", Asc)), "a", "b")
+
+	snap, err := coll.Doc("b").Get(ctx)
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	var doc testDoc
+	if err := snap.DataTo(&doc); err != nil {
+		t.Fatalf("DataTo failed: %v", err)
+	}
+	if doc.Score != 10 || !doc.Seen.Equal(time.Date(2026, 1, 1, 1, 0, 0, 0, time.UTC)) || len(doc.Tags) != 2 {
+		t.Errorf("Unexpected reloaded document: %+v", doc)
+	}
+
+	if other := reopened.Collection("clients").Doc("globex").Collection("people"); len(docIDs(t, other)) != 0 {
+		t.Error("Expected another tenant's collection to be empty")
+	}
+}
+
+func TestLocalClient_LogCompaction(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "store.json")
+
+	client, err := OpenLocalClient(path)
+	if err != nil {
+		t.Fatalf("OpenLocalClient failed: %v", err)
+	}
+	counter := client.Collection("counters").Doc("visits")
+	for i := 1; i <= minLocalCompactWrites; i++ {
+		if _, err := counter.Set(ctx, counterDoc{N: i}); err != nil {
+			t.Fatalf("Set failed: %v", err)
+		}
+	}
+	if _, err := os.Stat(path); err != nil {
+		t.Fatalf("Expected the log to be compacted into a snapshot: %v", err)
+	}
+	if info, err := os.Stat(path + ".log"); err != nil || info.Size() != 0 {
+		t.Fatalf("Expected an empty log after compaction, got %v %v", info, err)
+	}
+	if _, err := counter.Set(ctx, counterDoc{N: -1}); err != nil {
+		t.Fatalf("Set failed: %v", err)
+	}
+
+	reopened, err := OpenLocalClient(path)
+	if err != nil {
+		t.Fatalf("Reopen failed: %v", err)
+	}
+	snap, err := reopened.Collection("counters").Doc("visits").Get(ctx)
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	var doc counterDoc
+	if err := snap.DataTo(&doc); err != nil || doc.N != -1 {
+		t.Errorf("Expected the write after compaction to be replayed, got %+v %v", doc, err)
+	}
+}
+
+func TestLocalClient_TornLogEntry(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "store.json")
+
+	client, err := OpenLocalClient(path)
+	if err != nil {
+		t.Fatalf("OpenLocalClient failed: %v", err)
+	}
+	seedDocs(t, client.Collection("people"))
+
+	// Simulate a crash part way through appending a commit
+	log, err := os.OpenFile(path+".log", os.O_WRONLY|os.O_APPEND, 0)
+	if err != nil {
+		t.Fatalf("Failed to open log: %v", err)
+	}
+	log.WriteString(`{"writes":[{"collection":"people","id":"a","doc":nu`)
+	log.Close()
+
+	reopened, err := OpenLocalClient(path)
+	if err != nil {
+		t.Fatalf("Reopen failed: %v", err)
+	}
+	assertIDs(t, "after torn write", docIDs(t, reopened.Collection("people").OrderBy("seen", Asc)), "a", "b", "c")
+
+	if _, err := reopened.Collection("people").Doc("d").Set(ctx, testDoc{Name: "dan"}); err != nil {
+		t.Fatalf("Set failed: %v", err)
+	}
+	again, err := OpenLocalClient(path)
+	if err != nil {
+		t.Fatalf("Reopen after repair failed: %v", err)
+	}
+	assertIDs(t, "after repair", docIDs(t, again.Collection("people")), "a", "b", "c", "d")
+}
+
+type counterDoc struct {
+	N int `firestore:"n"`
+}
+
+// incrementCounter adds one to the counter document in a transaction
+func incrementCounter(ctx context.Context, client *LocalClient, ref DocumentRefInterface) error {
+	return client.RunTransaction(ctx, func(ctx context.Context, tx TransactionInterface) error {
+		var counter counterDoc
+		snap, err := tx.Get(ref)
+		if err == nil {
+			err = snap.DataTo(&counter)
+		}
+		if err != nil && err != ErrNotFound {
+			return err
+		}
+		counter.N++
+		return tx.Set(ref, counter)
+	})
+}
+
+func TestLocalClient_TransactionsSerialize(t *testing.T) {
+	ctx := context.Background()
+	client := NewLocalClient()
+	ref := client.Collection("counters").Doc("visits")
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := incrementCounter(ctx, client, ref); err != nil {
+				t.Errorf("Transaction failed: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	snap, err := ref.Get(ctx)
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	var counter counterDoc
+	snap.DataTo(&counter)
+	if counter.N != 20 {
+		t.Errorf("Expected 20 increments, got %d", counter.N)
+	}
+}
+
+func TestLocalClient_TransactionRetriesAfterOutsideWrite(t *testing.T) {
+	ctx := context.Background()
+	client := NewLocalClient()
+	ref := client.Collection("counters").Doc("visits")
+
+	attempts := 0
+	err := client.RunTransaction(ctx, func(ctx context.Context, tx TransactionInterface) error {
+		attempts++
+		var counter counterDoc
+		if snap, err := tx.Get(ref); err == nil {
+			snap.DataTo(&counter)
+		}
+		if attempts == 1 {
+			// A write outside the transaction invalidates what it read
+			ref.Set(ctx, counterDoc{N: 10})
+		}
+		counter.N++
+		return tx.Set(ref, counter)
+	})
+	if err != nil {
+		t.Fatalf("Transaction failed: %v", err)
+	}
+
+	snap, _ := ref.Get(ctx)
+	var counter counterDoc
+	snap.DataTo(&counter)
+	if attempts != 2 || counter.N != 11 {
+		t.Errorf("Expected a retry on fresh data, got %d attempts and n=%d", attempts, counter.N)
+	}
+}
+
+func TestLocalClient_TransactionIsAllOrNothing(t *testing.T) {
+	ctx := context.Background()
+	client := NewLocalClient()
+	coll := client.Collection("people")
+	coll.Doc("taken").Set(ctx, counterDoc{N: 1})
+
+	err := client.RunTransaction(ctx, func(ctx context.Context, tx TransactionInterface) error {
+		tx.Set(coll.Doc("fresh"), counterDoc{N: 2})
+		return tx.Create(coll.Doc("taken"), counterDoc{N: 3})
+	})
+	if err != ErrAlreadyExists {
+		t.Fatalf("Expected ErrAlreadyExists, got %v", err)
+	}
+	if _, err := coll.Doc("fresh").Get(ctx); err != ErrNotFound {
+		t.Errorf("Expected no writes from the failed transaction, got %v", err)
+	}
+}
@@ -0,0 +1,526 @@
+package firestore
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// The local store keeps documents as maps of normalized values, the same value
+// types Firestore supports: nil, bool, int64, float64, string, []byte,
+// time.Time, []interface{} and map[string]interface{}. Structs are converted
+// using Firestore's rules: the firestore tag names a field, "-" skips it,
+// "omitempty" drops zero values and untagged fields use the Go field name.
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// encodeDocument converts data (a struct, pointer to struct or map) to a document
+func encodeDocument(data interface{}) (map[string]interface{}, error) {
+	encoded, err := encodeValue(reflect.ValueOf(data))
+	if err != nil {
+		return nil, err
+	}
+	doc, ok := encoded.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("document data must be a struct or map, got %T", data)
+	}
+	return doc, nil
+}
+
+// encodeValue converts v to its normalized form
+func encodeValue(v reflect.Value) (interface{}, error) {
+	if !v.IsValid() {
+		return nil, nil
+	}
+	if v.Type() == timeType {
+		return v.Interface().(time.Time).UTC(), nil
+	}
+
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if v.IsNil() {
+			return nil, nil
+		}
+		return encodeValue(v.Elem())
+	case reflect.Bool:
+		return v.Bool(), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int(), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return int64(v.Uint()), nil
+	case reflect.Float32, reflect.Float64:
+		return v.Float(), nil
+	case reflect.String:
+		return v.String(), nil
+	case reflect.Slice:
+		if v.IsNil() {
+			return nil, nil
+		}
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			return append([]byte(nil), v.Bytes()...), nil
+		}
+		fallthrough
+	case reflect.Array:
+		items := make([]interface{}, v.Len())
+		for i := range items {
+			item, err := encodeValue(v.Index(i))
+			if err != nil {
+				return nil, err
+			}
+			items[i] = item
+		}
+		return items, nil
+	case reflect.Map:
+		if v.IsNil() {
+			return nil, nil
+		}
+		if v.Type().Key().Kind() != reflect.String {
+			return nil, fmt.Errorf("map keys must be strings, got %s", v.Type().Key())
+		}
+		m := make(map[string]interface{}, v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			item, err := encodeValue(iter.Value())
+			if err != nil {
+				return nil, err
+			}
+			m[iter.Key().String()] = item
+		}
+		return m, nil
+	case reflect.Struct:
+		m := make(map[string]interface{})
+		if err := encodeStruct(v, m); err != nil {
+			return nil, err
+		}
+		return m, nil
+	}
+	return nil, fmt.Errorf("unsupported type %s", v.Type())
+}
+
+// encodeStruct adds the exported fields of v to m; embedded structs are flattened
+func encodeStruct(v reflect.Value, m map[string]interface{}) error {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name, omitEmpty, ok := fieldName(field)
+		if !ok {
+			continue
+		}
+		fv := v.Field(i)
+		if field.Anonymous && field.Tag.Get("firestore") == "" && fv.Kind() == reflect.Struct {
+			if err := encodeStruct(fv, m); err != nil {
+				return err
+			}
+			continue
+		}
+		if omitEmpty && fv.IsZero() {
+			continue
+		}
+		encoded, err := encodeValue(fv)
+		if err != nil {
+			return fmt.Errorf("field %s: %w", field.Name, err)
+		}
+		m[name] = encoded
+	}
+	return nil
+}
+
+// fieldName returns the document field name for a struct field and whether it is stored
+func fieldName(field reflect.StructField) (name string, omitEmpty, ok bool) {
+	if field.PkgPath != "" && !field.Anonymous {
+		return "", false, false
+	}
+	tag := field.Tag.Get("firestore")
+	if tag == "-" {
+		return "", false, false
+	}
+	name, opts, _ := strings.Cut(tag, ",")
+	if name == "" {
+		name = field.Name
+	}
+	return name, strings.Contains(","+opts+",", ",omitempty,"), true
+}
+
+// decodeDocument populates dest, a pointer, from a stored document
+func decodeDocument(doc map[string]interface{}, dest interface{}) error {
+	v := reflect.ValueOf(dest)
+	if v.Kind() != reflect.Ptr || v.IsNil() {
+		return fmt.Errorf("dest must be a non-nil pointer")
+	}
+	return decodeValue(v.Elem(), doc)
+}
+
+// decodeValue sets dst from the normalized value src
+func decodeValue(dst reflect.Value, src interface{}) error {
+	if src == nil {
+		dst.Set(reflect.Zero(dst.Type()))
+		return nil
+	}
+	if dst.Type() == timeType {
+		t, ok := src.(time.Time)
+		if !ok {
+			return typeMismatch(dst, src)
+		}
+		dst.Set(reflect.ValueOf(t))
+		return nil
+	}
+
+	switch dst.Kind() {
+	case reflect.Ptr:
+		elem := reflect.New(dst.Type().Elem())
+		if err := decodeValue(elem.Elem(), src); err != nil {
+			return err
+		}
+		dst.Set(elem)
+		return nil
+	case reflect.Interface:
+		copied := copyValue(src)
+		if !reflect.TypeOf(copied).AssignableTo(dst.Type()) {
+			return typeMismatch(dst, src)
+		}
+		dst.Set(reflect.ValueOf(copied))
+		return nil
+	case reflect.Bool:
+		b, ok := src.(bool)
+		if !ok {
+			return typeMismatch(dst, src)
+		}
+		dst.SetBool(b)
+		return nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		switch n := src.(type) {
+		case int64:
+			dst.SetInt(n)
+		case float64:
+			if n != float64(int64(n)) {
+				return typeMismatch(dst, src)
+			}
+			dst.SetInt(int64(n))
+		default:
+			return typeMismatch(dst, src)
+		}
+		return nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, ok := src.(int64)
+		if !ok || n < 0 {
+			return typeMismatch(dst, src)
+		}
+		dst.SetUint(uint64(n))
+		return nil
+	case reflect.Float32, reflect.Float64:
+		switch n := src.(type) {
+		case int64:
+			dst.SetFloat(float64(n))
+		case float64:
+			dst.SetFloat(n)
+		default:
+			return typeMismatch(dst, src)
+		}
+		return nil
+	case reflect.String:
+		s, ok := src.(string)
+		if !ok {
+			return typeMismatch(dst, src)
+		}
+		dst.SetString(s)
+		return nil
+	case reflect.Slice:
+		if b, ok := src.([]byte); ok && dst.Type().Elem().Kind() == reflect.Uint8 {
+			dst.SetBytes(append([]byte(nil), b...))
+			return nil
+		}
+		items, ok := src.([]interface{})
+		if !ok {
+			return typeMismatch(dst, src)
+		}
+		slice := reflect.MakeSlice(dst.Type(), len(items), len(items))
+		for i, item := range items {
+			if err := decodeValue(slice.Index(i), item); err != nil {
+				return err
+			}
+		}
+		dst.Set(slice)
+		return nil
+	case reflect.Array:
+		items, ok := src.([]interface{})
+		if !ok {
+			return typeMismatch(dst, src)
+		}
+		for i := 0; i < dst.Len() && i < len(items); i++ {
+			if err := decodeValue(dst.Index(i), items[i]); err != nil {
+				return err
+			}
+		}
+		return nil
+	case reflect.Map:
+		m, ok := src.(map[string]interface{})
+		if !ok || dst.Type().Key().Kind() != reflect.String {
+			return typeMismatch(dst, src)
+		}
+		out := reflect.MakeMapWithSize(dst.Type(), len(m))
+		for k, item := range m {
+			elem := reflect.New(dst.Type().Elem()).Elem()
+			if err := decodeValue(elem, item); err != nil {
+				return err
+			}
+			out.SetMapIndex(reflect.ValueOf(k).Convert(dst.Type().Key()), elem)
+		}
+		dst.Set(out)
+		return nil
+	case reflect.Struct:
+		m, ok := src.(map[string]interface{})
+		if !ok {
+			return typeMismatch(dst, src)
+		}
+		return decodeStruct(dst, m)
+	}
+	return typeMismatch(dst, src)
+}
+
+// decodeStruct sets the fields of dst present in m; other fields are left unchanged
+func decodeStruct(dst reflect.Value, m map[string]interface{}) error {
+	t := dst.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name, _, ok := fieldName(field)
+		if !ok {
+			continue
+		}
+		fv := dst.Field(i)
+		if field.Anonymous && field.Tag.Get("firestore") == "" && fv.Kind() == reflect.Struct {
+			if err := decodeStruct(fv, m); err != nil {
+				return err
+			}
+			continue
+		}
+		value, present := m[name]
+		if !present {
+			continue
+		}
+		if err := decodeValue(fv, value); err != nil {
+			return fmt.Errorf("field %s: %w", field.Name, err)
+		}
+	}
+	return nil
+}
+
+func typeMismatch(dst reflect.Value, src interface{}) error {
+	return fmt.Errorf("cannot decode %T into %s", src, dst.Type())
+}
+
+// copyValue deep-copies a normalized value so callers cannot alias stored documents
+func copyValue(v interface{}) interface{} {
+	switch val := v.(type) {
+	case map[string]interface{}:
+		m := make(map[string]interface{}, len(val))
+		for k, item := range val {
+			m[k] = copyValue(item)
+		}
+		return m
+	case []interface{}:
+		items := make([]interface{}, len(val))
+		for i, item := range val {
+			items[i] = copyValue(item)
+		}
+		return items
+	case []byte:
+		return append([]byte(nil), val...)
+	}
+	return v
+}
+
+// Value type classes in Firestore's cross-type ordering
+const (
+	classNull = iota
+	classBool
+	classNumber
+	classTime
+	classString
+	classBytes
+	classArray
+	classMap
+)
+
+func valueClass(v interface{}) int {
+	switch v.(type) {
+	case nil:
+		return classNull
+	case bool:
+		return classBool
+	case int64, float64:
+		return classNumber
+	case time.Time:
+		return classTime
+	case string:
+		return classString
+	case []byte:
+		return classBytes
+	case []interface{}:
+		return classArray
+	}
+	return classMap
+}
+
+// compareValues orders two normalized values the way Firestore does, first by
+// type class and then by value
+func compareValues(a, b interface{}) int {
+	ca, cb := valueClass(a), valueClass(b)
+	if ca != cb {
+		return compareInts(ca, cb)
+	}
+
+	switch ca {
+	case classBool:
+		x, y := a.(bool), b.(bool)
+		if x == y {
+			return 0
+		}
+		if !x {
+			return -1
+		}
+		return 1
+	case classNumber:
+		x, y := toFloat(a), toFloat(b)
+		if x < y {
+			return -1
+		}
+		if x > y {
+			return 1
+		}
+		return 0
+	case classTime:
+		return a.(time.Time).Compare(b.(time.Time))
+	case classString:
+		return strings.Compare(a.(string), b.(string))
+	case classBytes:
+		return strings.Compare(string(a.([]byte)), string(b.([]byte)))
+	case classArray:
+		x, y := a.([]interface{}), b.([]interface{})
+		for i := 0; i < len(x) && i < len(y); i++ {
+			if c := compareValues(x[i], y[i]); c != 0 {
+				return c
+			}
+		}
+		return compareInts(len(x), len(y))
+	case classMap:
+		x, y := a.(map[string]interface{}), b.(map[string]interface{})
+		if len(x) != len(y) {
+			return compareInts(len(x), len(y))
+		}
+		for k, v := range x {
+			w, ok := y[k]
+			if !ok {
+				return 1
+			}
+			if c := compareValues(v, w); c != 0 {
+				return c
+			}
+		}
+	}
+	return 0
+}
+
+func compareInts(a, b int) int {
+	if a < b {
+		return -1
+	}
+	if a > b {
+		return 1
+	}
+	return 0
+}
+
+func toFloat(v interface{}) float64 {
+	if n, ok := v.(int64); ok {
+		return float64(n)
+	}
+	return v.(float64)
+}
+
+// lookupField resolves a dotted field path within a document
+func lookupField(doc map[string]interface{}, path string) (interface{}, bool) {
+	var current interface{} = doc
+	for _, part := range strings.Split(path, ".") {
+		m, ok := current.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		if current, ok = m[part]; !ok {
+			return nil, false
+		}
+	}
+	return current, true
+}
+
+// Snapshot files are JSON. Values without a native JSON form are wrapped in
+// single-key objects so they survive a round trip.
+const (
+	jsonTimeKey  = "$time"
+	jsonBytesKey = "$bytes"
+)
+
+// toJSONValue converts a normalized value to its snapshot file form
+func toJSONValue(v interface{}) interface{} {
+	switch val := v.(type) {
+	case time.Time:
+		return map[string]interface{}{jsonTimeKey: val.Format(time.RFC3339Nano)}
+	case []byte:
+		return map[string]interface{}{jsonBytesKey: base64.StdEncoding.EncodeToString(val)}
+	case []interface{}:
+		items := make([]interface{}, len(val))
+		for i, item := range val {
+			items[i] = toJSONValue(item)
+		}
+		return items
+	case map[string]interface{}:
+		m := make(map[string]interface{}, len(val))
+		for k, item := range val {
+			m[k] = toJSONValue(item)
+		}
+		return m
+	}
+	return v
+}
+
+// fromJSONValue converts a value decoded with json.Decoder.UseNumber back to normalized form
+func fromJSONValue(v interface{}) (interface{}, error) {
+	switch val := v.(type) {
+	case json.Number:
+		if n, err := val.Int64(); err == nil {
+			return n, nil
+		}
+		return val.Float64()
+	case []interface{}:
+		items := make([]interface{}, len(val))
+		for i, item := range val {
+			decoded, err := fromJSONValue(item)
+			if err != nil {
+				return nil, err
+			}
+			items[i] = decoded
+		}
+		return items, nil
+	case map[string]interface{}:
+		if len(val) == 1 {
+			if s, ok := val[jsonTimeKey].(string); ok {
+				return time.Parse(time.RFC3339Nano, s)
+			}
+			if s, ok := val[jsonBytesKey].(string); ok {
+				return base64.StdEncoding.DecodeString(s)
+			}
+		}
+		m := make(map[string]interface{}, len(val))
+		for k, item := range val {
+			decoded, err := fromJSONValue(item)
+			if err != nil {
+				return nil, err
+			}
+			m[k] = decoded
+		}
+		return m, nil
+	}
+	return v, nil
+}
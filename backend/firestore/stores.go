@@ -0,0 +1,840 @@
+package firestore
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"time"
+
+	"event-registration-backend/models"
+	"event-registration-backend/store"
+)
+
+// NewStores returns the Firestore-backed repositories for the current tenant
+func NewStores() store.Stores {
+	return store.Stores{
+		Attendees: &AttendeeStore{},
+		Sessions:  &SessionStore{},
+		Speakers:  &SpeakerStore{},
+		Fields:    &FieldStore{},
+	}
+}
+
+// AttendeeStore implements store.AttendeeStore on the tenant's attendees collection
+type AttendeeStore struct{}
+
+func (s *AttendeeStore) Create(ctx context.Context, attendee *models.Attendee, capacity int) error {
+	coll := GetAttendeesCollection()
+	id := store.AttendeeID(attendee.Email)
+	err := runAttendeeTransaction(ctx, func(ctx context.Context, tx TransactionInterface, counts *attendeeCounts) error {
+		replacing, err := checkAvailable(tx, coll, id)
+		if err != nil {
+			return err
+		}
+		store.Seat(attendee, counts.Confirmed, counts.Waitlisted, capacity)
+		counts.track(*attendee, 1)
+		if replacing {
+			return tx.Set(coll.Doc(id), attendee)
+		}
+		return tx.Create(coll.Doc(id), attendee)
+	})
+	if err == ErrAlreadyExists {
+		return store.ErrAlreadyExists
+	}
+	if err != nil {
+		return err
+	}
+	attendee.ID = id
+	return nil
+}
+
+func (s *AttendeeStore) CheckAvailable(ctx context.Context, email string) error {
+	coll := GetAttendeesCollection()
+	return runAttendeeTransaction(ctx, func(ctx context.Context, tx TransactionInterface, counts *attendeeCounts) error {
+		_, err := checkAvailable(tx, coll, store.AttendeeID(email))
+		return err
+	})
+}
+
+func (s *AttendeeStore) Get(ctx context.Context, id string) (*models.Attendee, error) {
+	var attendee models.Attendee
+	if err := getDoc(ctx, GetAttendeesCollection(), id, &attendee); err != nil {
+		return nil, err
+	}
+	attendee.ID = id
+	defaultStatus(&attendee)
+	return &attendee, nil
+}
+
+func (s *AttendeeStore) List(ctx context.Context) ([]models.Attendee, error) {
+	docs, err := GetAttendeesCollection().Documents(ctx).GetAll()
+	if err != nil {
+		return nil, err
+	}
+	return decodeAttendees(docs), nil
+}
+
+func (s *AttendeeStore) Each(ctx context.Context, fn func(models.Attendee) error) error {
+	iter := GetAttendeesCollection().OrderBy("registeredAt", Asc).Documents(ctx)
+	defer iter.Stop()
+	for {
+		doc, err := iter.Next()
+		if err == Done {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		var attendee models.Attendee
+		if err := doc.DataTo(&attendee); err != nil {
+			continue
+		}
+		attendee.ID = doc.GetID()
+		defaultStatus(&attendee)
+		if err := fn(attendee); err != nil {
+			return err
+		}
+	}
+}
+
+// CountByStatus uses count aggregations, which Firestore bills per 1,000
+// index entries rather than per document. Registrations from before statuses
+// existed have none and count as confirmed, so confirmed is what remains of
+// the total.
+func (s *AttendeeStore) CountByStatus(ctx context.Context) (map[string]int, error) {
+	coll := GetAttendeesCollection()
+	total, err := coll.Count(ctx)
+	if err != nil {
+		return nil, err
+	}
+	counts := make(map[string]int)
+	confirmed := total
+	for _, status := range []string{models.AttendeeWaitlisted, models.AttendeeCancelled, models.AttendeePending} {
+		n, err := coll.Where("status", "==", status).Count(ctx)
+		if err != nil {
+			return nil, err
+		}
+		counts[status] = n
+		confirmed -= n
+	}
+	counts[models.AttendeeConfirmed] = confirmed
+	return counts, nil
+}
+
+// CountByDesignation reads the designation counts kept with the seat counts,
+// since Firestore aggregations cannot group by a field. Those only cover
+// confirmed and waitlisted attendees; other statuses read the matching
+// registrations.
+func (s *AttendeeStore) CountByDesignation(ctx context.Context, status string) (map[string]int, error) {
+	if status != models.AttendeeConfirmed && status != models.AttendeeWaitlisted {
+		return countDesignations(ctx, status)
+	}
+	counts := make(map[string]int)
+	err := runAttendeeTransaction(ctx, func(ctx context.Context, tx TransactionInterface, c *attendeeCounts) error {
+		for _, count := range c.Designations {
+			if count.Status == status {
+				counts[count.Designation] = count.Count
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return counts, nil
+}
+
+// countDesignations counts the attendees with status by designation, reading
+// each of them. Registrations without a status are confirmed, so status must
+// not be confirmed.
+func countDesignations(ctx context.Context, status string) (map[string]int, error) {
+	iter := GetAttendeesCollection().Where("status", "==", status).Documents(ctx)
+	defer iter.Stop()
+	counts := make(map[string]int)
+	for {
+		doc, err := iter.Next()
+		if err == Done {
+			return counts, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		var attendee models.Attendee
+		if err := doc.DataTo(&attendee); err != nil {
+			continue
+		}
+		counts[attendee.Designation]++
+	}
+}
+
+// CountByField runs a count aggregation per option. Registrations without a
+// status predate registration fields, so filtering on the status misses none.
+func (s *AttendeeStore) CountByField(ctx context.Context, status, id string, options []string) (map[string]int, error) {
+	query := GetAttendeesCollection().Where("status", "==", status)
+	counts := make(map[string]int, len(options))
+	for _, option := range options {
+		n, err := query.Where("fields."+id, "==", option).Count(ctx)
+		if err != nil {
+			return nil, err
+		}
+		counts[option] = n
+	}
+	return counts, nil
+}
+
+// Search evaluates only the registration range in the query, and only when
+// sorting by registration time, since Firestore has no substring search and a
+// range on another field would need a composite index. The rest of the filter
+// is applied to the documents as they stream in sort order, stopping once the
+// page is full.
+func (s *AttendeeStore) Search(ctx context.Context, q store.AttendeeQuery) ([]models.Attendee, error) {
+	sortField := q.Sort
+	if sortField == "" {
+		sortField = store.SortRegisteredAt
+	}
+	dir := Asc
+	if q.Descending {
+		dir = Desc
+	}
+
+	query := GetAttendeesCollection()
+	if sortField == store.SortRegisteredAt {
+		if !q.RegisteredFrom.IsZero() {
+			query = query.Where("registeredAt", ">=", q.RegisteredFrom)
+		}
+		if !q.RegisteredTo.IsZero() {
+			query = query.Where("registeredAt", "<", q.RegisteredTo)
+		}
+	}
+	query = query.OrderBy(sortField, dir).OrderBy(DocumentID, dir)
+	if q.After != nil {
+		query = query.StartAfter(q.After.Key, q.After.ID)
+	}
+
+	iter := query.Documents(ctx)
+	defer iter.Stop()
+	var page []models.Attendee
+	for q.Limit == 0 || len(page) < q.Limit {
+		doc, err := iter.Next()
+		if err == Done {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		var attendee models.Attendee
+		if err := doc.DataTo(&attendee); err != nil {
+			continue
+		}
+		attendee.ID = doc.GetID()
+		defaultStatus(&attendee)
+		if q.Match(attendee) {
+			page = append(page, attendee)
+		}
+	}
+	return page, nil
+}
+
+func (s *AttendeeStore) UpdateDetails(ctx context.Context, id, fullName, designation string) (*models.Attendee, error) {
+	ref := GetAttendeesCollection().Doc(id)
+	var updated models.Attendee
+	err := runAttendeeTransaction(ctx, func(ctx context.Context, tx TransactionInterface, counts *attendeeCounts) error {
+		attendee, err := readAttendee(tx, ref)
+		if err == ErrNotFound || (err == nil && attendee.Status == models.AttendeeCancelled) {
+			return store.ErrNotFound
+		}
+		if err != nil {
+			return err
+		}
+		counts.track(*attendee, -1)
+		attendee.FullName = fullName
+		attendee.Designation = designation
+		counts.track(*attendee, 1)
+		updated = *attendee
+		return tx.Set(ref, attendee)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &updated, nil
+}
+
+// Cancel reads the waitlist only: the next waitlisted attendee is found with
+// a single-document query for each free seat and only those behind a vacated
+// position are renumbered.
+func (s *AttendeeStore) Cancel(ctx context.Context, id string, capacity int) (*models.Attendee, []models.Attendee, error) {
+	coll := GetAttendeesCollection()
+	var cancelled models.Attendee
+	var promoted []models.Attendee
+	err := runAttendeeTransaction(ctx, func(ctx context.Context, tx TransactionInterface, counts *attendeeCounts) error {
+		attendee, err := readAttendee(tx, coll.Doc(id))
+		if err == ErrNotFound || (err == nil && attendee.Status == models.AttendeeCancelled) {
+			return store.ErrNotFound
+		}
+		if err != nil {
+			return err
+		}
+
+		counts.track(*attendee, -1)
+		var vacated []int
+		if position := store.Cancel(attendee, time.Now()); position > 0 {
+			vacated = append(vacated, position)
+		}
+		cancelled, promoted = *attendee, nil
+		after := 0
+		for counts.Waitlisted > 0 && store.HasSeat(counts.Confirmed, capacity) {
+			next, err := nextWaitlisted(tx, coll, after)
+			if err == ErrNotFound {
+				break
+			}
+			if err != nil {
+				return err
+			}
+			after = next.WaitlistPosition
+			if next.ID == id {
+				continue
+			}
+			counts.track(*next, -1)
+			vacated = append(vacated, store.Promote(next))
+			counts.track(*next, 1)
+			promoted = append(promoted, *next)
+		}
+
+		var renumbered []models.Attendee
+		if len(vacated) > 0 {
+			first := vacated[0]
+			for _, position := range vacated {
+				if position < first {
+					first = position
+				}
+			}
+			docs, err := tx.Documents(coll.Where("waitlistPosition", ">", first).OrderBy("waitlistPosition", Asc))
+			if err != nil {
+				return err
+			}
+			renumbered = store.Renumber(decodeAttendees(docs), vacated)
+		}
+
+		if err := tx.Set(coll.Doc(id), attendee); err != nil {
+			return err
+		}
+		if err := releaseEmail(tx, *attendee); err != nil {
+			return err
+		}
+		for _, changed := range append(append([]models.Attendee{}, promoted...), renumbered...) {
+			if err := tx.Set(coll.Doc(changed.ID), &changed); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return &cancelled, promoted, nil
+}
+
+func (s *AttendeeStore) Verify(ctx context.Context, id, manageTokenHash string, capacity int) (*models.Attendee, bool, error) {
+	ref := GetAttendeesCollection().Doc(id)
+	var verified models.Attendee
+	var changed bool
+	err := runAttendeeTransaction(ctx, func(ctx context.Context, tx TransactionInterface, counts *attendeeCounts) error {
+		attendee, err := readAttendee(tx, ref)
+		if err == ErrNotFound || (err == nil && attendee.Status == models.AttendeeCancelled) {
+			return store.ErrNotFound
+		}
+		if err != nil {
+			return err
+		}
+		changed = store.Verify(attendee, counts.Confirmed, counts.Waitlisted, capacity)
+		verified = *attendee
+		if !changed {
+			return nil
+		}
+		counts.track(*attendee, 1)
+		attendee.ManageTokenHash = manageTokenHash
+		verified.ManageTokenHash = manageTokenHash
+		return tx.Set(ref, attendee)
+	})
+	if err != nil {
+		return nil, false, err
+	}
+	return &verified, changed, nil
+}
+
+func (s *AttendeeStore) MarkVerificationSent(ctx context.Context, id string, at time.Time, cooldown time.Duration) (bool, error) {
+	ref := GetAttendeesCollection().Doc(id)
+	var sent bool
+	err := RunTransaction(ctx, func(ctx context.Context, tx TransactionInterface) error {
+		attendee, err := readAttendee(tx, ref)
+		if err == ErrNotFound || (err == nil && attendee.Status != models.AttendeePending) {
+			return store.ErrNotFound
+		}
+		if err != nil {
+			return err
+		}
+		sent = attendee.VerificationSentAt == nil || !attendee.VerificationSentAt.After(at.Add(-cooldown))
+		if !sent {
+			return nil
+		}
+		attendee.VerificationSentAt = &at
+		return tx.Set(ref, attendee)
+	})
+	if err != nil {
+		return false, err
+	}
+	return sent, nil
+}
+
+// PurgePending queries pending registrations only; filtering them by
+// registration time in the query would need a composite index. Pending
+// attendees hold no seat, so the counts are left alone.
+func (s *AttendeeStore) PurgePending(ctx context.Context, cutoff time.Time) (int, error) {
+	coll := GetAttendeesCollection()
+	var purged int
+	err := RunTransaction(ctx, func(ctx context.Context, tx TransactionInterface) error {
+		docs, err := tx.Documents(coll.Where("status", "==", models.AttendeePending))
+		if err != nil {
+			return err
+		}
+		expired := store.Expired(decodeAttendees(docs), cutoff)
+		for _, attendee := range expired {
+			if err := tx.Delete(coll.Doc(attendee.ID)); err != nil {
+				return err
+			}
+			if err := releaseEmail(tx, attendee); err != nil {
+				return err
+			}
+		}
+		purged = len(expired)
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return purged, nil
+}
+
+func (s *AttendeeStore) CheckIn(ctx context.Context, id string, at time.Time) (*models.Attendee, error) {
+	ref := GetAttendeesCollection().Doc(id)
+	var checkedIn models.Attendee
+	err := runAttendeeTransaction(ctx, func(ctx context.Context, tx TransactionInterface, counts *attendeeCounts) error {
+		attendee, err := readAttendee(tx, ref)
+		if err == ErrNotFound || (err == nil && attendee.Status != models.AttendeeConfirmed) {
+			return store.ErrNotFound
+		}
+		if err != nil {
+			return err
+		}
+		checkedIn = *attendee
+		if attendee.CheckedInAt != nil {
+			return store.ErrAlreadyCheckedIn
+		}
+		counts.track(*attendee, -1)
+		attendee.CheckedInAt = &at
+		counts.track(*attendee, 1)
+		checkedIn = *attendee
+		return tx.Set(ref, attendee)
+	})
+	if err == store.ErrAlreadyCheckedIn {
+		return &checkedIn, err
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &checkedIn, nil
+}
+
+// CheckInStats reads the check-in counts kept with the seat counts
+func (s *AttendeeStore) CheckInStats(ctx context.Context) (*models.CheckInStats, error) {
+	stats := models.CheckInStats{ByDesignation: make(map[string]int)}
+	err := runAttendeeTransaction(ctx, func(ctx context.Context, tx TransactionInterface, counts *attendeeCounts) error {
+		stats.Confirmed = counts.Confirmed
+		for _, count := range counts.CheckedIn {
+			stats.ByDesignation[count.Designation] = count.Count
+			stats.CheckedIn += count.Count
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	stats.Remaining = stats.Confirmed - stats.CheckedIn
+	return &stats, nil
+}
+
+// attendeeCountsDoc is the settings document holding the tenant's seat counts
+const attendeeCountsDoc = "attendeeCounts"
+
+// errNoAttendeeCounts is returned inside runAttendeeTransaction when the
+// tenant's seat counts have not been built yet
+var errNoAttendeeCounts = errors.New("attendee counts not initialized")
+
+// attendeeCountsVersion is stored with the counts; counts built by an older
+// version lack some of them and are rebuilt
+const attendeeCountsVersion = 2
+
+// attendeeCounts tracks how many attendees hold or wait for a seat, in total
+// and per designation, and how many have checked in, so that registering,
+// cancelling and the stats do not read every registration
+type attendeeCounts struct {
+	Version    int `firestore:"version"`
+	Confirmed  int `firestore:"confirmed"`
+	Waitlisted int `firestore:"waitlisted"`
+	// Designations counts the confirmed and waitlisted attendees per status
+	// and designation
+	Designations []designationCount `firestore:"designations,omitempty"`
+	// CheckedIn counts the confirmed attendees checked in per designation
+	CheckedIn []designationCount `firestore:"checkedInDesignations,omitempty"`
+}
+
+// designationCount counts the attendees with a status and designation.
+// Designations are free text, so they are stored as values rather than as
+// map keys, which Firestore restricts.
+type designationCount struct {
+	Status      string `firestore:"status"`
+	Designation string `firestore:"designation"`
+	Count       int    `firestore:"count"`
+}
+
+// clone returns a copy of c that shares no slices with it
+func (c attendeeCounts) clone() attendeeCounts {
+	c.Designations = append([]designationCount(nil), c.Designations...)
+	c.CheckedIn = append([]designationCount(nil), c.CheckedIn...)
+	return c
+}
+
+// addDesignation adds n to the count of status and designation in counts,
+// dropping counts that reach zero
+func addDesignation(counts []designationCount, status, designation string, n int) []designationCount {
+	for i := range counts {
+		if counts[i].Status == status && counts[i].Designation == designation {
+			counts[i].Count += n
+			if counts[i].Count == 0 {
+				counts = append(counts[:i], counts[i+1:]...)
+			}
+			return counts
+		}
+	}
+	return append(counts, designationCount{Status: status, Designation: designation, Count: n})
+}
+
+// add adds n to the count for status; other statuses are not counted
+func (c *attendeeCounts) add(status string, n int) {
+	switch status {
+	case models.AttendeeConfirmed:
+		c.Confirmed += n
+	case models.AttendeeWaitlisted:
+		c.Waitlisted += n
+	}
+}
+
+// track adds n to the counts attendee falls in. Callers untrack an attendee
+// with n = -1 before changing it and track it again afterwards.
+func (c *attendeeCounts) track(attendee models.Attendee, n int) {
+	switch attendee.Status {
+	case models.AttendeeConfirmed, models.AttendeeWaitlisted:
+	default:
+		return
+	}
+	c.add(attendee.Status, n)
+	c.Designations = addDesignation(c.Designations, attendee.Status, attendee.Designation, n)
+	if attendee.Status == models.AttendeeConfirmed && attendee.CheckedInAt != nil {
+		c.CheckedIn = addDesignation(c.CheckedIn, attendee.Status, attendee.Designation, n)
+	}
+}
+
+// emailClaim reserves an address for a registration stored under a random ID
+type emailClaim struct {
+	AttendeeID string `firestore:"attendeeId"`
+}
+
+// runAttendeeTransaction runs fn in a transaction with the tenant's seat
+// counts and saves any change fn makes to them. The counts are built from the
+// registrations the first time they are needed, and again when they were
+// built by an older attendeeCountsVersion.
+func runAttendeeTransaction(ctx context.Context, fn func(ctx context.Context, tx TransactionInterface, counts *attendeeCounts) error) error {
+	err := runWithCounts(ctx, fn)
+	if err != errNoAttendeeCounts {
+		return err
+	}
+	if err := initAttendeeCounts(ctx); err != nil {
+		return err
+	}
+	return runWithCounts(ctx, fn)
+}
+
+// runWithCounts runs fn like runAttendeeTransaction, returning
+// errNoAttendeeCounts if the counts do not exist
+func runWithCounts(ctx context.Context, fn func(ctx context.Context, tx TransactionInterface, counts *attendeeCounts) error) error {
+	ref := GetSettingsCollection().Doc(attendeeCountsDoc)
+	return RunTransaction(ctx, func(ctx context.Context, tx TransactionInterface) error {
+		doc, err := tx.Get(ref)
+		if err == ErrNotFound {
+			return errNoAttendeeCounts
+		}
+		if err != nil {
+			return err
+		}
+		var counts attendeeCounts
+		if err := doc.DataTo(&counts); err != nil {
+			return err
+		}
+		if counts.Version != attendeeCountsVersion {
+			return errNoAttendeeCounts
+		}
+		before := counts.clone()
+		if err := fn(ctx, tx, &counts); err != nil {
+			return err
+		}
+		if reflect.DeepEqual(counts, before) {
+			return nil
+		}
+		return tx.Set(ref, &counts)
+	})
+}
+
+// initAttendeeCounts builds the counts from every registration and claims
+// the addresses of registrations stored under random IDs. It reads the whole
+// collection, once per tenant and attendeeCountsVersion.
+func initAttendeeCounts(ctx context.Context) error {
+	ref := GetSettingsCollection().Doc(attendeeCountsDoc)
+	return RunTransaction(ctx, func(ctx context.Context, tx TransactionInterface) error {
+		doc, err := tx.Get(ref)
+		if err != nil && err != ErrNotFound {
+			return err
+		}
+		if err == nil {
+			var existing attendeeCounts
+			if err := doc.DataTo(&existing); err != nil {
+				return err
+			}
+			if existing.Version == attendeeCountsVersion {
+				return nil
+			}
+		}
+		docs, err := tx.Documents(GetAttendeesCollection())
+		if err != nil {
+			return err
+		}
+		attendees := decodeAttendees(docs)
+		for _, attendee := range attendees {
+			id := store.AttendeeID(attendee.Email)
+			if attendee.ID == id || attendee.Status == models.AttendeeCancelled {
+				continue
+			}
+			if err := tx.Set(GetAttendeeEmailsCollection().Doc(id), emailClaim{AttendeeID: attendee.ID}); err != nil {
+				return err
+			}
+		}
+		counts := attendeeCounts{Version: attendeeCountsVersion}
+		for _, attendee := range attendees {
+			counts.track(attendee, 1)
+		}
+		return tx.Set(ref, &counts)
+	})
+}
+
+// checkAvailable returns store.ErrAlreadyExists if an active registration
+// holds the address with id, and reports whether a cancelled one is stored
+// under id
+func checkAvailable(tx TransactionInterface, coll CollectionRefInterface, id string) (replacing bool, err error) {
+	existing, err := readAttendee(tx, coll.Doc(id))
+	if err != nil && err != ErrNotFound {
+		return false, err
+	}
+	replacing = err == nil
+	if replacing && existing.Status != models.AttendeeCancelled {
+		return false, store.ErrAlreadyExists
+	}
+	// Attendees registered before IDs were derived from the email have
+	// random IDs, so their address is claimed separately
+	if _, err := tx.Get(GetAttendeeEmailsCollection().Doc(id)); err != ErrNotFound {
+		if err == nil {
+			return false, store.ErrAlreadyExists
+		}
+		return false, err
+	}
+	return replacing, nil
+}
+
+// releaseEmail frees the address of a registration stored under a random ID
+func releaseEmail(tx TransactionInterface, attendee models.Attendee) error {
+	id := store.AttendeeID(attendee.Email)
+	if attendee.ID == id {
+		return nil
+	}
+	return tx.Delete(GetAttendeeEmailsCollection().Doc(id))
+}
+
+// readAttendee reads the attendee at ref within tx, or returns ErrNotFound
+func readAttendee(tx TransactionInterface, ref DocumentRefInterface) (*models.Attendee, error) {
+	doc, err := tx.Get(ref)
+	if err != nil {
+		return nil, err
+	}
+	var attendee models.Attendee
+	if err := doc.DataTo(&attendee); err != nil {
+		return nil, err
+	}
+	attendee.ID = ref.GetID()
+	defaultStatus(&attendee)
+	return &attendee, nil
+}
+
+// nextWaitlisted reads the first waitlisted attendee behind position after,
+// or returns ErrNotFound. Only waitlisted attendees store a position, so the
+// query needs no status filter nor the composite index that would require.
+func nextWaitlisted(tx TransactionInterface, coll CollectionRefInterface, after int) (*models.Attendee, error) {
+	docs, err := tx.Documents(coll.Where("waitlistPosition", ">", after).OrderBy("waitlistPosition", Asc).Limit(1))
+	if err != nil {
+		return nil, err
+	}
+	attendees := decodeAttendees(docs)
+	if len(attendees) == 0 {
+		return nil, ErrNotFound
+	}
+	return &attendees[0], nil
+}
+
+// decodeAttendees converts attendee documents, skipping any that do not decode
+func decodeAttendees(docs []DocumentSnapshotInterface) []models.Attendee {
+	var attendees []models.Attendee
+	for _, doc := range docs {
+		var attendee models.Attendee
+		if err := doc.DataTo(&attendee); err != nil {
+			continue
+		}
+		attendee.ID = doc.GetID()
+		defaultStatus(&attendee)
+		attendees = append(attendees, attendee)
+	}
+	return attendees
+}
+
+// defaultStatus confirms attendees stored before registrations had a status
+func defaultStatus(attendee *models.Attendee) {
+	if attendee.Status == "" {
+		attendee.Status = models.AttendeeConfirmed
+	}
+}
+
+// SessionStore implements store.SessionStore on the tenant's sessions collection
+type SessionStore struct{}
+
+func (s *SessionStore) Get(ctx context.Context, id string) (*models.Session, error) {
+	var session models.Session
+	if err := getDoc(ctx, GetSessionsCollection(), id, &session); err != nil {
+		return nil, err
+	}
+	session.ID = id
+	return &session, nil
+}
+
+func (s *SessionStore) List(ctx context.Context) ([]models.Session, error) {
+	docs, err := GetSessionsCollection().Documents(ctx).GetAll()
+	if err != nil {
+		return nil, err
+	}
+	var sessions []models.Session
+	for _, doc := range docs {
+		var session models.Session
+		if err := doc.DataTo(&session); err != nil {
+			continue
+		}
+		session.ID = doc.GetID()
+		sessions = append(sessions, session)
+	}
+	return sessions, nil
+}
+
+func (s *SessionStore) Create(ctx context.Context, session *models.Session) error {
+	docRef, _, err := GetSessionsCollection().Add(ctx, session)
+	if err != nil {
+		return err
+	}
+	session.ID = docRef.GetID()
+	return nil
+}
+
+func (s *SessionStore) Save(ctx context.Context, session *models.Session) error {
+	_, err := GetSessionsCollection().Doc(session.ID).Set(ctx, session)
+	return err
+}
+
+// SpeakerStore implements store.SpeakerStore on the tenant's speakers collection
+type SpeakerStore struct{}
+
+func (s *SpeakerStore) Get(ctx context.Context, id string) (*models.Speaker, error) {
+	var speaker models.Speaker
+	if err := getDoc(ctx, GetSpeakersCollection(), id, &speaker); err != nil {
+		return nil, err
+	}
+	speaker.ID = id
+	return &speaker, nil
+}
+
+func (s *SpeakerStore) List(ctx context.Context) ([]models.Speaker, error) {
+	docs, err := GetSpeakersCollection().Documents(ctx).GetAll()
+	if err != nil {
+		return nil, err
+	}
+	var speakers []models.Speaker
+	for _, doc := range docs {
+		var speaker models.Speaker
+		if err := doc.DataTo(&speaker); err != nil {
+			continue
+		}
+		speaker.ID = doc.GetID()
+		speakers = append(speakers, speaker)
+	}
+	return speakers, nil
+}
+
+func (s *SpeakerStore) Create(ctx context.Context, speaker *models.Speaker) error {
+	docRef, _, err := GetSpeakersCollection().Add(ctx, speaker)
+	if err != nil {
+		return err
+	}
+	speaker.ID = docRef.GetID()
+	return nil
+}
+
+func (s *SpeakerStore) Save(ctx context.Context, speaker *models.Speaker) error {
+	_, err := GetSpeakersCollection().Doc(speaker.ID).Set(ctx, speaker)
+	return err
+}
+
+// registrationFormDoc is the settings document holding the registration form
+const registrationFormDoc = "registrationForm"
+
+// FieldStore implements store.FieldStore as one settings document, so the
+// form is read and replaced as a whole
+type FieldStore struct{}
+
+func (s *FieldStore) List(ctx context.Context) ([]models.RegistrationField, error) {
+	var form models.RegistrationForm
+	err := getDoc(ctx, GetSettingsCollection(), registrationFormDoc, &form)
+	if err == store.ErrNotFound {
+		return nil, nil
+	}
+	return form.Fields, err
+}
+
+func (s *FieldStore) Replace(ctx context.Context, fields []models.RegistrationField) error {
+	_, err := GetSettingsCollection().Doc(registrationFormDoc).Set(ctx, models.RegistrationForm{Fields: fields})
+	return err
+}
+
+// getDoc decodes document id of collection into dest, mapping a missing
+// document to store.ErrNotFound
+func getDoc(ctx context.Context, collection CollectionRefInterface, id string, dest interface{}) error {
+	doc, err := collection.Doc(id).Get(ctx)
+	if err == ErrNotFound {
+		return store.ErrNotFound
+	}
+	if err != nil {
+		return err
+	}
+	return doc.DataTo(dest)
+}
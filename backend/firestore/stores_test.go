@@ -0,0 +1,155 @@
+package firestore
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"event-registration-backend/models"
+	"event-registration-backend/store"
+)
+
+// useMockStores points the package at an empty in-memory store
+func useMockStores(t *testing.T) store.Stores {
+	t.Helper()
+	SetMockClient(NewMockFirestoreClient("acme"), "acme")
+	t.Cleanup(ClearTestClient)
+	return NewStores()
+}
+
+func TestAttendeeStore_LegacyRegistrations(t *testing.T) {
+	attendees := useMockStores(t).Attendees
+	ctx := context.Background()
+
+	// Registered before IDs were derived from the email, and before counts
+	legacy := map[string]interface{}{"fullName": "Old Timer", "email": "Old@Example.com", "designation": "Developer", "registeredAt": time.Now()}
+	if _, err := GetAttendeesCollection().Doc("legacy").Set(ctx, legacy); err != nil {
+		t.Fatalf("Failed to store legacy attendee: %v", err)
+	}
+
+	duplicate := models.Attendee{FullName: "Impostor", Email: "old@example.com", RegisteredAt: time.Now()}
+	if err := attendees.Create(ctx, &duplicate, 1); err != store.ErrAlreadyExists {
+		t.Fatalf("Expected the legacy address to be taken, got %v", err)
+	}
+	waiting := models.Attendee{FullName: "Ada", Email: "ada@example.com", RegisteredAt: time.Now()}
+	if err := attendees.Create(ctx, &waiting, 1); err != nil || waiting.Status != models.AttendeeWaitlisted {
+		t.Fatalf("Expected the legacy attendee to hold the only seat, got %+v (err %v)", waiting, err)
+	}
+
+	if _, promoted, err := attendees.Cancel(ctx, "legacy", 1); err != nil || len(promoted) != 1 || promoted[0].ID != waiting.ID {
+		t.Fatalf("Expected ada promoted, got %+v (err %v)", promoted, err)
+	}
+	again := models.Attendee{FullName: "Old Timer", Email: "old@example.com", RegisteredAt: time.Now()}
+	if err := attendees.Create(ctx, &again, 1); err != nil || again.WaitlistPosition != 1 {
+		t.Errorf("Expected the released address to join the waitlist, got %+v (err %v)", again, err)
+	}
+}
+
+func TestAttendeeStore_CancelRenumbersWaitlist(t *testing.T) {
+	attendees := useMockStores(t).Attendees
+	ctx := context.Background()
+
+	ids := make(map[string]string)
+	for _, name := range []string{"ada", "bob", "cy", "dee", "eve"} {
+		attendee := models.Attendee{FullName: name, Email: name + "@example.com", RegisteredAt: time.Now()}
+		if err := attendees.Create(ctx, &attendee, 1); err != nil {
+			t.Fatalf("Create %s failed: %v", name, err)
+		}
+		ids[name] = attendee.ID
+	}
+
+	// cy leaves the middle of the waitlist, then ada frees a seat for bob
+	if _, _, err := attendees.Cancel(ctx, ids["cy"], 1); err != nil {
+		t.Fatalf("Cancel failed: %v", err)
+	}
+	if _, promoted, err := attendees.Cancel(ctx, ids["ada"], 1); err != nil || len(promoted) != 1 || promoted[0].ID != ids["bob"] {
+		t.Fatalf("Expected bob promoted, got %+v (err %v)", promoted, err)
+	}
+	for name, position := range map[string]int{"dee": 1, "eve": 2} {
+		if attendee, _ := attendees.Get(ctx, ids[name]); attendee.WaitlistPosition != position {
+			t.Errorf("Expected %s at %d, got %+v", name, position, attendee)
+		}
+	}
+
+	// A raised capacity is filled from the front of the waitlist
+	if _, promoted, err := attendees.Cancel(ctx, ids["bob"], 3); err != nil || len(promoted) != 2 {
+		t.Errorf("Expected dee and eve promoted, got %+v (err %v)", promoted, err)
+	}
+	if counts, _ := attendees.CountByStatus(ctx); counts[models.AttendeeConfirmed] != 2 || counts[models.AttendeeWaitlisted] != 0 {
+		t.Errorf("Unexpected counts: %v", counts)
+	}
+}
+
+func TestAttendeeStore_CheckInStats(t *testing.T) {
+	attendees := useMockStores(t).Attendees
+	ctx := context.Background()
+
+	// Counts built before check-ins were counted are rebuilt
+	checkedInAt := time.Now()
+	legacy := map[string]interface{}{"fullName": "Old Timer", "email": "old@example.com", "designation": "Developer", "registeredAt": time.Now(), "checkedInAt": checkedInAt}
+	if _, err := GetAttendeesCollection().Doc("legacy").Set(ctx, legacy); err != nil {
+		t.Fatalf("Failed to store legacy attendee: %v", err)
+	}
+	if _, err := GetSettingsCollection().Doc(attendeeCountsDoc).Set(ctx, map[string]interface{}{"confirmed": 1}); err != nil {
+		t.Fatalf("Failed to store old counts: %v", err)
+	}
+
+	ada := models.Attendee{FullName: "Ada", Email: "ada@example.com", Designation: "Developer", RegisteredAt: time.Now()}
+	if err := attendees.Create(ctx, &ada, 0); err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	if _, err := attendees.CheckIn(ctx, ada.ID, time.Now()); err != nil {
+		t.Fatalf("CheckIn failed: %v", err)
+	}
+	if _, err := attendees.UpdateDetails(ctx, ada.ID, "Ada", "Manager"); err != nil {
+		t.Fatalf("UpdateDetails failed: %v", err)
+	}
+	if _, _, err := attendees.Cancel(ctx, "legacy", 0); err != nil {
+		t.Fatalf("Cancel failed: %v", err)
+	}
+
+	stats, err := attendees.CheckInStats(ctx)
+	if err != nil {
+		t.Fatalf("CheckInStats failed: %v", err)
+	}
+	if stats.Confirmed != 1 || stats.CheckedIn != 1 || stats.Remaining != 0 || len(stats.ByDesignation) != 1 || stats.ByDesignation["Manager"] != 1 {
+		t.Errorf("Unexpected check-in stats: %+v", stats)
+	}
+}
+
+func TestAttendeeStore_CountByDesignationAndField(t *testing.T) {
+	attendees := useMockStores(t).Attendees
+	ctx := context.Background()
+
+	ids := make(map[string]string)
+	for _, name := range []string{"ada", "bob", "cy"} {
+		attendee := models.Attendee{FullName: name, Email: name + "@example.com", Designation: "Developer", RegisteredAt: time.Now(), Fields: map[string]string{"tshirtSize": "M"}}
+		if err := attendees.Create(ctx, &attendee, 2); err != nil {
+			t.Fatalf("Create %s failed: %v", name, err)
+		}
+		ids[name] = attendee.ID
+	}
+	if _, err := attendees.UpdateDetails(ctx, ids["bob"], "bob", ""); err != nil {
+		t.Fatalf("UpdateDetails failed: %v", err)
+	}
+	// ada's seat goes to cy
+	if _, _, err := attendees.Cancel(ctx, ids["ada"], 2); err != nil {
+		t.Fatalf("Cancel failed: %v", err)
+	}
+
+	confirmed, err := attendees.CountByDesignation(ctx, models.AttendeeConfirmed)
+	if err != nil || len(confirmed) != 2 || confirmed["Developer"] != 1 || confirmed[""] != 1 {
+		t.Errorf("Unexpected confirmed designations: %v (err %v)", confirmed, err)
+	}
+	if waitlisted, err := attendees.CountByDesignation(ctx, models.AttendeeWaitlisted); err != nil || len(waitlisted) != 0 {
+		t.Errorf("Expected nobody waitlisted, got %v (err %v)", waitlisted, err)
+	}
+	if cancelled, err := attendees.CountByDesignation(ctx, models.AttendeeCancelled); err != nil || cancelled["Developer"] != 1 {
+		t.Errorf("Unexpected cancelled designations: %v (err %v)", cancelled, err)
+	}
+
+	sizes, err := attendees.CountByField(ctx, models.AttendeeConfirmed, "tshirtSize", []string{"S", "M"})
+	if err != nil || len(sizes) != 2 || sizes["S"] != 0 || sizes["M"] != 2 {
+		t.Errorf("Unexpected answer counts: %v (err %v)", sizes, err)
+	}
+}
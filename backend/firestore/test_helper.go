@@ -1,6 +1,7 @@
 package firestore
 
 import (
+	"context"
 	"sync"
 
 	"cloud.google.com/go/firestore"
@@ -24,6 +25,14 @@ func SetTestClient(client *firestore.Client, clientID string) {
 	testMode = true
 }
 
+// MockFirestoreClient is the in-memory LocalClient used by tests
+type MockFirestoreClient = LocalClient
+
+// NewMockFirestoreClient creates an empty in-memory store for tests
+func NewMockFirestoreClient(clientID string) *MockFirestoreClient {
+	return NewLocalClient()
+}
+
 // SetMockClient sets a mock Firestore client for testing
 func SetMockClient(mockClient *MockFirestoreClient, clientID string) {
 	testMutex.Lock()
@@ -34,6 +43,26 @@ func SetMockClient(mockClient *MockFirestoreClient, clientID string) {
 	testMode = true
 }
 
+var (
+	emulatorOnce   sync.Once
+	emulatorClient *firestore.Client
+	emulatorErr    error
+)
+
+// SetEmulatorTestClient points the package at the Firestore emulator for
+// clientID. Tests should pass a fresh client ID so each starts with empty
+// collections. The emulator connection is shared across tests.
+func SetEmulatorTestClient(ctx context.Context, clientID string) error {
+	emulatorOnce.Do(func() {
+		emulatorClient, emulatorErr = NewEmulatorClient(ctx, EmulatorProjectID)
+	})
+	if emulatorErr != nil {
+		return emulatorErr
+	}
+	SetTestClient(emulatorClient, clientID)
+	return nil
+}
+
 // ClearTestClient clears the test client
 func ClearTestClient() {
 	testMutex.Lock()
@@ -54,13 +83,16 @@ func getClient() *firestore.Client {
 	return Client
 }
 
-// getMockClient returns the mock client if in test mode
-func getMockClient() *MockFirestoreClient {
+// getLocalClient returns the mock client if in test mode, or the local store if initialized
+func getLocalClient() *LocalClient {
 	testMutex.RLock()
 	defer testMutex.RUnlock()
 	if testMode && testMockClient != nil {
 		return testMockClient
 	}
+	if !testMode {
+		return localClient
+	}
 	return nil
 }
 
@@ -0,0 +1,104 @@
+package firestore
+
+import (
+	"context"
+	"fmt"
+
+	"cloud.google.com/go/firestore"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// TransactionInterface reads and writes documents atomically inside
+// RunTransaction. As in Firestore, reads see only committed data, so every
+// read should happen before the first write.
+type TransactionInterface interface {
+	Get(doc DocumentRefInterface) (DocumentSnapshotInterface, error)
+	Documents(query CollectionRefInterface) ([]DocumentSnapshotInterface, error)
+	Create(doc DocumentRefInterface, data interface{}) error
+	Set(doc DocumentRefInterface, data interface{}) error
+	Delete(doc DocumentRefInterface) error
+}
+
+// RunTransaction runs fn in a transaction on the current database, retrying it
+// on contention. fn may run more than once and must not have side effects
+// beyond its transaction writes. Errors returned by fn are passed through.
+func RunTransaction(ctx context.Context, fn func(ctx context.Context, tx TransactionInterface) error) error {
+	if local := getLocalClient(); local != nil {
+		return local.RunTransaction(ctx, fn)
+	}
+	err := getClient().RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		return fn(ctx, &RealTransaction{tx: tx})
+	})
+	if status.Code(err) == codes.AlreadyExists {
+		return ErrAlreadyExists
+	}
+	return err
+}
+
+// RealTransaction wraps a real Firestore transaction
+type RealTransaction struct {
+	tx *firestore.Transaction
+}
+
+func (t *RealTransaction) Get(doc DocumentRefInterface) (DocumentSnapshotInterface, error) {
+	ref, err := realDocRef(doc)
+	if err != nil {
+		return nil, err
+	}
+	snapshot, err := t.tx.Get(ref)
+	if err != nil {
+		if status.Code(err) == codes.NotFound {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return &RealDocumentSnapshot{snapshot: snapshot}, nil
+}
+
+func (t *RealTransaction) Documents(query CollectionRefInterface) ([]DocumentSnapshotInterface, error) {
+	q, ok := query.(*RealCollectionRef)
+	if !ok {
+		return nil, fmt.Errorf("query %T does not belong to a Firestore client", query)
+	}
+	iter := &RealDocumentIterator{}
+	if q.hasQuery {
+		iter.iter = t.tx.Documents(q.query)
+	} else {
+		iter.iter = t.tx.Documents(q.ref)
+	}
+	return iter.GetAll()
+}
+
+func (t *RealTransaction) Create(doc DocumentRefInterface, data interface{}) error {
+	ref, err := realDocRef(doc)
+	if err != nil {
+		return err
+	}
+	return t.tx.Create(ref, data)
+}
+
+func (t *RealTransaction) Set(doc DocumentRefInterface, data interface{}) error {
+	ref, err := realDocRef(doc)
+	if err != nil {
+		return err
+	}
+	return t.tx.Set(ref, data)
+}
+
+func (t *RealTransaction) Delete(doc DocumentRefInterface) error {
+	ref, err := realDocRef(doc)
+	if err != nil {
+		return err
+	}
+	return t.tx.Delete(ref)
+}
+
+// realDocRef unwraps a document reference obtained from a Firestore client
+func realDocRef(doc DocumentRefInterface) (*firestore.DocumentRef, error) {
+	ref, ok := doc.(*RealDocumentRef)
+	if !ok {
+		return nil, fmt.Errorf("document %T does not belong to a Firestore client", doc)
+	}
+	return ref.ref, nil
+}
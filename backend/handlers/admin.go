@@ -2,24 +2,55 @@ package handlers
 
 import (
 	"encoding/json"
+	"log"
+	"math"
 	"net/http"
-	"os"
+	"strconv"
+	"time"
+
+	"event-registration-backend/auth"
+	"event-registration-backend/firestore"
+	"event-registration-backend/models"
 )
 
 type LoginRequest struct {
+	Username string `json:"username"`
 	Password string `json:"password"`
 }
 
 type LoginResponse struct {
-	Success bool   `json:"success"`
-	Message string `json:"message"`
+	Success   bool       `json:"success"`
+	Message   string     `json:"message"`
+	Token     string     `json:"token,omitempty"`
+	Role      auth.Role  `json:"role,omitempty"`
+	ExpiresAt *time.Time `json:"expiresAt,omitempty"`
+}
+
+type RevokeTokenRequest struct {
+	Token string `json:"token"`
+}
+
+var (
+	sessionSecret []byte
+	sessionTTL    = 12 * time.Hour
+	loginThrottle = auth.NewThrottle(auth.NewMemoryAttemptStore(), 5, time.Minute, time.Hour)
+)
+
+// SetSessionSecret sets the key used to sign admin session tokens
+func SetSessionSecret(secret string) {
+	sessionSecret = []byte(secret)
 }
 
-var AdminPassword string
+// SetSessionTTL sets how long issued admin session tokens stay valid
+func SetSessionTTL(ttl time.Duration) {
+	if ttl > 0 {
+		sessionTTL = ttl
+	}
+}
 
-// SetAdminPassword sets the admin password from config
-func SetAdminPassword(password string) {
-	AdminPassword = password
+// SetLoginThrottle replaces the brute-force protection applied to AdminLogin
+func SetLoginThrottle(throttle *auth.Throttle) {
+	loginThrottle = throttle
 }
 
 // AdminLogin handles admin authentication
@@ -35,29 +66,165 @@ func AdminLogin(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Use AdminPassword from config, fallback to env if not set
-	adminPassword := AdminPassword
-	if adminPassword == "" {
-		adminPassword = os.Getenv("ADMIN_PASSWORD")
+	if req.Username == "" || req.Password == "" {
+		http.Error(w, "Missing required fields", http.StatusBadRequest)
+		return
 	}
-	if adminPassword == "" {
-		adminPassword = "admin123" // Default for development
+
+	ctx := r.Context()
+	ipKey := "ip:" + clientIP(r)
+	accountKey := "user:" + normalizeUsername(req.Username)
+
+	wait, err := loginThrottle.Check(ctx, ipKey, accountKey)
+	if err != nil {
+		http.Error(w, "Failed to check login attempts", http.StatusInternalServerError)
+		return
+	}
+	if wait > 0 {
+		writeLockedOut(w, wait)
+		return
 	}
 
-	if req.Password != adminPassword {
+	user, err := getAdminUser(ctx, req.Username)
+	if err != nil && err != firestore.ErrNotFound {
+		http.Error(w, "Failed to look up admin user", http.StatusInternalServerError)
+		return
+	}
+
+	// Always run the hash comparison so unknown usernames take as long as wrong passwords
+	passwordHash := ""
+	if user != nil {
+		passwordHash = user.PasswordHash
+	}
+	if !auth.CheckPassword(passwordHash, req.Password) {
+		for _, key := range []string{ipKey, accountKey} {
+			if _, err := loginThrottle.RecordFailure(ctx, key); err != nil {
+				log.Printf("Failed to record failed login for %s: %v", key, err)
+			}
+		}
 		w.Header().Set("Content-Type", "application/json")
 		w.WriteHeader(http.StatusUnauthorized)
 		json.NewEncoder(w).Encode(LoginResponse{
 			Success: false,
-			Message: "Invalid password",
+			Message: "Invalid username or password",
 		})
 		return
 	}
 
+	// Guesses sent in parallel all pass the first check; if they locked the
+	// account meanwhile, a correct one among them is refused too
+	wait, err = loginThrottle.Check(ctx, ipKey, accountKey)
+	if err != nil {
+		http.Error(w, "Failed to check login attempts", http.StatusInternalServerError)
+		return
+	}
+	if wait > 0 {
+		writeLockedOut(w, wait)
+		return
+	}
+
+	if err := loginThrottle.RecordSuccess(ctx, accountKey); err != nil {
+		log.Printf("Failed to reset login attempts for %s: %v", accountKey, err)
+	}
+
+	token, claims, err := auth.IssueToken(sessionSecret, user.Username, user.Role, user.SessionGeneration, sessionTTL)
+	if err != nil {
+		http.Error(w, "Failed to issue session token", http.StatusInternalServerError)
+		return
+	}
+	expiresAt := claims.ExpiresAtTime()
+
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(LoginResponse{
-		Success: true,
-		Message: "Login successful",
+		Success:   true,
+		Message:   "Login successful",
+		Token:     token,
+		Role:      claims.Role,
+		ExpiresAt: &expiresAt,
 	})
 }
 
+// writeLockedOut refuses a login attempt while the account or IP is locked out
+func writeLockedOut(w http.ResponseWriter, wait time.Duration) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+	w.WriteHeader(http.StatusTooManyRequests)
+	json.NewEncoder(w).Encode(LoginResponse{
+		Success: false,
+		Message: "Too many failed login attempts, try again later",
+	})
+}
+
+// AdminLogout revokes the session token used to make the request (admin only)
+func AdminLogout(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	claims, ok := AdminClaimsFromContext(r.Context())
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+	if claims.APIKeyID != "" {
+		http.Error(w, "API keys cannot log out; revoke the key instead", http.StatusBadRequest)
+		return
+	}
+
+	if err := revokeToken(r, claims); err != nil {
+		http.Error(w, "Failed to revoke token", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]bool{"success": true})
+}
+
+// RevokeToken revokes an arbitrary session token, e.g. one that has leaked (owner only)
+func RevokeToken(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req RevokeTokenRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	claims, err := auth.ParseToken(sessionSecret, req.Token)
+	if err == auth.ErrExpiredToken {
+		// Already unusable, nothing to revoke
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]bool{"success": true})
+		return
+	}
+	if err != nil {
+		http.Error(w, "Invalid token", http.StatusBadRequest)
+		return
+	}
+
+	if err := revokeToken(r, claims); err != nil {
+		http.Error(w, "Failed to revoke token", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]bool{"success": true})
+}
+
+// revokeToken records claims.TokenID in the revoked tokens collection
+func revokeToken(r *http.Request, claims *auth.Claims) error {
+	revoked := models.RevokedToken{
+		Subject:   claims.Subject,
+		RevokedAt: time.Now(),
+		ExpiresAt: claims.ExpiresAtTime(),
+	}
+	if _, err := firestore.GetRevokedTokensCollection().Doc(claims.TokenID).Set(r.Context(), revoked); err != nil {
+		return err
+	}
+	recordAudit(r, "session_token", claims.TokenID, auditActionRevoke, nil, revoked)
+	return nil
+}
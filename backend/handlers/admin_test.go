@@ -2,19 +2,38 @@ package handlers
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
-	"os"
 	"testing"
+
+	"event-registration-backend/auth"
+	"event-registration-backend/models"
 )
 
+// createTestAdmin stores an owner account directly in the mock store
+func createTestAdmin(t *testing.T, username, password string) {
+	t.Helper()
+	createTestAdminWithRole(t, username, password, auth.RoleOwner)
+}
+
+// createTestAdminWithRole stores an admin account with the given role in the mock store
+func createTestAdminWithRole(t *testing.T, username, password string, role auth.Role) {
+	t.Helper()
+	if _, err := saveAdminUser(context.Background(), username, password, role); err != nil {
+		t.Fatalf("Failed to create admin user: %v", err)
+	}
+}
+
 func TestAdminLogin_Success(t *testing.T) {
 	setupTestClient(t)
-	// Set admin password
-	SetAdminPassword("testpassword123")
+	defer teardownTestClient()
+	createTestAdmin(t, "alice", "testpassword123")
 	
 	reqBody := LoginRequest{
+		Username: "alice",
 		Password: "testpassword123",
 	}
 	
@@ -40,13 +59,21 @@ func TestAdminLogin_Success(t *testing.T) {
 	if response.Message != "Login successful" {
 		t.Errorf("Expected message 'Login successful', got '%s'", response.Message)
 	}
+	if response.Token == "" {
+		t.Error("Expected a session token")
+	}
+	if response.ExpiresAt == nil {
+		t.Error("Expected token expiry to be set")
+	}
 }
 
 func TestAdminLogin_InvalidPassword(t *testing.T) {
 	setupTestClient(t)
-	SetAdminPassword("correctpassword")
+	defer teardownTestClient()
+	createTestAdmin(t, "alice", "correctpassword")
 	
 	reqBody := LoginRequest{
+		Username: "alice",
 		Password: "wrongpassword",
 	}
 	
@@ -69,25 +96,34 @@ func TestAdminLogin_InvalidPassword(t *testing.T) {
 	if response.Success {
 		t.Error("Expected success to be false")
 	}
-	if response.Message != "Invalid password" {
-		t.Errorf("Expected message 'Invalid password', got '%s'", response.Message)
+	if response.Message != "Invalid username or password" {
+		t.Errorf("Expected message 'Invalid username or password', got '%s'", response.Message)
 	}
 }
 
-func TestAdminLogin_FromEnv(t *testing.T) {
+func TestAdminLogin_UnknownUser(t *testing.T) {
 	setupTestClient(t)
-	// Clear set password
-	SetAdminPassword("")
+	defer teardownTestClient()
+	createTestAdmin(t, "alice", "correctpassword")
 	
-	// Set environment variable
-	os.Setenv("ADMIN_PASSWORD", "envpassword123")
-	defer os.Unsetenv("ADMIN_PASSWORD")
+	body, _ := json.Marshal(LoginRequest{Username: "mallory", Password: "correctpassword"})
+	req := httptest.NewRequest("POST", "/api/admin/login", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
 	
-	reqBody := LoginRequest{
-		Password: "envpassword123",
+	AdminLogin(w, req)
+	
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("Expected status 401, got %d", w.Code)
 	}
+}
+
+func TestAdminLogin_UsernameCaseInsensitive(t *testing.T) {
+	setupTestClient(t)
+	defer teardownTestClient()
+	createTestAdmin(t, "alice", "correctpassword")
 	
-	body, _ := json.Marshal(reqBody)
+	body, _ := json.Marshal(LoginRequest{Username: " Alice ", Password: "correctpassword"})
 	req := httptest.NewRequest("POST", "/api/admin/login", bytes.NewBuffer(body))
 	req.Header.Set("Content-Type", "application/json")
 	w := httptest.NewRecorder()
@@ -99,25 +135,96 @@ func TestAdminLogin_FromEnv(t *testing.T) {
 	}
 }
 
-func TestAdminLogin_DefaultPassword(t *testing.T) {
+func TestAdminLogin_MissingFields(t *testing.T) {
 	setupTestClient(t)
-	// Clear set password and env
-	SetAdminPassword("")
-	os.Unsetenv("ADMIN_PASSWORD")
+	defer teardownTestClient()
 	
-	reqBody := LoginRequest{
-		Password: "admin123",
-	}
-	
-	body, _ := json.Marshal(reqBody)
+	body, _ := json.Marshal(LoginRequest{Password: "admin123"})
 	req := httptest.NewRequest("POST", "/api/admin/login", bytes.NewBuffer(body))
 	req.Header.Set("Content-Type", "application/json")
 	w := httptest.NewRecorder()
 	
 	AdminLogin(w, req)
 	
-	if w.Code != http.StatusOK {
-		t.Errorf("Expected status 200, got %d", w.Code)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400, got %d", w.Code)
+	}
+}
+
+func TestBootstrapAdmin(t *testing.T) {
+	setupTestClient(t)
+	defer teardownTestClient()
+	ctx := context.Background()
+	
+	created, err := BootstrapAdmin(ctx, "owner", "bootstrap-pass")
+	if err != nil {
+		t.Fatalf("Bootstrap failed: %v", err)
+	}
+	if !created {
+		t.Error("Expected first bootstrap to create an admin")
+	}
+	
+	created, err = BootstrapAdmin(ctx, "someone-else", "another-pass")
+	if err != nil {
+		t.Fatalf("Second bootstrap failed: %v", err)
+	}
+	if created {
+		t.Error("Expected bootstrap to be a no-op once an admin exists")
+	}
+	
+	user, err := getAdminUser(ctx, "owner")
+	if err != nil {
+		t.Fatalf("Expected bootstrapped admin to exist: %v", err)
+	}
+	if user.PasswordHash == "bootstrap-pass" || user.PasswordHash == "" {
+		t.Error("Expected password to be stored hashed")
+	}
+	if user.Role != auth.RoleOwner {
+		t.Errorf("Expected bootstrapped admin to be an owner, got '%s'", user.Role)
+	}
+}
+
+func TestBootstrapAdmin_NoPassword(t *testing.T) {
+	setupTestClient(t)
+	defer teardownTestClient()
+	
+	if _, err := BootstrapAdmin(context.Background(), "owner", ""); err == nil {
+		t.Error("Expected error when no admins exist and no password is configured")
+	}
+}
+
+func TestCreateOrUpdateAdminUser(t *testing.T) {
+	setupTestClient(t)
+	defer teardownTestClient()
+	
+	testCases := []struct {
+		name           string
+		body           models.AdminUserRequest
+		expectedStatus int
+	}{
+		{"Valid", models.AdminUserRequest{Username: "bob", Password: "bob-password"}, http.StatusOK},
+		{"Missing password", models.AdminUserRequest{Username: "bob"}, http.StatusBadRequest},
+		{"Short password", models.AdminUserRequest{Username: "bob", Password: "short"}, http.StatusBadRequest},
+		{"Invalid username", models.AdminUserRequest{Username: "bob/evil", Password: "bob-password"}, http.StatusBadRequest},
+		{"With role", models.AdminUserRequest{Username: "carol", Password: "carol-password", Role: "editor"}, http.StatusOK},
+		{"Unknown role", models.AdminUserRequest{Username: "dave", Password: "dave-password", Role: "root"}, http.StatusBadRequest},
+	}
+	
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			body, _ := json.Marshal(tc.body)
+			req := httptest.NewRequest("POST", "/api/admin/users", bytes.NewBuffer(body))
+			w := httptest.NewRecorder()
+			
+			CreateOrUpdateAdminUser(w, req)
+			
+			if w.Code != tc.expectedStatus {
+				t.Errorf("Expected status %d, got %d", tc.expectedStatus, w.Code)
+			}
+			if w.Code == http.StatusOK && bytes.Contains(w.Body.Bytes(), []byte("passwordHash")) {
+				t.Error("Expected password hash not to be returned")
+			}
+		})
 	}
 }
 
@@ -146,3 +253,53 @@ func TestAdminLogin_WrongMethod(t *testing.T) {
 	}
 }
 
+
+// attemptLogin posts credentials from remoteAddr and returns the response code
+func attemptLogin(username, password, remoteAddr string) *httptest.ResponseRecorder {
+	body, _ := json.Marshal(LoginRequest{Username: username, Password: password})
+	req := httptest.NewRequest("POST", "/api/admin/login", bytes.NewBuffer(body))
+	req.RemoteAddr = remoteAddr
+	w := httptest.NewRecorder()
+	AdminLogin(w, req)
+	return w
+}
+
+func TestAdminLogin_AccountLockout(t *testing.T) {
+	setupTestClient(t)
+	defer teardownTestClient()
+	createTestAdmin(t, "alice", "correctpassword")
+	
+	// Failures from different IPs still count against the account
+	for i := 0; i < 5; i++ {
+		w := attemptLogin("alice", "wrongpassword", fmt.Sprintf("10.0.0.%d:1234", i))
+		if w.Code != http.StatusUnauthorized {
+			t.Fatalf("Attempt %d: expected status 401, got %d", i+1, w.Code)
+		}
+	}
+	
+	w := attemptLogin("alice", "correctpassword", "10.0.1.1:1234")
+	if w.Code != http.StatusTooManyRequests {
+		t.Errorf("Expected status 429 for locked account, got %d", w.Code)
+	}
+	if w.Header().Get("Retry-After") == "" {
+		t.Error("Expected Retry-After header")
+	}
+}
+
+func TestAdminLogin_IPLockout(t *testing.T) {
+	setupTestClient(t)
+	defer teardownTestClient()
+	createTestAdmin(t, "alice", "correctpassword")
+	
+	// Spraying different usernames from one IP locks the IP
+	for i := 0; i < 5; i++ {
+		attemptLogin(fmt.Sprintf("user%d", i), "wrongpassword", "192.0.2.7:5555")
+	}
+	
+	if w := attemptLogin("alice", "correctpassword", "192.0.2.7:5555"); w.Code != http.StatusTooManyRequests {
+		t.Errorf("Expected status 429 for locked IP, got %d", w.Code)
+	}
+	if w := attemptLogin("alice", "correctpassword", "198.51.100.1:5555"); w.Code != http.StatusOK {
+		t.Errorf("Expected status 200 from another IP, got %d", w.Code)
+	}
+}
@@ -0,0 +1,253 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+
+	"event-registration-backend/auth"
+	"event-registration-backend/firestore"
+	"event-registration-backend/models"
+)
+
+var usernamePattern = regexp.MustCompile(`^[a-z0-9._@-]{1,64}$`)
+
+// normalizeUsername lowercases and trims a username so lookups are case-insensitive.
+// The normalized username is also the admin document ID.
+func normalizeUsername(username string) string {
+	return strings.ToLower(strings.TrimSpace(username))
+}
+
+// getAdminUser loads an admin account by username, returning firestore.ErrNotFound if absent
+func getAdminUser(ctx context.Context, username string) (*models.AdminUser, error) {
+	doc, err := firestore.GetAdminsCollection().Doc(normalizeUsername(username)).Get(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var user models.AdminUser
+	if err := doc.DataTo(&user); err != nil {
+		return nil, err
+	}
+	user.Username = doc.GetID()
+	applyLegacyRole(&user)
+	return &user, nil
+}
+
+// applyLegacyRole gives accounts created before roles existed the full access they already had
+func applyLegacyRole(user *models.AdminUser) {
+	if user.Role == "" {
+		user.Role = auth.RoleOwner
+	}
+}
+
+// validateAdminCredentials checks username format and password strength
+func validateAdminCredentials(username, password string) error {
+	if !usernamePattern.MatchString(normalizeUsername(username)) {
+		return fmt.Errorf("username must be 1-64 characters of a-z, 0-9, '.', '_', '@' or '-'")
+	}
+	if len(password) < auth.MinPasswordLength {
+		return fmt.Errorf("password must be at least %d characters", auth.MinPasswordLength)
+	}
+	return nil
+}
+
+// saveAdminUser hashes password and stores the account with role under its normalized username
+func saveAdminUser(ctx context.Context, username, password string, role auth.Role) (*models.AdminUser, error) {
+	if err := validateAdminCredentials(username, password); err != nil {
+		return nil, err
+	}
+	username = normalizeUsername(username)
+
+	hash, err := auth.HashPassword(password)
+	if err != nil {
+		return nil, err
+	}
+
+	user := models.AdminUser{
+		Username:     username,
+		PasswordHash: hash,
+		Role:         role,
+		CreatedAt:    time.Now(),
+	}
+	if _, err := firestore.GetAdminsCollection().Doc(username).Set(ctx, user); err != nil {
+		return nil, fmt.Errorf("failed to save admin user: %w", err)
+	}
+	return &user, nil
+}
+
+// errLastOwner is returned when a change would leave no account with the owner role
+var errLastOwner = errors.New("cannot demote the last owner")
+
+// updateAdminUser creates or updates the account username with password and
+// role, keeping its current role when role is empty. Changing the password
+// ends the account's existing sessions. It returns the account before and
+// after, before being nil for a new account, or errLastOwner.
+func updateAdminUser(ctx context.Context, username, password string, role auth.Role) (before, user *models.AdminUser, err error) {
+	username = normalizeUsername(username)
+	hash, err := auth.HashPassword(password)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	coll := firestore.GetAdminsCollection()
+	err = firestore.RunTransaction(ctx, func(ctx context.Context, tx firestore.TransactionInterface) error {
+		// Admin accounts are few, so counting owners reads them all
+		docs, err := tx.Documents(coll)
+		if err != nil {
+			return err
+		}
+		before = nil
+		owners := 0
+		for _, doc := range docs {
+			var existing models.AdminUser
+			if err := doc.DataTo(&existing); err != nil {
+				continue
+			}
+			existing.Username = doc.GetID()
+			applyLegacyRole(&existing)
+			if existing.Role == auth.RoleOwner {
+				owners++
+			}
+			if existing.Username == username {
+				before = &existing
+			}
+		}
+
+		user = &models.AdminUser{Username: username, PasswordHash: hash, Role: role, CreatedAt: time.Now()}
+		if user.Role == "" {
+			user.Role = auth.RoleViewer
+		}
+		if before != nil {
+			if role == "" {
+				user.Role = before.Role
+			}
+			if before.Role == auth.RoleOwner && user.Role != auth.RoleOwner && owners == 1 {
+				return errLastOwner
+			}
+			user.CreatedAt = before.CreatedAt
+			user.SessionGeneration = before.SessionGeneration
+			if !auth.CheckPassword(before.PasswordHash, password) {
+				user.SessionGeneration++
+			}
+		}
+		return tx.Set(coll.Doc(username), user)
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return before, user, nil
+}
+
+// BootstrapAdmin creates the first admin account, with the owner role, if none exist yet.
+// It returns true when an account was created.
+func BootstrapAdmin(ctx context.Context, username, password string) (bool, error) {
+	docs, err := firestore.GetAdminsCollection().Limit(1).Documents(ctx).GetAll()
+	if err != nil {
+		return false, fmt.Errorf("failed to check for existing admins: %w", err)
+	}
+	if len(docs) > 0 {
+		return false, nil
+	}
+	if password == "" {
+		return false, fmt.Errorf("no admin accounts exist; set ADMIN_PASSWORD to create the first one")
+	}
+
+	if _, err := saveAdminUser(ctx, username, password, auth.RoleOwner); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// GetAdminUsers lists admin accounts without their password hashes (owner only)
+func GetAdminUsers(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	docs, err := firestore.GetAdminsCollection().Documents(r.Context()).GetAll()
+	if err != nil {
+		http.Error(w, "Failed to get admin users", http.StatusInternalServerError)
+		return
+	}
+
+	var users []models.AdminUser
+	for _, doc := range docs {
+		var user models.AdminUser
+		if err := doc.DataTo(&user); err != nil {
+			continue
+		}
+		user.Username = doc.GetID()
+		applyLegacyRole(&user)
+		users = append(users, user)
+	}
+
+	// Ensure we return an empty array, not null
+	if users == nil {
+		users = []models.AdminUser{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(users)
+}
+
+// CreateOrUpdateAdminUser creates an admin account or resets its password and
+// role (owner only). The role is kept when omitted, and the last owner cannot
+// be demoted.
+func CreateOrUpdateAdminUser(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req models.AdminUserRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if req.Username == "" || req.Password == "" {
+		http.Error(w, "Missing required fields", http.StatusBadRequest)
+		return
+	}
+
+	if err := validateAdminCredentials(req.Username, req.Password); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// An omitted role keeps the account's role; new accounts default to viewer
+	var role auth.Role
+	if req.Role != "" {
+		parsed, err := auth.ParseRole(req.Role)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		role = parsed
+	}
+
+	before, user, err := updateAdminUser(r.Context(), req.Username, req.Password, role)
+	if err == errLastOwner {
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	}
+	if err != nil {
+		http.Error(w, "Failed to save admin user", http.StatusInternalServerError)
+		return
+	}
+
+	action := auditActionUpdate
+	if before == nil {
+		action = auditActionCreate
+	}
+	recordAudit(r, "admin_user", user.Username, action, before, user)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(user)
+}
@@ -0,0 +1,184 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"event-registration-backend/auth"
+	"event-registration-backend/firestore"
+	"event-registration-backend/models"
+
+	"github.com/gorilla/mux"
+)
+
+// apiKeyClaims authenticates an API key and returns claims limited to its scopes.
+// It returns auth.ErrInvalidToken for unknown, malformed or revoked keys.
+func apiKeyClaims(ctx context.Context, key string) (*auth.Claims, error) {
+	id, secret, ok := auth.ParseAPIKey(key)
+	if !ok {
+		return nil, auth.ErrInvalidToken
+	}
+
+	doc, err := firestore.GetAPIKeysCollection().Doc(id).Get(ctx)
+	if err == firestore.ErrNotFound {
+		return nil, auth.ErrInvalidToken
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var apiKey models.APIKey
+	if err := doc.DataTo(&apiKey); err != nil {
+		return nil, err
+	}
+	if apiKey.RevokedAt != nil || !auth.CheckAPIKeySecret(apiKey.SecretHash, secret) {
+		return nil, auth.ErrInvalidToken
+	}
+
+	return &auth.Claims{
+		Subject:  "apikey:" + id,
+		Scopes:   apiKey.Scopes,
+		APIKeyID: id,
+	}, nil
+}
+
+// CreateAPIKey mints a new API key. The plaintext key is only returned in this response.
+func CreateAPIKey(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req models.APIKeyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	req.Name = strings.TrimSpace(req.Name)
+	if req.Name == "" || len(req.Scopes) == 0 {
+		http.Error(w, "Missing required fields", http.StatusBadRequest)
+		return
+	}
+
+	claims, _ := AdminClaimsFromContext(r.Context())
+	scopes := make([]auth.Permission, 0, len(req.Scopes))
+	for _, name := range req.Scopes {
+		scope, err := auth.ParsePermission(name)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		// A key can never grant more than its creator holds
+		if claims == nil || !claims.Has(scope) {
+			http.Error(w, "Forbidden: missing permission "+string(scope), http.StatusForbidden)
+			return
+		}
+		scopes = append(scopes, scope)
+	}
+
+	key, id, secretHash, err := auth.GenerateAPIKey()
+	if err != nil {
+		http.Error(w, "Failed to generate API key", http.StatusInternalServerError)
+		return
+	}
+
+	apiKey := models.APIKey{
+		Name:       req.Name,
+		SecretHash: secretHash,
+		Scopes:     scopes,
+		CreatedBy:  claims.Subject,
+		CreatedAt:  time.Now(),
+	}
+	if _, err := firestore.GetAPIKeysCollection().Doc(id).Set(r.Context(), apiKey); err != nil {
+		http.Error(w, "Failed to create API key", http.StatusInternalServerError)
+		return
+	}
+	apiKey.ID = id
+	recordAudit(r, "api_key", id, auditActionCreate, nil, apiKey)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(models.APIKeyResponse{Key: key, APIKey: apiKey})
+}
+
+// GetAPIKeys lists API keys without their secrets
+func GetAPIKeys(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	docs, err := firestore.GetAPIKeysCollection().Documents(r.Context()).GetAll()
+	if err != nil {
+		http.Error(w, "Failed to get API keys", http.StatusInternalServerError)
+		return
+	}
+
+	var apiKeys []models.APIKey
+	for _, doc := range docs {
+		var apiKey models.APIKey
+		if err := doc.DataTo(&apiKey); err != nil {
+			continue
+		}
+		apiKey.ID = doc.GetID()
+		apiKeys = append(apiKeys, apiKey)
+	}
+
+	// Ensure we return an empty array, not null
+	if apiKeys == nil {
+		apiKeys = []models.APIKey{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(apiKeys)
+}
+
+// RevokeAPIKey marks the API key named in the URL as revoked
+func RevokeAPIKey(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodDelete {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	id := mux.Vars(r)["id"]
+	if id == "" {
+		http.Error(w, "Missing API key id", http.StatusBadRequest)
+		return
+	}
+
+	ctx := r.Context()
+	ref := firestore.GetAPIKeysCollection().Doc(id)
+	doc, err := ref.Get(ctx)
+	if err == firestore.ErrNotFound {
+		http.Error(w, "API key not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, "Failed to get API key", http.StatusInternalServerError)
+		return
+	}
+
+	var apiKey models.APIKey
+	if err := doc.DataTo(&apiKey); err != nil {
+		http.Error(w, "Failed to get API key", http.StatusInternalServerError)
+		return
+	}
+	apiKey.ID = id
+	if apiKey.RevokedAt == nil {
+		before := apiKey
+		now := time.Now()
+		apiKey.RevokedAt = &now
+		if _, err := ref.Set(ctx, apiKey); err != nil {
+			http.Error(w, "Failed to revoke API key", http.StatusInternalServerError)
+			return
+		}
+		recordAudit(r, "api_key", id, auditActionRevoke, before, apiKey)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(apiKey)
+}
@@ -0,0 +1,143 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"event-registration-backend/auth"
+	"event-registration-backend/models"
+
+	"github.com/gorilla/mux"
+)
+
+// mintAPIKey creates an API key through the handler using bearer credentials
+func mintAPIKey(t *testing.T, bearer string, scopes ...string) (*httptest.ResponseRecorder, models.APIKeyResponse) {
+	t.Helper()
+	body, _ := json.Marshal(models.APIKeyRequest{Name: "CRM sync", Scopes: scopes})
+	req := httptest.NewRequest("POST", "/api/admin/apikeys", bytes.NewBuffer(body))
+	req.Header.Set("Authorization", "Bearer "+bearer)
+	w := httptest.NewRecorder()
+	RequireAdmin(RequirePermission(auth.PermissionAPIKeysManage, CreateAPIKey)).ServeHTTP(w, req)
+
+	var response models.APIKeyResponse
+	if w.Code == http.StatusCreated {
+		if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+			t.Fatalf("Failed to unmarshal response: %v", err)
+		}
+	}
+	return w, response
+}
+
+// callWithBearer runs a permission-guarded no-op handler with the given credentials
+func callWithBearer(bearer string, permission auth.Permission) int {
+	req := httptest.NewRequest("GET", "/api/admin/attendees", nil)
+	req.Header.Set("Authorization", "Bearer "+bearer)
+	w := httptest.NewRecorder()
+	ok := func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusOK) }
+	RequireAdmin(RequirePermission(permission, ok)).ServeHTTP(w, req)
+	return w.Code
+}
+
+func TestAPIKey_ScopedAccess(t *testing.T) {
+	setupTestClient(t)
+	defer teardownTestClient()
+	createTestAdmin(t, "alice", "testpassword123")
+	token := loginToken(t, "alice", "testpassword123")
+
+	w, minted := mintAPIKey(t, token, "attendees:read")
+	if w.Code != http.StatusCreated {
+		t.Fatalf("Expected status 201, got %d: %s", w.Code, w.Body.String())
+	}
+	if !strings.HasPrefix(minted.Key, auth.APIKeyPrefix) {
+		t.Errorf("Expected key with prefix %s, got %q", auth.APIKeyPrefix, minted.Key)
+	}
+
+	if code := callWithBearer(minted.Key, auth.PermissionAttendeesRead); code != http.StatusOK {
+		t.Errorf("Expected status 200 for scoped permission, got %d", code)
+	}
+	if code := callWithBearer(minted.Key, auth.PermissionSessionsWrite); code != http.StatusForbidden {
+		t.Errorf("Expected status 403 outside key scopes, got %d", code)
+	}
+	if code := callWithBearer(minted.Key+"tampered", auth.PermissionAttendeesRead); code != http.StatusUnauthorized {
+		t.Errorf("Expected status 401 for wrong secret, got %d", code)
+	}
+}
+
+func TestAPIKey_InvalidScope(t *testing.T) {
+	setupTestClient(t)
+	defer teardownTestClient()
+	createTestAdmin(t, "alice", "testpassword123")
+	token := loginToken(t, "alice", "testpassword123")
+
+	if w, _ := mintAPIKey(t, token, "everything:write"); w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400 for unknown scope, got %d", w.Code)
+	}
+}
+
+func TestAPIKey_CannotEscalate(t *testing.T) {
+	setupTestClient(t)
+	defer teardownTestClient()
+	createTestAdmin(t, "alice", "testpassword123")
+	token := loginToken(t, "alice", "testpassword123")
+
+	_, managerKey := mintAPIKey(t, token, "apikeys:manage")
+
+	if w, _ := mintAPIKey(t, managerKey.Key, "attendees:read"); w.Code != http.StatusForbidden {
+		t.Errorf("Expected status 403 when minting beyond own scopes, got %d", w.Code)
+	}
+}
+
+func TestAPIKey_ListAndRevoke(t *testing.T) {
+	setupTestClient(t)
+	defer teardownTestClient()
+	createTestAdmin(t, "alice", "testpassword123")
+	token := loginToken(t, "alice", "testpassword123")
+
+	_, minted := mintAPIKey(t, token, "attendees:read")
+
+	listReq := httptest.NewRequest("GET", "/api/admin/apikeys", nil)
+	listW := httptest.NewRecorder()
+	GetAPIKeys(listW, listReq)
+
+	if listW.Code != http.StatusOK {
+		t.Fatalf("Expected status 200 for list, got %d", listW.Code)
+	}
+	if strings.Contains(listW.Body.String(), "secretHash") || strings.Contains(listW.Body.String(), minted.Key) {
+		t.Error("Expected list not to expose key secrets")
+	}
+	var keys []models.APIKey
+	json.Unmarshal(listW.Body.Bytes(), &keys)
+	if len(keys) != 1 || keys[0].ID != minted.APIKey.ID {
+		t.Fatalf("Expected one listed key with id %s, got %+v", minted.APIKey.ID, keys)
+	}
+
+	revokeReq := httptest.NewRequest("DELETE", "/api/admin/apikeys/"+minted.APIKey.ID, nil)
+	revokeReq = mux.SetURLVars(revokeReq, map[string]string{"id": minted.APIKey.ID})
+	revokeW := httptest.NewRecorder()
+	RevokeAPIKey(revokeW, revokeReq)
+
+	if revokeW.Code != http.StatusOK {
+		t.Fatalf("Expected status 200 for revoke, got %d", revokeW.Code)
+	}
+	if code := callWithBearer(minted.Key, auth.PermissionAttendeesRead); code != http.StatusUnauthorized {
+		t.Errorf("Expected status 401 for revoked key, got %d", code)
+	}
+}
+
+func TestRevokeAPIKey_NotFound(t *testing.T) {
+	setupTestClient(t)
+	defer teardownTestClient()
+
+	req := httptest.NewRequest("DELETE", "/api/admin/apikeys/missing", nil)
+	req = mux.SetURLVars(req, map[string]string{"id": "missing"})
+	w := httptest.NewRecorder()
+	RevokeAPIKey(w, req)
+
+	if w.Code != http.StatusNotFound {
+		t.Errorf("Expected status 404, got %d", w.Code)
+	}
+}
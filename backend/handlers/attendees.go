@@ -2,14 +2,34 @@ package handlers
 
 import (
 	"encoding/json"
+	"log"
 	"net/http"
 	"time"
 
-	"event-registration-backend/firestore"
+	"event-registration-backend/auth"
 	"event-registration-backend/models"
+	"event-registration-backend/store"
+
+	"github.com/gorilla/mux"
 )
 
-// RegisterAttendee handles attendee registration
+// eventCapacity is the number of confirmed seats; 0 means unlimited
+var eventCapacity int
+
+// SetEventCapacity sets the number of confirmed seats, beyond which
+// registrations are waitlisted; 0 means unlimited
+func SetEventCapacity(capacity int) {
+	eventCapacity = capacity
+}
+
+// RegisterAttendee handles attendee registration. Answers to the event's
+// extra registration fields are validated against the form and stored on the
+// attendee. Once the event is full the attendee is waitlisted, which the
+// response reports in status and waitlistPosition. The response and the
+// confirmation email, sent in the background, carry the attendee's manage
+// token. With double opt-in the registration is pending and answered with 202
+// instead; the manage token is emailed once the address is verified, and
+// registering a pending email again only resends its verification email.
 func RegisterAttendee(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
@@ -22,19 +42,20 @@ func RegisterAttendee(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Validate required fields
-	if req.FullName == "" || req.Email == "" || req.Designation == "" {
-		http.Error(w, "Missing required fields", http.StatusBadRequest)
+	form, err := fieldStore.List(r.Context())
+	if err != nil {
+		http.Error(w, "Failed to register attendee", http.StatusInternalServerError)
 		return
 	}
 
-	// Check if email already exists
-	ctx := r.Context()
-	attendeesRef := firestore.GetAttendeesCollection()
-	query := attendeesRef.Where("email", "==", req.Email).Limit(1)
-	docs, err := query.Documents(ctx).GetAll()
-	if err == nil && len(docs) > 0 {
-		http.Error(w, "Email already registered", http.StatusConflict)
+	// Trim and case-fold the input, rejecting invalid fields one by one
+	fields := validateRegisterRequest(&req)
+	answers, answerErrors := validateFieldAnswers(form, req.Fields)
+	for key, msg := range answerErrors {
+		fields[key] = msg
+	}
+	if len(fields) > 0 {
+		writeValidationErrors(w, fields)
 		return
 	}
 
@@ -44,62 +65,161 @@ func RegisterAttendee(w http.ResponseWriter, r *http.Request) {
 		Email:        req.Email,
 		Designation:  req.Designation,
 		RegisteredAt: time.Now(),
+		Fields:       answers,
+	}
+	if verificationRequired {
+		// With double opt-in the registration holds no seat, and gets no
+		// manage token, until it is verified
+		attendee.Status = models.AttendeePending
+		registerPendingAttendee(w, r, &attendee)
+		return
+	}
+
+	// The manage token lets the attendee change or cancel the registration later
+	manageToken, manageTokenHash, err := auth.GenerateManageToken(store.AttendeeID(req.Email))
+	if err != nil {
+		http.Error(w, "Failed to register attendee", http.StatusInternalServerError)
+		return
+	}
+	attendee.ManageTokenHash = manageTokenHash
+
+	// The store rejects a second registration for the same email and assigns
+	// seats atomically
+	err = attendeeStore.Create(r.Context(), &attendee, eventCapacity)
+	if err == store.ErrAlreadyExists {
+		http.Error(w, "Email already registered", http.StatusConflict)
+		return
+	}
+	if err != nil {
+		log.Printf("Failed to register attendee: %v", err)
+		http.Error(w, "Failed to register attendee", http.StatusInternalServerError)
+		return
 	}
+	invalidateResponses(CacheAttendeeCount)
+	attendee.ManageToken = manageToken
+	sendConfirmation(attendee)
 
-	docRef, _, err := attendeesRef.Add(ctx, attendee)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(attendee)
+}
+
+// registerPendingAttendee stores a registration awaiting verification and
+// emails the verification link. If the email already has a pending
+// registration, that one is kept and its link sent again, at most once per
+// verificationResendCooldown, so that registering someone else's address can
+// neither replace their details nor flood their inbox; the response looks the
+// same either way. A pending registration whose link has expired is purged
+// first.
+func registerPendingAttendee(w http.ResponseWriter, r *http.Request, attendee *models.Attendee) {
+	ctx := r.Context()
+	sentAt := attendee.RegisteredAt
+	attendee.VerificationSentAt = &sentAt
+	err := attendeeStore.Create(ctx, attendee, eventCapacity)
+	if err == store.ErrAlreadyExists {
+		var existing *models.Attendee
+		existing, err = attendeeStore.Get(ctx, store.AttendeeID(attendee.Email))
+		switch {
+		case err != nil || existing.Status != models.AttendeePending:
+			err = store.ErrAlreadyExists
+		case time.Since(existing.RegisteredAt) < verificationTTL:
+			var resend bool
+			resend, err = attendeeStore.MarkVerificationSent(ctx, existing.ID, time.Now(), verificationResendCooldown)
+			if err == store.ErrNotFound {
+				// Verified in the meantime
+				err = store.ErrAlreadyExists
+				break
+			}
+			if err != nil {
+				break
+			}
+			if resend {
+				sendVerification(*existing)
+			}
+			attendee.ID = existing.ID
+			writePending(w, attendee)
+			return
+		default:
+			if _, err = PurgeExpiredRegistrations(ctx); err == nil {
+				err = attendeeStore.Create(ctx, attendee, eventCapacity)
+			}
+		}
+	}
+	if err == store.ErrAlreadyExists {
+		http.Error(w, "Email already registered", http.StatusConflict)
+		return
+	}
 	if err != nil {
+		log.Printf("Failed to register attendee: %v", err)
 		http.Error(w, "Failed to register attendee", http.StatusInternalServerError)
 		return
 	}
+	invalidateResponses(CacheAttendeeCount)
+	sendVerification(*attendee)
+	writePending(w, attendee)
+}
 
-	attendee.ID = docRef.GetID()
+// writePending answers a registration that awaits verification with 202
+func writePending(w http.ResponseWriter, attendee *models.Attendee) {
 	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
 	json.NewEncoder(w).Encode(attendee)
 }
 
-// GetAttendeeCount returns the total count of registered attendees
+// GetAttendeeCount returns the confirmed and waitlisted counts and, when the
+// event has a capacity, the remaining seats
 func GetAttendeeCount(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 		return
 	}
 
-	ctx := r.Context()
-	attendeesRef := firestore.GetAttendeesCollection()
-	docs, err := attendeesRef.Documents(ctx).GetAll()
+	counts, err := attendeeStore.CountByStatus(r.Context())
 	if err != nil {
 		http.Error(w, "Failed to get attendee count", http.StatusInternalServerError)
 		return
 	}
 
-	count := len(docs)
+	confirmed, waitlisted := counts[models.AttendeeConfirmed], counts[models.AttendeeWaitlisted]
+	count := models.AttendeeCount{Count: confirmed, Confirmed: confirmed, Waitlisted: waitlisted}
+	if eventCapacity > 0 {
+		remaining := eventCapacity - confirmed
+		if remaining < 0 {
+			remaining = 0
+		}
+		count.Capacity = eventCapacity
+		count.Remaining = &remaining
+	}
+
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(map[string]int{"count": count})
+	json.NewEncoder(w).Encode(count)
 }
 
-// GetAttendees returns all attendees (admin only)
+// GetAttendees returns one page of attendees (admin only), filtered and
+// sorted by the query parameters of parseAttendeeQuery. When more attendees
+// match, the X-Next-Page-Token header carries the pageToken for the next page.
 func GetAttendees(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 		return
 	}
 
-	ctx := r.Context()
-	attendeesRef := firestore.GetAttendeesCollection()
-	docs, err := attendeesRef.Documents(ctx).GetAll()
+	query, fields := parseAttendeeQuery(r.URL.Query())
+	if len(fields) > 0 {
+		writeValidationErrors(w, fields)
+		return
+	}
+
+	// One attendee beyond the page tells whether there is a next one
+	limit := query.Limit
+	query.Limit++
+	attendees, err := attendeeStore.Search(r.Context(), query)
 	if err != nil {
 		http.Error(w, "Failed to get attendees", http.StatusInternalServerError)
 		return
 	}
-
-	var attendees []models.Attendee
-	for _, doc := range docs {
-		var attendee models.Attendee
-		if err := doc.DataTo(&attendee); err != nil {
-			continue
-		}
-		attendee.ID = doc.GetID()
-		attendees = append(attendees, attendee)
+	if len(attendees) > limit {
+		attendees = attendees[:limit]
+		w.Header().Set("X-Next-Page-Token", encodePageToken(query, attendees[limit-1]))
 	}
 
 	// Ensure we return an empty array, not null
@@ -111,31 +231,97 @@ func GetAttendees(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(attendees)
 }
 
-// GetAttendeeStats returns attendee breakdown by designation
+// GetAttendeeStats returns the breakdown of confirmed attendees by
+// designation, or with field=<id> by their answer to that select field. Every
+// option of the field is listed, with 0 when nobody chose it.
 func GetAttendeeStats(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 		return
 	}
 
-	ctx := r.Context()
-	attendeesRef := firestore.GetAttendeesCollection()
-	docs, err := attendeesRef.Documents(ctx).GetAll()
+	id := r.URL.Query().Get("field")
+	if id == "" {
+		stats, err := attendeeStore.CountByDesignation(r.Context(), models.AttendeeConfirmed)
+		if err != nil {
+			http.Error(w, "Failed to get attendee stats", http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(stats)
+		return
+	}
+
+	form, err := fieldStore.List(r.Context())
 	if err != nil {
 		http.Error(w, "Failed to get attendee stats", http.StatusInternalServerError)
 		return
 	}
-
-	stats := make(map[string]int)
-	for _, doc := range docs {
-		var attendee models.Attendee
-		if err := doc.DataTo(&attendee); err != nil {
-			continue
+	var field *models.RegistrationField
+	for i := range form {
+		if form[i].ID == id && form[i].Type == models.FieldSelect {
+			field = &form[i]
+			break
 		}
-		stats[attendee.Designation]++
+	}
+	if field == nil {
+		writeValidationErrors(w, map[string]string{"field": "Unknown select field " + id})
+		return
+	}
+
+	stats, err := attendeeStore.CountByField(r.Context(), models.AttendeeConfirmed, id, field.Options)
+	if err != nil {
+		http.Error(w, "Failed to get attendee stats", http.StatusInternalServerError)
+		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(stats)
 }
 
+// CancelAttendee cancels the registration named in the URL and confirms
+// waitlisted attendees into the freed seat
+func CancelAttendee(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	id := mux.Vars(r)["id"]
+	if id == "" {
+		http.Error(w, "Missing attendee id", http.StatusBadRequest)
+		return
+	}
+
+	ctx := r.Context()
+	before, err := attendeeStore.Get(ctx, id)
+	if err == store.ErrNotFound {
+		http.Error(w, "Attendee not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, "Failed to get attendee", http.StatusInternalServerError)
+		return
+	}
+
+	cancelled, promoted, err := attendeeStore.Cancel(ctx, id, eventCapacity)
+	if err == store.ErrNotFound {
+		http.Error(w, "Attendee is already cancelled", http.StatusConflict)
+		return
+	}
+	if err != nil {
+		log.Printf("Failed to cancel attendee %s: %v", id, err)
+		http.Error(w, "Failed to cancel attendee", http.StatusInternalServerError)
+		return
+	}
+	invalidateResponses(CacheAttendeeCount)
+	recordAudit(r, "attendee", id, auditActionCancel, before, cancelled)
+	for _, attendee := range promoted {
+		log.Printf("Promoted attendee %s from the waitlist", attendee.ID)
+		sendConfirmation(attendee)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(cancelled)
+}
+
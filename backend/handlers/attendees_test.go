@@ -2,12 +2,20 @@ package handlers
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
+	"event-registration-backend/firestore"
 	"event-registration-backend/models"
+	"event-registration-backend/store"
+
+	"github.com/gorilla/mux"
 )
 
 func TestRegisterAttendee_Success(t *testing.T) {
@@ -94,8 +102,7 @@ func TestRegisterAttendee_DuplicateEmail(t *testing.T) {
 	RegisterAttendee(w, req)
 	
 	if w.Code != http.StatusOK {
-		t.Skipf("Skipping duplicate test: first registration failed (likely no Firestore): %d", w.Code)
-		return
+		t.Fatalf("Expected status 200 for first registration, got %d", w.Code)
 	}
 	
 	// Try to register same email again
@@ -109,6 +116,192 @@ func TestRegisterAttendee_DuplicateEmail(t *testing.T) {
 	}
 }
 
+// registerEmail registers an attendee with the given email and returns the status code
+func registerEmail(email string) int {
+	body, _ := json.Marshal(models.RegisterRequest{FullName: "John Doe", Email: email, Designation: "Developer"})
+	req := httptest.NewRequest("POST", "/api/attendees/register", bytes.NewBuffer(body))
+	w := httptest.NewRecorder()
+	RegisterAttendee(w, req)
+	return w.Code
+}
+
+func TestRegisterAttendee_DuplicateEmailDifferentCase(t *testing.T) {
+	setupTestClient(t)
+	defer teardownTestClient()
+
+	if code := registerEmail("case@example.com"); code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", code)
+	}
+	if code := registerEmail(" Case@Example.com "); code != http.StatusConflict {
+		t.Errorf("Expected status 409 for the same email in different case, got %d", code)
+	}
+}
+
+func TestRegisterAttendee_ConcurrentDuplicates(t *testing.T) {
+	setupTestClient(t)
+	defer teardownTestClient()
+
+	const attempts = 10
+	codes := make(chan int, attempts)
+	var wg sync.WaitGroup
+	for i := 0; i < attempts; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			codes <- registerEmail("race@example.com")
+		}()
+	}
+	wg.Wait()
+	close(codes)
+
+	counts := make(map[int]int)
+	for code := range codes {
+		counts[code]++
+	}
+	if counts[http.StatusOK] != 1 || counts[http.StatusConflict] != attempts-1 {
+		t.Errorf("Expected exactly one registration and %d conflicts, got %v", attempts-1, counts)
+	}
+}
+
+func TestRegisterAttendee_StoreError(t *testing.T) {
+	setupTestClient(t)
+	defer teardownTestClient()
+	SetStores(store.Stores{Attendees: failingStore{}, Fields: &firestore.FieldStore{}})
+
+	if code := registerEmail("john@example.com"); code != http.StatusInternalServerError {
+		t.Errorf("Expected status 500 when the store fails, got %d", code)
+	}
+}
+
+// registerAttendee registers an attendee with the given email and returns the stored attendee
+func registerAttendee(t *testing.T, email string) models.Attendee {
+	t.Helper()
+	body, _ := json.Marshal(models.RegisterRequest{FullName: "John Doe", Email: email, Designation: "Developer"})
+	req := httptest.NewRequest("POST", "/api/attendees/register", bytes.NewBuffer(body))
+	w := httptest.NewRecorder()
+	RegisterAttendee(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200 registering %s, got %d: %s", email, w.Code, w.Body.String())
+	}
+	var attendee models.Attendee
+	if err := json.Unmarshal(w.Body.Bytes(), &attendee); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+	return attendee
+}
+
+// fetchAttendeeCount calls GetAttendeeCount
+func fetchAttendeeCount(t *testing.T) models.AttendeeCount {
+	t.Helper()
+	req := httptest.NewRequest("GET", "/api/attendees/count", nil)
+	w := httptest.NewRecorder()
+	GetAttendeeCount(w, req)
+
+	var count models.AttendeeCount
+	if err := json.Unmarshal(w.Body.Bytes(), &count); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+	return count
+}
+
+// cancelAttendee calls CancelAttendee for id and returns the status code
+func cancelAttendee(id string) int {
+	req := httptest.NewRequest("POST", "/api/admin/attendees/"+id+"/cancel", nil)
+	req = mux.SetURLVars(req, map[string]string{"id": id})
+	w := httptest.NewRecorder()
+	CancelAttendee(w, req)
+	return w.Code
+}
+
+func TestRegisterAttendee_Waitlist(t *testing.T) {
+	setupTestClient(t)
+	defer teardownTestClient()
+	SetEventCapacity(2)
+
+	registerAttendee(t, "one@example.com")
+	registerAttendee(t, "two@example.com")
+	third := registerAttendee(t, "three@example.com")
+	fourth := registerAttendee(t, "four@example.com")
+
+	if third.Status != models.AttendeeWaitlisted || third.WaitlistPosition != 1 || fourth.WaitlistPosition != 2 {
+		t.Errorf("Expected third and fourth on the waitlist, got %+v and %+v", third, fourth)
+	}
+	count := fetchAttendeeCount(t)
+	if count.Confirmed != 2 || count.Count != 2 || count.Waitlisted != 2 || count.Capacity != 2 || count.Remaining == nil || *count.Remaining != 0 {
+		t.Errorf("Unexpected count: %+v", count)
+	}
+}
+
+func TestGetAttendeeCount_Unlimited(t *testing.T) {
+	setupTestClient(t)
+	defer teardownTestClient()
+
+	registerAttendee(t, "one@example.com")
+	if count := fetchAttendeeCount(t); count.Count != 1 || count.Remaining != nil || count.Capacity != 0 {
+		t.Errorf("Expected no capacity or remaining seats when unlimited, got %+v", count)
+	}
+}
+
+func TestAttendeeCountsAndStats_ByStatus(t *testing.T) {
+	setupTestClient(t)
+	defer teardownTestClient()
+	SetEventCapacity(2)
+
+	// Registrations stored before statuses existed count as confirmed
+	legacy := map[string]interface{}{"fullName": "Old Timer", "email": "old@example.com", "designation": "Developer", "registeredAt": time.Now()}
+	if _, err := firestore.GetAttendeesCollection().Doc("legacy").Set(context.Background(), legacy); err != nil {
+		t.Fatalf("Failed to store legacy attendee: %v", err)
+	}
+	registerDesignation(t, "one@example.com", "Designer")
+	registerDesignation(t, "two@example.com", "Designer")
+	cancelled := registerDesignation(t, "three@example.com", "Developer")
+	cancelAttendee(cancelled.ID)
+
+	if count := fetchAttendeeCount(t); count.Confirmed != 2 || count.Waitlisted != 1 || *count.Remaining != 0 {
+		t.Errorf("Expected 2 confirmed and 1 waitlisted, got %+v", count)
+	}
+
+	req := httptest.NewRequest("GET", "/api/admin/stats", nil)
+	w := httptest.NewRecorder()
+	GetAttendeeStats(w, req)
+	var stats map[string]int
+	json.Unmarshal(w.Body.Bytes(), &stats)
+	if len(stats) != 2 || stats["Developer"] != 1 || stats["Designer"] != 1 {
+		t.Errorf("Expected 1 confirmed developer and designer, got %v", stats)
+	}
+}
+
+func TestCancelAttendee_PromotesWaitlisted(t *testing.T) {
+	setupTestClient(t)
+	defer teardownTestClient()
+	SetEventCapacity(1)
+
+	first := registerAttendee(t, "one@example.com")
+	second := registerAttendee(t, "two@example.com")
+
+	if code := cancelAttendee(first.ID); code != http.StatusOK {
+		t.Fatalf("Expected status 200 cancelling, got %d", code)
+	}
+	if count := fetchAttendeeCount(t); count.Confirmed != 1 || count.Waitlisted != 0 || *count.Remaining != 0 {
+		t.Errorf("Expected the waitlisted attendee to take the seat, got %+v", count)
+	}
+	promoted, err := attendeeStore.Get(context.Background(), second.ID)
+	if err != nil || promoted.Status != models.AttendeeConfirmed {
+		t.Errorf("Expected second attendee confirmed, got %+v (err %v)", promoted, err)
+	}
+
+	if code := cancelAttendee(first.ID); code != http.StatusConflict {
+		t.Errorf("Expected status 409 cancelling twice, got %d", code)
+	}
+	if code := cancelAttendee("missing"); code != http.StatusNotFound {
+		t.Errorf("Expected status 404 for unknown attendee, got %d", code)
+	}
+	if code := registerEmail("one@example.com"); code != http.StatusOK {
+		t.Errorf("Expected a cancelled attendee to register again, got %d", code)
+	}
+}
+
 func TestRegisterAttendee_InvalidJSON(t *testing.T) {
 	setupTestClient(t)
 	defer teardownTestClient()
@@ -213,6 +406,100 @@ func TestGetAttendees_Empty(t *testing.T) {
 	}
 }
 
+// fetchAttendeePage calls GetAttendees with query
+func fetchAttendeePage(t *testing.T, query string) ([]models.Attendee, string) {
+	t.Helper()
+	req := httptest.NewRequest("GET", "/api/admin/attendees"+query, nil)
+	w := httptest.NewRecorder()
+	GetAttendees(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200 for %s, got %d: %s", query, w.Code, w.Body.String())
+	}
+	var attendees []models.Attendee
+	if err := json.Unmarshal(w.Body.Bytes(), &attendees); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+	return attendees, w.Header().Get("X-Next-Page-Token")
+}
+
+func TestGetAttendees_Pagination(t *testing.T) {
+	setupTestClient(t)
+	defer teardownTestClient()
+
+	for _, email := range []string{"e@example.com", "c@example.com", "a@example.com", "d@example.com", "b@example.com"} {
+		registerAttendee(t, email)
+	}
+
+	for _, order := range []string{"asc", "desc"} {
+		var emails []string
+		query := "?sort=email&limit=2&order=" + order
+		for pages := 0; ; pages++ {
+			if pages == 3 {
+				t.Fatalf("%s: expected 3 pages", order)
+			}
+			page, next := fetchAttendeePage(t, query)
+			for _, attendee := range page {
+				emails = append(emails, attendee.Email[:1])
+			}
+			if next == "" {
+				break
+			}
+			query = "?sort=email&limit=2&order=" + order + "&pageToken=" + next
+		}
+		expected := "abcde"
+		if order == "desc" {
+			expected = "edcba"
+		}
+		if got := strings.Join(emails, ""); got != expected {
+			t.Errorf("%s: expected %s, got %s", order, expected, got)
+		}
+	}
+
+	// Registration order is the default
+	page, _ := fetchAttendeePage(t, "")
+	if len(page) != 5 || page[0].Email != "e@example.com" || page[4].Email != "b@example.com" {
+		t.Errorf("Expected all attendees in registration order, got %+v", page)
+	}
+}
+
+func TestGetAttendees_SearchAndFilter(t *testing.T) {
+	setupTestClient(t)
+	defer teardownTestClient()
+
+	registerDesignation(t, "ada@lovelace.dev", "Developer")
+	registerDesignation(t, "bob@example.com", "Developer")
+	registerDesignation(t, "cat@lovelace.dev", "Designer")
+
+	page, next := fetchAttendeePage(t, "?q=LOVELACE&designation=developer")
+	if len(page) != 1 || page[0].Email != "ada@lovelace.dev" || next != "" {
+		t.Errorf("Expected only ada on a single page, got %+v", page)
+	}
+	if page, _ := fetchAttendeePage(t, "?registeredTo=2000-01-01"); len(page) != 0 {
+		t.Errorf("Expected no attendees registered before 2000, got %d", len(page))
+	}
+}
+
+func TestGetAttendees_InvalidParameters(t *testing.T) {
+	setupTestClient(t)
+	defer teardownTestClient()
+
+	registerAttendee(t, "a@example.com")
+	registerAttendee(t, "b@example.com")
+	_, next := fetchAttendeePage(t, "?limit=1")
+
+	for _, query := range []string{
+		"?sort=designation", "?order=sideways", "?limit=0", "?limit=5000",
+		"?pageToken=not-a-token", "?sort=email&pageToken=" + next,
+	} {
+		req := httptest.NewRequest("GET", "/api/admin/attendees"+query, nil)
+		w := httptest.NewRecorder()
+		GetAttendees(w, req)
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("%s: expected status 400, got %d", query, w.Code)
+		}
+	}
+}
+
 func TestGetAttendeeStats(t *testing.T) {
 	setupTestClient(t)
 	defer teardownTestClient()
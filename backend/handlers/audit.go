@@ -0,0 +1,193 @@
+package handlers
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"reflect"
+	"strconv"
+	"time"
+
+	"event-registration-backend/firestore"
+	"event-registration-backend/models"
+)
+
+const (
+	auditActionCreate  = "create"
+	auditActionUpdate  = "update"
+	auditActionRevoke  = "revoke"
+	auditActionCancel  = "cancel"
+	auditActionCheckIn = "checkin"
+	auditActionImport  = "import"
+
+	// defaultAuditLimit caps GET /api/admin/audit when no limit is given
+	defaultAuditLimit = 100
+	// maxAuditLimit is the largest page GET /api/admin/audit returns
+	maxAuditLimit = 1000
+)
+
+// recordAudit appends an audit entry for a completed mutation. before is nil
+// for creations. Failures are logged rather than failing the request, since the
+// mutation itself has already been written.
+func recordAudit(r *http.Request, entity, entityID, action string, before, after interface{}) {
+	actor := "anonymous"
+	if claims, ok := AdminClaimsFromContext(r.Context()); ok {
+		actor = claims.Subject
+	}
+
+	entry := models.AuditEntry{
+		Actor:     actor,
+		Timestamp: time.Now(),
+		Entity:    entity,
+		EntityID:  entityID,
+		Action:    action,
+		Changes:   diffFields(before, after),
+		IP:        clientIP(r),
+	}
+	if _, _, err := firestore.GetAuditLogCollection().Add(r.Context(), entry); err != nil {
+		log.Printf("Failed to write audit entry for %s %s %s by %s: %v", action, entity, entityID, actor, err)
+	}
+}
+
+// diffFields compares the JSON representations of before and after and
+// returns the fields whose values differ. The id field is ignored.
+func diffFields(before, after interface{}) map[string]models.FieldChange {
+	beforeFields := jsonFields(before)
+	afterFields := jsonFields(after)
+
+	changes := make(map[string]models.FieldChange)
+	for name, value := range afterFields {
+		if old, ok := beforeFields[name]; !ok || !reflect.DeepEqual(old, value) {
+			changes[name] = models.FieldChange{Before: beforeFields[name], After: value}
+		}
+	}
+	for name, old := range beforeFields {
+		if _, ok := afterFields[name]; !ok {
+			changes[name] = models.FieldChange{Before: old}
+		}
+	}
+	delete(changes, "id")
+	return changes
+}
+
+// jsonFields flattens v to its top-level JSON fields
+func jsonFields(v interface{}) map[string]interface{} {
+	fields := make(map[string]interface{})
+	if v == nil || (reflect.ValueOf(v).Kind() == reflect.Ptr && reflect.ValueOf(v).IsNil()) {
+		return fields
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fields
+	}
+	json.Unmarshal(data, &fields)
+	return fields
+}
+
+// GetAuditLog returns one page of audit entries, newest first, filtered by
+// the optional query parameters entity, entityId, actor, from and to (RFC
+// 3339). limit sets the page size; when more entries match, the
+// X-Next-Page-Token header carries the pageToken for the next page.
+func GetAuditLog(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	q := r.URL.Query()
+	query := firestore.GetAuditLogCollection()
+	for _, field := range []string{"entity", "entityId", "actor"} {
+		if v := q.Get(field); v != "" {
+			query = query.Where(field, "==", v)
+		}
+	}
+	if v := q.Get("from"); v != "" {
+		from, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			http.Error(w, "Invalid from time, expected RFC 3339", http.StatusBadRequest)
+			return
+		}
+		query = query.Where("timestamp", ">=", from)
+	}
+	if v := q.Get("to"); v != "" {
+		to, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			http.Error(w, "Invalid to time, expected RFC 3339", http.StatusBadRequest)
+			return
+		}
+		query = query.Where("timestamp", "<=", to)
+	}
+	limit := defaultAuditLimit
+	if v := q.Get("limit"); v != "" {
+		var err error
+		if limit, err = strconv.Atoi(v); err != nil || limit <= 0 || limit > maxAuditLimit {
+			http.Error(w, fmt.Sprintf("Invalid limit, expected 1 to %d", maxAuditLimit), http.StatusBadRequest)
+			return
+		}
+	}
+
+	// Entries with the same timestamp are ordered by ID so pages never skip
+	// or repeat one
+	query = query.OrderBy("timestamp", firestore.Desc).OrderBy(firestore.DocumentID, firestore.Desc)
+	if v := q.Get("pageToken"); v != "" {
+		timestamp, id, ok := decodeAuditPageToken(v)
+		if !ok {
+			http.Error(w, "Invalid page token", http.StatusBadRequest)
+			return
+		}
+		query = query.StartAfter(timestamp, id)
+	}
+
+	// One entry beyond the page tells whether there is a next one
+	docs, err := query.Limit(limit + 1).Documents(r.Context()).GetAll()
+	if err != nil {
+		http.Error(w, "Failed to get audit log", http.StatusInternalServerError)
+		return
+	}
+
+	more := len(docs) > limit
+	if more {
+		docs = docs[:limit]
+	}
+	entries := make([]models.AuditEntry, 0, len(docs))
+	for _, doc := range docs {
+		var entry models.AuditEntry
+		if err := doc.DataTo(&entry); err != nil {
+			http.Error(w, "Failed to get audit log", http.StatusInternalServerError)
+			return
+		}
+		entry.ID = doc.GetID()
+		entries = append(entries, entry)
+	}
+	if more {
+		last := entries[len(entries)-1]
+		w.Header().Set("X-Next-Page-Token", encodeAuditPageToken(last.Timestamp, last.ID))
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(entries)
+}
+
+// encodeAuditPageToken returns the token continuing the audit log after the
+// entry with timestamp and id, in the attendee listing's token format
+func encodeAuditPageToken(timestamp time.Time, id string) string {
+	token := pageToken{Sort: "timestamp", Descending: true, Key: timestamp.UTC().Format(time.RFC3339Nano), ID: id}
+	data, _ := json.Marshal(token)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// decodeAuditPageToken returns the position recorded by encodeAuditPageToken
+func decodeAuditPageToken(v string) (time.Time, string, bool) {
+	var token pageToken
+	data, err := base64.RawURLEncoding.DecodeString(v)
+	if err != nil || json.Unmarshal(data, &token) != nil || token.ID == "" || token.Sort != "timestamp" {
+		return time.Time{}, "", false
+	}
+	timestamp, err := time.Parse(time.RFC3339Nano, token.Key)
+	if err != nil {
+		return time.Time{}, "", false
+	}
+	return timestamp, token.ID, true
+}
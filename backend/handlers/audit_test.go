@@ -0,0 +1,149 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"event-registration-backend/auth"
+	"event-registration-backend/models"
+)
+
+// saveSpeakerAs calls CreateOrUpdateSpeaker with the given bearer token
+func saveSpeakerAs(t *testing.T, token string, speaker models.SpeakerRequest) models.Speaker {
+	t.Helper()
+	body, _ := json.Marshal(speaker)
+	req := httptest.NewRequest("POST", "/api/admin/speakers", bytes.NewBuffer(body))
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.RemoteAddr = "203.0.113.7:4711"
+	w := httptest.NewRecorder()
+	RequireAdmin(RequirePermission(auth.PermissionSpeakersWrite, CreateOrUpdateSpeaker)).ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200 saving speaker, got %d: %s", w.Code, w.Body.String())
+	}
+	var saved models.Speaker
+	if err := json.Unmarshal(w.Body.Bytes(), &saved); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+	return saved
+}
+
+// fetchAuditLog calls GetAuditLog with the given query string
+func fetchAuditLog(t *testing.T, query string) (*httptest.ResponseRecorder, []models.AuditEntry) {
+	t.Helper()
+	req := httptest.NewRequest("GET", "/api/admin/audit?"+query, nil)
+	w := httptest.NewRecorder()
+	GetAuditLog(w, req)
+
+	var entries []models.AuditEntry
+	if w.Code == http.StatusOK {
+		if err := json.Unmarshal(w.Body.Bytes(), &entries); err != nil {
+			t.Fatalf("Failed to unmarshal response: %v", err)
+		}
+	}
+	return w, entries
+}
+
+func TestAuditLog_RecordsSpeakerChanges(t *testing.T) {
+	setupTestClient(t)
+	defer teardownTestClient()
+	createTestAdminWithRole(t, "alice", "testpassword123", auth.RoleEditor)
+	token := loginToken(t, "alice", "testpassword123")
+
+	created := saveSpeakerAs(t, token, models.SpeakerRequest{Name: "Ada", Bio: "Engineer"})
+	saveSpeakerAs(t, token, models.SpeakerRequest{ID: created.ID, Name: "Ada", Bio: "Mathematician"})
+
+	_, entries := fetchAuditLog(t, "entity=speaker&entityId="+created.ID)
+	if len(entries) != 2 {
+		t.Fatalf("Expected 2 audit entries, got %d", len(entries))
+	}
+
+	update := entries[0]
+	if update.Action != auditActionUpdate || update.Actor != "alice" || update.IP != "203.0.113.7" {
+		t.Errorf("Unexpected update entry: %+v", update)
+	}
+	if len(update.Changes) != 1 {
+		t.Fatalf("Expected only bio to change, got %+v", update.Changes)
+	}
+	if change := update.Changes["bio"]; change.Before != "Engineer" || change.After != "Mathematician" {
+		t.Errorf("Expected bio change Engineer -> Mathematician, got %+v", change)
+	}
+
+	if create := entries[1]; create.Action != auditActionCreate || create.Changes["name"].After != "Ada" {
+		t.Errorf("Unexpected create entry: %+v", create)
+	}
+}
+
+func TestAuditLog_Filters(t *testing.T) {
+	setupTestClient(t)
+	defer teardownTestClient()
+	createTestAdmin(t, "alice", "testpassword123")
+	createTestAdminWithRole(t, "bob", "testpassword123", auth.RoleEditor)
+
+	saveSpeakerAs(t, loginToken(t, "alice", "testpassword123"), models.SpeakerRequest{Name: "Ada"})
+	saveSpeakerAs(t, loginToken(t, "bob", "testpassword123"), models.SpeakerRequest{Name: "Grace"})
+
+	if _, entries := fetchAuditLog(t, "actor=bob"); len(entries) != 1 || entries[0].Changes["name"].After != "Grace" {
+		t.Errorf("Expected one entry by bob, got %+v", entries)
+	}
+
+	future := time.Now().Add(time.Hour).UTC().Format(time.RFC3339)
+	if _, entries := fetchAuditLog(t, "from="+future); len(entries) != 0 {
+		t.Errorf("Expected no entries after %s, got %d", future, len(entries))
+	}
+	if _, entries := fetchAuditLog(t, "entity=speaker&to="+future); len(entries) != 2 {
+		t.Errorf("Expected 2 entries before %s, got %d", future, len(entries))
+	}
+	if _, entries := fetchAuditLog(t, "entity=speaker&limit=1"); len(entries) != 1 {
+		t.Errorf("Expected limit to cap entries at 1, got %d", len(entries))
+	}
+}
+
+func TestAuditLog_Pagination(t *testing.T) {
+	setupTestClient(t)
+	defer teardownTestClient()
+	for _, name := range []string{"Ada", "Grace", "Hedy", "Katherine", "Radia"} {
+		saveSpeaker(t, models.SpeakerRequest{Name: name})
+	}
+
+	var names []string
+	token := ""
+	for page := 0; page < 5; page++ {
+		w, entries := fetchAuditLog(t, "entity=speaker&limit=2&pageToken="+token)
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+		}
+		for _, entry := range entries {
+			names = append(names, entry.Changes["name"].After.(string))
+		}
+		if token = w.Header().Get("X-Next-Page-Token"); token == "" {
+			break
+		}
+	}
+
+	expected := []string{"Radia", "Katherine", "Hedy", "Grace", "Ada"}
+	if strings.Join(names, ",") != strings.Join(expected, ",") {
+		t.Errorf("Expected pages newest first %v, got %v", expected, names)
+	}
+
+	if w, _ := fetchAuditLog(t, "pageToken=garbage"); w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400 for an invalid page token, got %d", w.Code)
+	}
+	if w, _ := fetchAuditLog(t, "limit=100000"); w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400 for a limit above the maximum, got %d", w.Code)
+	}
+}
+
+func TestAuditLog_InvalidTime(t *testing.T) {
+	setupTestClient(t)
+	defer teardownTestClient()
+
+	if w, _ := fetchAuditLog(t, "from=yesterday"); w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400 for invalid from time, got %d", w.Code)
+	}
+}
@@ -0,0 +1,61 @@
+package handlers
+
+import (
+	"log"
+	"net/http"
+	"sort"
+	"strings"
+
+	"event-registration-backend/badges"
+	"event-registration-backend/models"
+)
+
+// GetAttendeeBadges renders printable name badges with QR tickets as a PDF.
+// It takes the filters of parseAttendeeFilter; without a status filter only
+// confirmed attendees get a badge. Badges are sorted by name.
+func GetAttendeeBadges(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	filter, fields := parseAttendeeFilter(r.URL.Query())
+	if len(fields) > 0 {
+		writeValidationErrors(w, fields)
+		return
+	}
+	if len(filter.Statuses) == 0 {
+		filter.Statuses = []string{models.AttendeeConfirmed}
+	}
+
+	attendees, err := attendeeStore.List(r.Context())
+	if err != nil {
+		http.Error(w, "Failed to get attendees", http.StatusInternalServerError)
+		return
+	}
+	attendees = filter.Apply(attendees)
+	sort.SliceStable(attendees, func(i, j int) bool {
+		return strings.ToLower(attendees[i].FullName) < strings.ToLower(attendees[j].FullName)
+	})
+
+	entries := make([]badges.Badge, 0, len(attendees))
+	for _, attendee := range attendees {
+		badge := badges.Badge{Name: attendee.FullName, Designation: attendee.Designation}
+		// Only confirmed attendees have a ticket to check in with
+		if attendee.Status == models.AttendeeConfirmed {
+			if badge.QR, err = ticketPNG(attendee.ID); err != nil {
+				log.Printf("Failed to render ticket for attendee %s: %v", attendee.ID, err)
+				http.Error(w, "Failed to render badges", http.StatusInternalServerError)
+				return
+			}
+		}
+		entries = append(entries, badge)
+	}
+
+	w.Header().Set("Content-Type", "application/pdf")
+	w.Header().Set("Content-Disposition", `attachment; filename="badges.pdf"`)
+	if err := badges.Render(w, eventDetails.Name, entries); err != nil {
+		log.Printf("Failed to render badges: %v", err)
+		http.Error(w, "Failed to render badges", http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,61 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"event-registration-backend/models"
+)
+
+// registerDesignation registers email with designation and returns the attendee
+func registerDesignation(t *testing.T, email, designation string) models.Attendee {
+	t.Helper()
+	body, _ := json.Marshal(models.RegisterRequest{FullName: "Jane Doe", Email: email, Designation: designation})
+	req := httptest.NewRequest("POST", "/api/attendees/register", bytes.NewBuffer(body))
+	w := httptest.NewRecorder()
+	RegisterAttendee(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200 registering %s, got %d", email, w.Code)
+	}
+	var attendee models.Attendee
+	json.Unmarshal(w.Body.Bytes(), &attendee)
+	return attendee
+}
+
+func TestGetAttendeeBadges(t *testing.T) {
+	setupTestClient(t)
+	defer teardownTestClient()
+
+	registerDesignation(t, "ada@example.com", "Developer")
+	registerDesignation(t, "bob@example.com", "Designer")
+	registerDesignation(t, "cat@example.com", "developer")
+
+	badges := func(query string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", "/api/admin/attendees/badges"+query, nil)
+		w := httptest.NewRecorder()
+		GetAttendeeBadges(w, req)
+		return w
+	}
+
+	w := badges("")
+	if w.Code != http.StatusOK || w.Header().Get("Content-Type") != "application/pdf" {
+		t.Fatalf("Expected a PDF, got %d %q", w.Code, w.Header().Get("Content-Type"))
+	}
+	if images := strings.Count(w.Body.String(), "/Subtype /Image"); images != 3 {
+		t.Errorf("Expected 3 badges with QR tickets, got %d", images)
+	}
+
+	if images := strings.Count(badges("?designation=Developer").Body.String(), "/Subtype /Image"); images != 2 {
+		t.Errorf("Expected 2 developer badges, got %d", images)
+	}
+	if images := strings.Count(badges("?registeredTo=2000-01-01").Body.String(), "/Subtype /Image"); images != 0 {
+		t.Errorf("Expected no badges for registrations before 2000, got %d", images)
+	}
+	if w := badges("?registeredFrom=yesterday"); w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400 for an invalid date, got %d", w.Code)
+	}
+}
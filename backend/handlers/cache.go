@@ -0,0 +1,149 @@
+package handlers
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Names of the cached public responses. Handlers that change the data behind
+// one invalidate it.
+const (
+	CacheSessions           = "sessions"
+	CacheSpeakers           = "speakers"
+	CacheAttendeeCount      = "attendeeCount"
+	CacheRegistrationFields = "registrationFields"
+)
+
+// responseCache holds rendered public responses by name
+var responseCache = struct {
+	sync.Mutex
+	ttl     time.Duration
+	entries map[string]cachedResponse
+	// generations counts invalidations per name, so a response rendered
+	// before an invalidation is not stored after it
+	generations map[string]uint64
+}{
+	entries:     make(map[string]cachedResponse),
+	generations: make(map[string]uint64),
+}
+
+type cachedResponse struct {
+	header  http.Header
+	body    []byte
+	etag    string
+	expires time.Time
+}
+
+// SetResponseCacheTTL sets how long public read responses are served from
+// memory; 0 disables caching. Cached responses are dropped.
+func SetResponseCacheTTL(ttl time.Duration) {
+	responseCache.Lock()
+	defer responseCache.Unlock()
+	responseCache.ttl = ttl
+	for name := range responseCache.entries {
+		delete(responseCache.entries, name)
+		responseCache.generations[name]++
+	}
+}
+
+// invalidateResponses drops the named cached responses after the data behind
+// them changes
+func invalidateResponses(names ...string) {
+	responseCache.Lock()
+	defer responseCache.Unlock()
+	for _, name := range names {
+		delete(responseCache.entries, name)
+		responseCache.generations[name]++
+	}
+}
+
+// CacheResponse serves next's successful responses from memory under name
+// until the TTL passes or the response is invalidated. Responses carry an
+// ETag and Cache-Control no-cache, so clients revalidate every time and see a
+// change as soon as it invalidates the response; a request whose
+// If-None-Match holds the current ETag gets 304 Not Modified without a body.
+func CacheResponse(name string, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		responseCache.Lock()
+		ttl := responseCache.ttl
+		entry, ok := responseCache.entries[name]
+		generation := responseCache.generations[name]
+		responseCache.Unlock()
+
+		if ttl <= 0 || r.Method != http.MethodGet {
+			next(w, r)
+			return
+		}
+
+		now := time.Now()
+		if !ok || now.After(entry.expires) {
+			rec := &bufferedResponse{header: make(http.Header), status: http.StatusOK}
+			next(rec, r)
+			if rec.status != http.StatusOK {
+				rec.copyTo(w)
+				return
+			}
+			sum := sha256.Sum256(rec.body.Bytes())
+			entry = cachedResponse{
+				header:  rec.header,
+				body:    rec.body.Bytes(),
+				etag:    `"` + hex.EncodeToString(sum[:16]) + `"`,
+				expires: now.Add(ttl),
+			}
+			responseCache.Lock()
+			if responseCache.generations[name] == generation {
+				responseCache.entries[name] = entry
+			}
+			responseCache.Unlock()
+		}
+
+		for key, values := range entry.header {
+			w.Header()[key] = append([]string(nil), values...)
+		}
+		w.Header().Set("ETag", entry.etag)
+		w.Header().Set("Cache-Control", "public, no-cache")
+		if etagMatches(r.Header.Get("If-None-Match"), entry.etag) {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Write(entry.body)
+	}
+}
+
+// etagMatches reports whether an If-None-Match header lists etag
+func etagMatches(ifNoneMatch, etag string) bool {
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == etag || candidate == "*" {
+			return true
+		}
+	}
+	return false
+}
+
+// bufferedResponse captures a handler's response so it can be cached
+type bufferedResponse struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+}
+
+func (b *bufferedResponse) Header() http.Header { return b.header }
+
+func (b *bufferedResponse) Write(p []byte) (int, error) { return b.body.Write(p) }
+
+func (b *bufferedResponse) WriteHeader(status int) { b.status = status }
+
+// copyTo sends the captured response to w unchanged
+func (b *bufferedResponse) copyTo(w http.ResponseWriter) {
+	for key, values := range b.header {
+		w.Header()[key] = values
+	}
+	w.WriteHeader(b.status)
+	w.Write(b.body.Bytes())
+}
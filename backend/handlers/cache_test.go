@@ -0,0 +1,127 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"event-registration-backend/firestore"
+	"event-registration-backend/models"
+	"event-registration-backend/store"
+)
+
+// getCached calls handler through CacheResponse under name
+func getCached(name string, handler http.HandlerFunc, ifNoneMatch string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest("GET", "/api/"+name, nil)
+	if ifNoneMatch != "" {
+		req.Header.Set("If-None-Match", ifNoneMatch)
+	}
+	w := httptest.NewRecorder()
+	CacheResponse(name, handler)(w, req)
+	return w
+}
+
+// saveSpeaker calls CreateOrUpdateSpeaker
+func saveSpeaker(t *testing.T, speaker models.SpeakerRequest) {
+	t.Helper()
+	body, _ := json.Marshal(speaker)
+	w := httptest.NewRecorder()
+	CreateOrUpdateSpeaker(w, httptest.NewRequest("POST", "/api/admin/speakers", bytes.NewBuffer(body)))
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200 saving speaker, got %d", w.Code)
+	}
+}
+
+func TestCacheResponse_ServesFromMemoryUntilInvalidated(t *testing.T) {
+	setupTestClient(t)
+	defer teardownTestClient()
+	SetResponseCacheTTL(time.Minute)
+
+	saveSpeaker(t, models.SpeakerRequest{Name: "Ada"})
+	if w := getCached(CacheSpeakers, GetSpeakers, ""); !strings.Contains(w.Body.String(), "Ada") {
+		t.Fatalf("Expected Ada in the speakers, got %s", w.Body.String())
+	}
+
+	// A write that bypasses the handlers is not seen until the entry is invalidated
+	if err := speakerStore.Create(context.Background(), &models.Speaker{Name: "Bob"}); err != nil {
+		t.Fatalf("Failed to create speaker: %v", err)
+	}
+	if w := getCached(CacheSpeakers, GetSpeakers, ""); strings.Contains(w.Body.String(), "Bob") {
+		t.Error("Expected the cached speakers without Bob")
+	}
+
+	saveSpeaker(t, models.SpeakerRequest{Name: "Cat"})
+	if w := getCached(CacheSpeakers, GetSpeakers, ""); !strings.Contains(w.Body.String(), "Bob") || !strings.Contains(w.Body.String(), "Cat") {
+		t.Errorf("Expected saving a speaker to refresh the speakers, got %s", w.Body.String())
+	}
+}
+
+func TestCacheResponse_ETag(t *testing.T) {
+	setupTestClient(t)
+	defer teardownTestClient()
+	SetResponseCacheTTL(time.Minute)
+
+	w := getCached(CacheAttendeeCount, GetAttendeeCount, "")
+	etag := w.Header().Get("ETag")
+	if etag == "" || w.Header().Get("Cache-Control") != "public, no-cache" {
+		t.Fatalf("Expected an ETag and no-cache, got %q and %q", etag, w.Header().Get("Cache-Control"))
+	}
+	if w.Header().Get("Content-Type") != "application/json" {
+		t.Errorf("Expected the handler's headers, got %v", w.Header())
+	}
+
+	w = getCached(CacheAttendeeCount, GetAttendeeCount, etag)
+	if w.Code != http.StatusNotModified || w.Body.Len() != 0 {
+		t.Errorf("Expected 304 with no body for a matching ETag, got %d %q", w.Code, w.Body.String())
+	}
+
+	// A registration changes the count and so the ETag
+	registerAttendee(t, "ada@example.com")
+	w = getCached(CacheAttendeeCount, GetAttendeeCount, etag)
+	if w.Code != http.StatusOK || w.Header().Get("ETag") == etag {
+		t.Errorf("Expected a fresh count after registering, got %d with ETag %q", w.Code, w.Header().Get("ETag"))
+	}
+}
+
+func TestCacheResponse_ExpiresAfterTTL(t *testing.T) {
+	setupTestClient(t)
+	defer teardownTestClient()
+	SetResponseCacheTTL(20 * time.Millisecond)
+
+	getCached(CacheSpeakers, GetSpeakers, "")
+	speakerStore.Create(context.Background(), &models.Speaker{Name: "Bob"})
+	time.Sleep(30 * time.Millisecond)
+	if w := getCached(CacheSpeakers, GetSpeakers, ""); !strings.Contains(w.Body.String(), "Bob") {
+		t.Error("Expected the expired entry to be reloaded")
+	}
+}
+
+func TestCacheResponse_DoesNotCacheErrors(t *testing.T) {
+	setupTestClient(t)
+	defer teardownTestClient()
+	SetResponseCacheTTL(time.Minute)
+
+	SetStores(store.Stores{Attendees: failingStore{}})
+	if w := getCached(CacheAttendeeCount, GetAttendeeCount, ""); w.Code != http.StatusInternalServerError || w.Header().Get("ETag") != "" {
+		t.Fatalf("Expected an uncached 500, got %d with ETag %q", w.Code, w.Header().Get("ETag"))
+	}
+	SetStores(firestore.NewStores())
+	if w := getCached(CacheAttendeeCount, GetAttendeeCount, ""); w.Code != http.StatusOK {
+		t.Errorf("Expected the store to be asked again, got %d", w.Code)
+	}
+}
+
+func TestCacheResponse_Disabled(t *testing.T) {
+	setupTestClient(t)
+	defer teardownTestClient()
+
+	w := getCached(CacheSpeakers, GetSpeakers, "")
+	if w.Code != http.StatusOK || w.Header().Get("ETag") != "" || w.Header().Get("Cache-Control") != "" {
+		t.Errorf("Expected no caching headers with a TTL of 0, got %v", w.Header())
+	}
+}
@@ -0,0 +1,144 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"event-registration-backend/auth"
+	"event-registration-backend/models"
+	"event-registration-backend/store"
+
+	"github.com/gorilla/mux"
+	"github.com/skip2/go-qrcode"
+)
+
+const (
+	// ticketSize is the width and height of a ticket image in pixels
+	ticketSize = 320
+	// ticketContentID names the ticket image inline in confirmation emails
+	ticketContentID = "ticket"
+)
+
+// ticketPNG renders the QR ticket of the attendee with id
+func ticketPNG(id string) ([]byte, error) {
+	code, err := auth.IssueTicket(sessionSecret, id)
+	if err != nil {
+		return nil, err
+	}
+	return qrcode.Encode(code, qrcode.Medium, ticketSize)
+}
+
+// writeTicket responds with the QR ticket of attendee. Only confirmed
+// attendees have a ticket.
+func writeTicket(w http.ResponseWriter, attendee *models.Attendee) {
+	if attendee.Status != models.AttendeeConfirmed {
+		http.Error(w, "Only confirmed registrations have a ticket", http.StatusConflict)
+		return
+	}
+	png, err := ticketPNG(attendee.ID)
+	if err != nil {
+		log.Printf("Failed to render ticket for attendee %s: %v", attendee.ID, err)
+		http.Error(w, "Failed to render ticket", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "image/png")
+	w.Header().Set("Cache-Control", "private, no-store")
+	w.Write(png)
+}
+
+// GetRegistrationTicket returns the QR ticket belonging to a manage token as a PNG
+func GetRegistrationTicket(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	attendee := manageAttendee(w, r)
+	if attendee == nil {
+		return
+	}
+	writeTicket(w, attendee)
+}
+
+// GetAttendeeTicket returns the QR ticket of the attendee named in the URL as a PNG
+func GetAttendeeTicket(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	attendee, err := attendeeStore.Get(r.Context(), mux.Vars(r)["id"])
+	if err == store.ErrNotFound {
+		http.Error(w, "Attendee not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, "Failed to get attendee", http.StatusInternalServerError)
+		return
+	}
+	writeTicket(w, attendee)
+}
+
+// CheckInAttendee validates the code scanned from a QR ticket and records the
+// attendee's arrival. Each ticket checks in once; a second scan is rejected
+// with the time of the first.
+func CheckInAttendee(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req models.CheckInRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	id, err := auth.ParseTicket(sessionSecret, req.Code)
+	if err != nil {
+		http.Error(w, "Invalid ticket", http.StatusBadRequest)
+		return
+	}
+
+	attendee, err := attendeeStore.CheckIn(r.Context(), id, time.Now())
+	if err == store.ErrNotFound {
+		http.Error(w, "No confirmed registration for this ticket", http.StatusNotFound)
+		return
+	}
+	if err == store.ErrAlreadyCheckedIn {
+		http.Error(w, fmt.Sprintf("%s already checked in at %s", attendee.FullName, attendee.CheckedInAt.Format(time.Kitchen)), http.StatusConflict)
+		return
+	}
+	if err != nil {
+		log.Printf("Failed to check in attendee %s: %v", id, err)
+		http.Error(w, "Failed to check in attendee", http.StatusInternalServerError)
+		return
+	}
+	before := *attendee
+	before.CheckedInAt = nil
+	recordAudit(r, "attendee", id, auditActionCheckIn, &before, attendee)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(attendee)
+}
+
+// GetCheckInStats returns how many confirmed attendees have checked in, in
+// total and by designation
+func GetCheckInStats(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	stats, err := attendeeStore.CheckInStats(r.Context())
+	if err != nil {
+		log.Printf("Failed to get check-in stats: %v", err)
+		http.Error(w, "Failed to get check-in stats", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(stats)
+}
@@ -0,0 +1,167 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"image/png"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"event-registration-backend/auth"
+	"event-registration-backend/mailer"
+	"event-registration-backend/models"
+
+	"github.com/gorilla/mux"
+)
+
+// checkIn posts code to CheckInAttendee
+func checkIn(code string) *httptest.ResponseRecorder {
+	body, _ := json.Marshal(models.CheckInRequest{Code: code})
+	req := httptest.NewRequest("POST", "/api/admin/checkin", bytes.NewBuffer(body))
+	w := httptest.NewRecorder()
+	CheckInAttendee(w, req)
+	return w
+}
+
+// ticketCode returns the code encoded in the QR ticket of the attendee with id
+func ticketCode(t *testing.T, id string) string {
+	t.Helper()
+	code, err := auth.IssueTicket(sessionSecret, id)
+	if err != nil {
+		t.Fatalf("Failed to issue ticket: %v", err)
+	}
+	return code
+}
+
+func TestCheckInAttendee(t *testing.T) {
+	setupTestClient(t)
+	defer teardownTestClient()
+
+	attendee := registerAttendee(t, "ada@example.com")
+	w := checkIn(ticketCode(t, attendee.ID))
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var checkedIn models.Attendee
+	json.Unmarshal(w.Body.Bytes(), &checkedIn)
+	if checkedIn.CheckedInAt == nil {
+		t.Errorf("Expected checkedInAt to be recorded, got %+v", checkedIn)
+	}
+
+	if w := checkIn(ticketCode(t, attendee.ID)); w.Code != http.StatusConflict {
+		t.Errorf("Expected status 409 for a second scan, got %d", w.Code)
+	}
+}
+
+func TestCheckInAttendee_Rejected(t *testing.T) {
+	setupTestClient(t)
+	defer teardownTestClient()
+	SetEventCapacity(1)
+
+	registerAttendee(t, "ada@example.com")
+	waitlisted := registerAttendee(t, "bob@example.com")
+
+	testCases := map[string]struct {
+		code     string
+		expected int
+	}{
+		"forged":     {waitlisted.ID + ".forged", http.StatusBadRequest},
+		"empty":      {"", http.StatusBadRequest},
+		"waitlisted": {ticketCode(t, waitlisted.ID), http.StatusNotFound},
+		"unknown":    {ticketCode(t, "unknown"), http.StatusNotFound},
+	}
+	for name, tc := range testCases {
+		if w := checkIn(tc.code); w.Code != tc.expected {
+			t.Errorf("%s: expected status %d, got %d", name, tc.expected, w.Code)
+		}
+	}
+}
+
+func TestGetCheckInStats(t *testing.T) {
+	setupTestClient(t)
+	defer teardownTestClient()
+
+	ada := registerAttendee(t, "ada@example.com")
+	registerAttendee(t, "bob@example.com")
+	checkIn(ticketCode(t, ada.ID))
+
+	req := httptest.NewRequest("GET", "/api/admin/checkin/stats", nil)
+	w := httptest.NewRecorder()
+	GetCheckInStats(w, req)
+
+	var stats models.CheckInStats
+	json.Unmarshal(w.Body.Bytes(), &stats)
+	if stats.Confirmed != 2 || stats.CheckedIn != 1 || stats.Remaining != 1 || stats.ByDesignation["Developer"] != 1 {
+		t.Errorf("Unexpected check-in stats: %+v", stats)
+	}
+}
+
+func TestGetAttendeeTicket(t *testing.T) {
+	setupTestClient(t)
+	defer teardownTestClient()
+	SetEventCapacity(1)
+
+	confirmed := registerAttendee(t, "ada@example.com")
+	waitlisted := registerAttendee(t, "bob@example.com")
+
+	ticket := func(id string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", "/api/admin/attendees/"+id+"/ticket", nil)
+		req = mux.SetURLVars(req, map[string]string{"id": id})
+		w := httptest.NewRecorder()
+		GetAttendeeTicket(w, req)
+		return w
+	}
+
+	w := ticket(confirmed.ID)
+	if w.Code != http.StatusOK || w.Header().Get("Content-Type") != "image/png" {
+		t.Fatalf("Expected a PNG, got %d %q", w.Code, w.Header().Get("Content-Type"))
+	}
+	if img, err := png.Decode(w.Body); err != nil || img.Bounds().Dx() != ticketSize {
+		t.Errorf("Expected a %dpx ticket image, got err %v", ticketSize, err)
+	}
+	if w := ticket(waitlisted.ID); w.Code != http.StatusConflict {
+		t.Errorf("Expected status 409 for a waitlisted attendee, got %d", w.Code)
+	}
+	if w := ticket("unknown"); w.Code != http.StatusNotFound {
+		t.Errorf("Expected status 404 for an unknown attendee, got %d", w.Code)
+	}
+}
+
+func TestGetRegistrationTicket(t *testing.T) {
+	setupTestClient(t)
+	defer teardownTestClient()
+
+	attendee := registerAttendee(t, "ada@example.com")
+	if w := manageRequest(GetRegistrationTicket, "GET", attendee.ManageToken, nil); w.Code != http.StatusOK || w.Header().Get("Content-Type") != "image/png" {
+		t.Errorf("Expected the ticket PNG, got %d %q", w.Code, w.Header().Get("Content-Type"))
+	}
+	if w := manageRequest(GetRegistrationTicket, "GET", "", nil); w.Code != http.StatusUnauthorized {
+		t.Errorf("Expected status 401 without a manage token, got %d", w.Code)
+	}
+}
+
+func TestConfirmationEmail_EmbedsTicket(t *testing.T) {
+	setupTestClient(t)
+	defer teardownTestClient()
+	recorder := &mailer.Recorder{}
+	SetMailer(recorder, models.EventDetails{Name: "Go Meetup"})
+	SetEventCapacity(1)
+
+	registerAttendee(t, "ada@example.com")
+	registerAttendee(t, "bob@example.com")
+	pendingMail.Wait()
+
+	for _, msg := range recorder.Messages() {
+		switch msg.To {
+		case "ada@example.com":
+			if len(msg.Inline) != 1 || msg.Inline[0].ContentID != ticketContentID || !bytes.Contains([]byte(msg.HTML), []byte("cid:"+ticketContentID)) {
+				t.Errorf("Expected the confirmed attendee's email to embed the ticket, got %d inline parts", len(msg.Inline))
+			}
+		case "bob@example.com":
+			if len(msg.Inline) != 0 {
+				t.Error("Expected no ticket for a waitlisted attendee")
+			}
+		}
+	}
+}
@@ -0,0 +1,141 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"embed"
+	htmltemplate "html/template"
+	"log"
+	"sync"
+	texttemplate "text/template"
+	"time"
+
+	"event-registration-backend/mailer"
+	"event-registration-backend/models"
+)
+
+// mailTimeout bounds rendering and delivering one background email
+const mailTimeout = 30 * time.Second
+
+// maxConcurrentMail bounds how many background emails are sent at once, so a
+// burst of registrations queues up instead of opening a connection per email
+const maxConcurrentMail = 4
+
+var (
+	mailSender   mailer.Mailer = mailer.Noop{}
+	eventDetails models.EventDetails
+
+	// pendingMail tracks emails still being sent in the background
+	pendingMail sync.WaitGroup
+	// mailSlots holds a token for each email being sent
+	mailSlots = make(chan struct{}, maxConcurrentMail)
+)
+
+//go:embed templates
+var templateFS embed.FS
+
+var (
+	confirmationText = texttemplate.Must(texttemplate.ParseFS(templateFS, "templates/confirmation.txt"))
+	confirmationHTML = htmltemplate.Must(htmltemplate.ParseFS(templateFS, "templates/confirmation.html"))
+	verificationText = texttemplate.Must(texttemplate.ParseFS(templateFS, "templates/verification.txt"))
+	verificationHTML = htmltemplate.Must(htmltemplate.ParseFS(templateFS, "templates/verification.html"))
+)
+
+// SetMailer sets how attendee emails are delivered and the event they describe
+func SetMailer(m mailer.Mailer, event models.EventDetails) {
+	mailSender = m
+	eventDetails = event
+}
+
+// confirmationData is the input of the confirmation templates
+type confirmationData struct {
+	Event    models.EventDetails
+	Attendee models.Attendee
+	Sessions []models.Session
+	// Ticket is set when the email carries the attendee's QR ticket
+	Ticket bool
+}
+
+// sendConfirmation emails the registration confirmation, with the agenda, in
+// the background so a slow or failing mail server never affects the response.
+// Failures are logged.
+func sendConfirmation(attendee models.Attendee) {
+	sendInBackground("confirmation", attendee, func(ctx context.Context) (mailer.Message, error) {
+		return confirmationMessage(ctx, attendee)
+	})
+}
+
+// WaitForPendingMail blocks until the background emails queued so far are
+// sent, or ctx is done
+func WaitForPendingMail(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		pendingMail.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// sendInBackground renders and sends one email for attendee without blocking
+// the caller, logging failures. At most maxConcurrentMail emails are sent at
+// once; the rest wait for a slot.
+func sendInBackground(kind string, attendee models.Attendee, render func(ctx context.Context) (mailer.Message, error)) {
+	pendingMail.Add(1)
+	go func() {
+		defer pendingMail.Done()
+		mailSlots <- struct{}{}
+		defer func() { <-mailSlots }()
+
+		ctx, cancel := context.WithTimeout(context.Background(), mailTimeout)
+		defer cancel()
+
+		msg, err := render(ctx)
+		if err != nil {
+			log.Printf("Failed to render %s email for attendee %s: %v", kind, attendee.ID, err)
+			return
+		}
+		if err := mailSender.Send(ctx, msg); err != nil {
+			log.Printf("Failed to send %s email for attendee %s: %v", kind, attendee.ID, err)
+		}
+	}()
+}
+
+// confirmationMessage renders the confirmation email for attendee
+func confirmationMessage(ctx context.Context, attendee models.Attendee) (mailer.Message, error) {
+	sessions, err := loadAgenda(ctx)
+	if err != nil {
+		// The confirmation is still useful without the agenda
+		log.Printf("Failed to load agenda for confirmation email: %v", err)
+	}
+	data := confirmationData{Event: eventDetails, Attendee: attendee, Sessions: sessions}
+
+	// Confirmed attendees get their QR ticket inline
+	var inline []mailer.Attachment
+	if attendee.Status == models.AttendeeConfirmed {
+		png, err := ticketPNG(attendee.ID)
+		if err != nil {
+			return mailer.Message{}, err
+		}
+		inline = append(inline, mailer.Attachment{ContentID: ticketContentID, ContentType: "image/png", Filename: "ticket.png", Data: png})
+		data.Ticket = true
+	}
+
+	var text, html bytes.Buffer
+	if err := confirmationText.Execute(&text, data); err != nil {
+		return mailer.Message{}, err
+	}
+	if err := confirmationHTML.Execute(&html, data); err != nil {
+		return mailer.Message{}, err
+	}
+
+	subject := "Registration confirmed: " + eventDetails.Name
+	if attendee.Status == models.AttendeeWaitlisted {
+		subject = "You're on the waitlist: " + eventDetails.Name
+	}
+	return mailer.Message{To: attendee.Email, Subject: subject, Text: text.String(), HTML: html.String(), Inline: inline}, nil
+}
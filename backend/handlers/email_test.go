@@ -0,0 +1,173 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"event-registration-backend/mailer"
+	"event-registration-backend/models"
+)
+
+// blockingMailer holds every Send until release is closed
+type blockingMailer struct {
+	release chan struct{}
+}
+
+func (m blockingMailer) Send(ctx context.Context, msg mailer.Message) error {
+	<-m.release
+	return nil
+}
+
+// countingMailer is a blockingMailer that records the most sends in flight
+type countingMailer struct {
+	blockingMailer
+	mu      sync.Mutex
+	active  int
+	maxSeen int
+}
+
+func (m *countingMailer) Send(ctx context.Context, msg mailer.Message) error {
+	m.mu.Lock()
+	m.active++
+	if m.active > m.maxSeen {
+		m.maxSeen = m.active
+	}
+	m.mu.Unlock()
+	err := m.blockingMailer.Send(ctx, msg)
+	m.mu.Lock()
+	m.active--
+	m.mu.Unlock()
+	return err
+}
+
+func TestSendInBackground_Bounded(t *testing.T) {
+	setupTestClient(t)
+	defer teardownTestClient()
+	slow := &countingMailer{blockingMailer: blockingMailer{release: make(chan struct{})}}
+	SetMailer(slow, models.EventDetails{Name: "Go Meetup"})
+
+	for i := 0; i < maxConcurrentMail+3; i++ {
+		sendConfirmation(models.Attendee{ID: fmt.Sprint(i), Email: "ada@example.com"})
+	}
+
+	time.AfterFunc(100*time.Millisecond, func() { close(slow.release) })
+	if err := WaitForPendingMail(context.Background()); err != nil {
+		t.Errorf("Expected pending emails to finish, got %v", err)
+	}
+	if slow.maxSeen > maxConcurrentMail {
+		t.Errorf("Expected at most %d emails in flight, got %d", maxConcurrentMail, slow.maxSeen)
+	}
+}
+
+func TestRegisterAttendee_SendsConfirmation(t *testing.T) {
+	setupTestClient(t)
+	defer teardownTestClient()
+	recorder := &mailer.Recorder{}
+	SetMailer(recorder, models.EventDetails{Name: "Go Meetup", Date: "1 May 2026", Venue: "Hall A"})
+
+	ctx := context.Background()
+	speaker := models.Speaker{Name: "Grace Hopper"}
+	speakerStore.Create(ctx, &speaker)
+	sessionStore.Create(ctx, &models.Session{Title: "Compilers 101", Time: "10:00", SpeakerID: speaker.ID})
+
+	registerAttendee(t, "ada@example.com")
+	pendingMail.Wait()
+
+	messages := recorder.Messages()
+	if len(messages) != 1 {
+		t.Fatalf("Expected 1 email, got %d", len(messages))
+	}
+	msg := messages[0]
+	if msg.To != "ada@example.com" || msg.Subject != "Registration confirmed: Go Meetup" {
+		t.Errorf("Unexpected email: to %q subject %q", msg.To, msg.Subject)
+	}
+	for _, want := range []string{"Go Meetup", "1 May 2026", "Hall A", "Compilers 101", "Grace Hopper"} {
+		if !strings.Contains(msg.Text, want) || !strings.Contains(msg.HTML, want) {
+			t.Errorf("Expected both bodies to contain %q", want)
+		}
+	}
+}
+
+func TestRegisterAttendee_WaitlistEmail(t *testing.T) {
+	setupTestClient(t)
+	defer teardownTestClient()
+	recorder := &mailer.Recorder{}
+	SetMailer(recorder, models.EventDetails{Name: "Go Meetup"})
+	SetEventCapacity(1)
+
+	registerAttendee(t, "ada@example.com")
+	registerAttendee(t, "bob@example.com")
+	pendingMail.Wait()
+
+	messages := recorder.Messages()
+	if len(messages) != 2 {
+		t.Fatalf("Expected 2 emails, got %d", len(messages))
+	}
+	for _, msg := range messages {
+		if msg.To == "bob@example.com" && (msg.Subject != "You're on the waitlist: Go Meetup" || !strings.Contains(msg.Text, "number 1 on the waitlist")) {
+			t.Errorf("Unexpected waitlist email: %q\n%s", msg.Subject, msg.Text)
+		}
+	}
+}
+
+func TestCancelAttendee_EmailsPromotedAttendee(t *testing.T) {
+	setupTestClient(t)
+	defer teardownTestClient()
+	recorder := &mailer.Recorder{}
+	SetMailer(recorder, models.EventDetails{Name: "Go Meetup"})
+	SetEventCapacity(1)
+
+	first := registerAttendee(t, "ada@example.com")
+	registerAttendee(t, "bob@example.com")
+	if code := cancelAttendee(first.ID); code != http.StatusOK {
+		t.Fatalf("Expected status 200 cancelling, got %d", code)
+	}
+	pendingMail.Wait()
+
+	messages := recorder.Messages()
+	if len(messages) != 3 {
+		t.Fatalf("Expected 3 emails, got %d", len(messages))
+	}
+	promoted := false
+	for _, msg := range messages {
+		promoted = promoted || (msg.To == "bob@example.com" && msg.Subject == "Registration confirmed: Go Meetup")
+	}
+	if !promoted {
+		t.Errorf("Expected bob to be told of the freed seat, got %+v", messages)
+	}
+}
+
+func TestRegisterAttendee_MailFailureDoesNotFailRegistration(t *testing.T) {
+	setupTestClient(t)
+	defer teardownTestClient()
+	SetMailer(&mailer.Recorder{Err: errors.New("smtp down")}, models.EventDetails{Name: "Go Meetup"})
+
+	if code := registerEmail("ada@example.com"); code != http.StatusOK {
+		t.Errorf("Expected status 200 despite the mail failure, got %d", code)
+	}
+}
+
+func TestRegisterAttendee_SlowMailerDoesNotBlock(t *testing.T) {
+	setupTestClient(t)
+	defer teardownTestClient()
+	slow := blockingMailer{release: make(chan struct{})}
+	SetMailer(slow, models.EventDetails{Name: "Go Meetup"})
+
+	done := make(chan int)
+	go func() { done <- registerEmail("ada@example.com") }()
+	select {
+	case code := <-done:
+		if code != http.StatusOK {
+			t.Errorf("Expected status 200, got %d", code)
+		}
+	case <-time.After(5 * time.Second):
+		t.Error("Registration waited for the mailer")
+	}
+	close(slow.release)
+}
@@ -0,0 +1,162 @@
+package handlers
+
+import (
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"event-registration-backend/export"
+	"event-registration-backend/models"
+)
+
+// exportTimeLayout is how timestamps are written to exports. Spreadsheet
+// applications parse it as a date and time when opening a CSV export; XLSX
+// exports hold it as text, since the XLSX writer only writes string cells.
+const exportTimeLayout = "2006-01-02 15:04:05"
+
+// exportColumn is one column an attendee export can include
+type exportColumn struct {
+	name  string
+	value func(attendee models.Attendee, loc *time.Location) string
+}
+
+// exportColumns lists every exportable attendee field in default order
+var exportColumns = []exportColumn{
+	{"id", func(a models.Attendee, _ *time.Location) string { return a.ID }},
+	{"fullName", func(a models.Attendee, _ *time.Location) string { return a.FullName }},
+	{"email", func(a models.Attendee, _ *time.Location) string { return a.Email }},
+	{"designation", func(a models.Attendee, _ *time.Location) string { return a.Designation }},
+	{"status", func(a models.Attendee, _ *time.Location) string { return a.Status }},
+	{"waitlistPosition", func(a models.Attendee, _ *time.Location) string {
+		if a.WaitlistPosition == 0 {
+			return ""
+		}
+		return strconv.Itoa(a.WaitlistPosition)
+	}},
+	{"registeredAt", func(a models.Attendee, loc *time.Location) string { return formatExportTime(&a.RegisteredAt, loc) }},
+	{"cancelledAt", func(a models.Attendee, loc *time.Location) string { return formatExportTime(a.CancelledAt, loc) }},
+	{"checkedInAt", func(a models.Attendee, loc *time.Location) string { return formatExportTime(a.CheckedInAt, loc) }},
+}
+
+// formColumns returns an export column per registration field, named
+// fields.<id> so they cannot clash with the attendee's own fields
+func formColumns(form []models.RegistrationField) []exportColumn {
+	columns := make([]exportColumn, len(form))
+	for i, field := range form {
+		id := field.ID
+		columns[i] = exportColumn{"fields." + id, func(a models.Attendee, _ *time.Location) string { return a.Fields[id] }}
+	}
+	return columns
+}
+
+func formatExportTime(t *time.Time, loc *time.Location) string {
+	if t == nil || t.IsZero() {
+		return ""
+	}
+	return t.In(loc).Format(exportTimeLayout)
+}
+
+// ExportAttendees streams attendees as CSV or XLSX. Query parameters:
+// format (csv, the default, or xlsx), columns (comma-separated field names,
+// with registration fields named fields.<id>, default all), tz (IANA time
+// zone for timestamps, default UTC) and the filters of parseAttendeeFilter.
+// Rows are written in registration order as they are read from the store.
+func ExportAttendees(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	query := r.URL.Query()
+	filter, fields := parseAttendeeFilter(query)
+
+	format := strings.ToLower(query.Get("format"))
+	if format == "" {
+		format = "csv"
+	}
+	if format != "csv" && format != "xlsx" {
+		fields["format"] = "Must be csv or xlsx"
+	}
+
+	form, err := fieldStore.List(r.Context())
+	if err != nil {
+		http.Error(w, "Failed to export attendees", http.StatusInternalServerError)
+		return
+	}
+	available := append(append([]exportColumn(nil), exportColumns...), formColumns(form)...)
+
+	columns := available
+	if names := queryList(query, "columns"); len(names) > 0 {
+		columns = nil
+		for _, name := range names {
+			column, ok := findExportColumn(available, name)
+			if !ok {
+				fields["columns"] = "Unknown column " + name
+				break
+			}
+			columns = append(columns, column)
+		}
+	}
+
+	loc := time.UTC
+	if tz := query.Get("tz"); tz != "" {
+		if loc, err = time.LoadLocation(tz); err != nil {
+			fields["tz"] = "Unknown time zone " + tz
+		}
+	}
+
+	if len(fields) > 0 {
+		writeValidationErrors(w, fields)
+		return
+	}
+
+	var out export.Writer
+	if format == "xlsx" {
+		w.Header().Set("Content-Type", "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet")
+		w.Header().Set("Content-Disposition", `attachment; filename="attendees.xlsx"`)
+		if out, err = export.NewXLSX(w, "Attendees"); err != nil {
+			log.Printf("Failed to start attendee export: %v", err)
+			return
+		}
+	} else {
+		w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+		w.Header().Set("Content-Disposition", `attachment; filename="attendees.csv"`)
+		out = export.NewCSV(w)
+	}
+
+	header := make([]string, len(columns))
+	for i, column := range columns {
+		header[i] = column.name
+	}
+	row := make([]string, len(columns))
+	err = out.WriteRow(header)
+	if err == nil {
+		err = attendeeStore.Each(r.Context(), func(attendee models.Attendee) error {
+			if !filter.Match(attendee) {
+				return nil
+			}
+			for i, column := range columns {
+				row[i] = column.value(attendee, loc)
+			}
+			return out.WriteRow(row)
+		})
+	}
+	if err == nil {
+		err = out.Close()
+	}
+	// The status line is already sent, so a failure can only cut the file short
+	if err != nil {
+		log.Printf("Attendee export failed: %v", err)
+	}
+}
+
+func findExportColumn(columns []exportColumn, name string) (exportColumn, bool) {
+	for _, column := range columns {
+		if strings.EqualFold(column.name, name) {
+			return column, true
+		}
+	}
+	return exportColumn{}, false
+}
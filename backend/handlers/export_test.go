@@ -0,0 +1,108 @@
+package handlers
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/csv"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// exportAttendees calls ExportAttendees with query
+func exportAttendees(query string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest("GET", "/api/admin/attendees/export"+query, nil)
+	w := httptest.NewRecorder()
+	ExportAttendees(w, req)
+	return w
+}
+
+func TestExportAttendees_CSV(t *testing.T) {
+	setupTestClient(t)
+	defer teardownTestClient()
+
+	ada := registerDesignation(t, "ada@example.com", "Developer")
+	registerDesignation(t, "bob@example.com", "Designer")
+
+	w := exportAttendees("?columns=email,registeredAt,designation&tz=Asia/Kolkata&designation=developer")
+	if w.Code != http.StatusOK || w.Header().Get("Content-Type") != "text/csv; charset=utf-8" {
+		t.Fatalf("Expected CSV, got %d %q", w.Code, w.Header().Get("Content-Type"))
+	}
+	records, err := csv.NewReader(w.Body).ReadAll()
+	if err != nil {
+		t.Fatalf("Invalid CSV: %v", err)
+	}
+	if len(records) != 2 {
+		t.Fatalf("Expected a header and 1 row, got %q", records)
+	}
+	if header := records[0]; len(header) != 3 || header[0] != "email" || header[1] != "registeredAt" || header[2] != "designation" {
+		t.Errorf("Unexpected header: %q", header)
+	}
+	kolkata, _ := time.LoadLocation("Asia/Kolkata")
+	if want := ada.RegisteredAt.In(kolkata).Format(exportTimeLayout); records[1][0] != "ada@example.com" || records[1][1] != want {
+		t.Errorf("Expected ada registered at %s, got %q", want, records[1])
+	}
+}
+
+func TestExportAttendees_AllColumnsByDefault(t *testing.T) {
+	setupTestClient(t)
+	defer teardownTestClient()
+
+	registerAttendee(t, "ada@example.com")
+	records, _ := csv.NewReader(exportAttendees("").Body).ReadAll()
+	if len(records) != 2 || len(records[0]) != len(exportColumns) {
+		t.Errorf("Expected every column for 1 attendee, got %q", records)
+	}
+}
+
+func TestExportAttendees_RegistrationFields(t *testing.T) {
+	setupTestClient(t)
+	defer teardownTestClient()
+	setRegistrationFields(t, workshopForm)
+	registerWithFields("ada@example.com", map[string]string{"company": "Analytical Engines", "tshirtSize": "S"})
+
+	records, _ := csv.NewReader(exportAttendees("").Body).ReadAll()
+	if len(records) != 2 || len(records[0]) != len(exportColumns)+len(workshopForm) || records[0][len(exportColumns)] != "fields.company" {
+		t.Fatalf("Expected a column per registration field, got %q", records)
+	}
+
+	records, _ = csv.NewReader(exportAttendees("?columns=email,fields.tshirtSize").Body).ReadAll()
+	if len(records) != 2 || records[1][0] != "ada@example.com" || records[1][1] != "S" {
+		t.Errorf("Expected the selected answer, got %q", records)
+	}
+}
+
+func TestExportAttendees_XLSX(t *testing.T) {
+	setupTestClient(t)
+	defer teardownTestClient()
+
+	registerAttendee(t, "ada@example.com")
+	w := exportAttendees("?format=xlsx")
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", w.Code)
+	}
+	body := w.Body.Bytes()
+	z, err := zip.NewReader(bytes.NewReader(body), int64(len(body)))
+	if err != nil {
+		t.Fatalf("Expected an XLSX workbook: %v", err)
+	}
+	found := false
+	for _, f := range z.File {
+		found = found || f.Name == "xl/worksheets/sheet1.xml"
+	}
+	if !found {
+		t.Error("Expected a worksheet in the workbook")
+	}
+}
+
+func TestExportAttendees_InvalidParameters(t *testing.T) {
+	setupTestClient(t)
+	defer teardownTestClient()
+
+	for _, query := range []string{"?format=pdf", "?columns=email,password", "?tz=Mars/Olympus", "?registeredFrom=soon"} {
+		if w := exportAttendees(query); w.Code != http.StatusBadRequest {
+			t.Errorf("%s: expected status 400, got %d", query, w.Code)
+		}
+	}
+}
@@ -0,0 +1,266 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"event-registration-backend/models"
+)
+
+// Limits on the admin-defined registration form
+const (
+	maxRegistrationFields = 20
+	maxFieldIDLength      = 40
+	maxFieldLabelLength   = 100
+	maxFieldOptions       = 50
+	maxFieldAnswerLength  = 500
+)
+
+// fieldIDPattern keeps field IDs usable as JSON keys, export column names and
+// Firestore field paths
+var fieldIDPattern = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_]*$`)
+
+// GetRegistrationFields returns the event's extra registration fields in form order
+func GetRegistrationFields(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	fields, err := fieldStore.List(r.Context())
+	if err != nil {
+		http.Error(w, "Failed to get registration fields", http.StatusInternalServerError)
+		return
+	}
+
+	// Ensure we return an empty array, not null
+	if fields == nil {
+		fields = []models.RegistrationField{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(models.RegistrationForm{Fields: fields})
+}
+
+// UpdateRegistrationFields replaces the event's extra registration fields
+// (admin only). Answers already stored on attendees are kept when their field
+// is removed or changed.
+func UpdateRegistrationFields(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPut {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var form models.RegistrationForm
+	if err := json.NewDecoder(r.Body).Decode(&form); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if fields := validateRegistrationForm(form.Fields); len(fields) > 0 {
+		writeValidationErrors(w, fields)
+		return
+	}
+
+	ctx := r.Context()
+	// Keep the previous form for the audit log
+	before, err := fieldStore.List(ctx)
+	if err != nil {
+		http.Error(w, "Failed to update registration fields", http.StatusInternalServerError)
+		return
+	}
+	if err := fieldStore.Replace(ctx, form.Fields); err != nil {
+		http.Error(w, "Failed to update registration fields", http.StatusInternalServerError)
+		return
+	}
+	recordAudit(r, "registrationForm", "", auditActionUpdate, models.RegistrationForm{Fields: before}, form)
+	invalidateResponses(CacheRegistrationFields)
+
+	if form.Fields == nil {
+		form.Fields = []models.RegistrationField{}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(form)
+}
+
+// validateRegistrationForm normalizes fields in place and returns a message
+// per invalid property, keyed like fields.0.label
+func validateRegistrationForm(fields []models.RegistrationField) map[string]string {
+	errs := make(map[string]string)
+	if len(fields) > maxRegistrationFields {
+		errs["fields"] = fmt.Sprintf("must have at most %d fields", maxRegistrationFields)
+		return errs
+	}
+
+	seen := make(map[string]int)
+	for i := range fields {
+		field := &fields[i]
+		key := func(property string) string { return fmt.Sprintf("fields.%d.%s", i, property) }
+
+		field.ID = strings.TrimSpace(field.ID)
+		switch {
+		case field.ID == "":
+			errs[key("id")] = "is required"
+		case len(field.ID) > maxFieldIDLength:
+			errs[key("id")] = fmt.Sprintf("must be at most %d characters", maxFieldIDLength)
+		case !fieldIDPattern.MatchString(field.ID):
+			errs[key("id")] = "must start with a letter and contain only letters, digits and underscores"
+		default:
+			if j, ok := seen[field.ID]; ok {
+				errs[key("id")] = fmt.Sprintf("duplicates field %d", j)
+			}
+			seen[field.ID] = i
+		}
+
+		var msg string
+		if field.Label, msg = validateText(field.Label, maxFieldLabelLength); msg != "" {
+			errs[key("label")] = msg
+		}
+
+		field.Type = strings.ToLower(strings.TrimSpace(field.Type))
+		switch field.Type {
+		case models.FieldText, models.FieldNumber, models.FieldSelect, models.FieldCheckbox:
+		case "":
+			errs[key("type")] = "is required"
+		default:
+			errs[key("type")] = "must be text, number, select or checkbox"
+		}
+
+		if field.Type == models.FieldSelect {
+			if msg := normalizeFieldOptions(field); msg != "" {
+				errs[key("options")] = msg
+			}
+		} else if len(field.Options) > 0 {
+			errs[key("options")] = "only apply to select fields"
+		}
+
+		if field.Pattern != "" {
+			if field.Type != models.FieldText {
+				errs[key("pattern")] = "only applies to text fields"
+			} else if _, err := compileFieldPattern(field.Pattern); err != nil {
+				errs[key("pattern")] = "is not a valid regular expression"
+			}
+		}
+	}
+	return errs
+}
+
+// normalizeFieldOptions trims a select field's options, returning a message
+// when they are missing, blank, too long or repeated
+func normalizeFieldOptions(field *models.RegistrationField) string {
+	if len(field.Options) == 0 {
+		return "are required for select fields"
+	}
+	if len(field.Options) > maxFieldOptions {
+		return fmt.Sprintf("must be at most %d", maxFieldOptions)
+	}
+	seen := make(map[string]bool)
+	for i, option := range field.Options {
+		option, msg := validateText(option, maxFieldAnswerLength)
+		if msg != "" {
+			return fmt.Sprintf("option %d %s", i, msg)
+		}
+		if seen[strings.ToLower(option)] {
+			return fmt.Sprintf("option %d is repeated", i)
+		}
+		seen[strings.ToLower(option)] = true
+		field.Options[i] = option
+	}
+	return ""
+}
+
+// compileFieldPattern compiles a text field's pattern so that it must match
+// the whole answer
+func compileFieldPattern(pattern string) (*regexp.Regexp, error) {
+	return regexp.Compile(`^(?:` + pattern + `)$`)
+}
+
+// validateFieldAnswers checks answers against the registration form and
+// returns the normalized answers, nil when there are none, with a message per
+// invalid answer keyed like fields.company. Numbers are stored in canonical
+// form, checkboxes as true or false and select answers as the option spelled
+// as the admin defined it.
+func validateFieldAnswers(form []models.RegistrationField, answers map[string]string) (map[string]string, map[string]string) {
+	errs := make(map[string]string)
+	known := make(map[string]bool, len(form))
+	var values map[string]string
+
+	for _, field := range form {
+		known[field.ID] = true
+		key := "fields." + field.ID
+		value := strings.TrimSpace(answers[field.ID])
+		if value == "" {
+			if field.Required {
+				errs[key] = "is required"
+			}
+			continue
+		}
+
+		switch field.Type {
+		case models.FieldNumber:
+			n, err := strconv.ParseFloat(value, 64)
+			if err != nil || math.IsNaN(n) || math.IsInf(n, 0) {
+				errs[key] = "must be a number"
+				continue
+			}
+			value = strconv.FormatFloat(n, 'f', -1, 64)
+		case models.FieldSelect:
+			option, ok := matchFieldOption(field.Options, value)
+			if !ok {
+				errs[key] = "must be one of " + strings.Join(field.Options, ", ")
+				continue
+			}
+			value = option
+		case models.FieldCheckbox:
+			checked, err := strconv.ParseBool(value)
+			if err != nil {
+				errs[key] = "must be true or false"
+				continue
+			}
+			if field.Required && !checked {
+				errs[key] = "must be checked"
+				continue
+			}
+			value = strconv.FormatBool(checked)
+		default:
+			if utf8.RuneCountInString(value) > maxFieldAnswerLength {
+				errs[key] = fmt.Sprintf("must be at most %d characters", maxFieldAnswerLength)
+				continue
+			}
+			if field.Pattern != "" {
+				// The pattern was validated when the form was saved
+				if re, err := compileFieldPattern(field.Pattern); err == nil && !re.MatchString(value) {
+					errs[key] = "is not in the expected format"
+					continue
+				}
+			}
+		}
+
+		if values == nil {
+			values = make(map[string]string)
+		}
+		values[field.ID] = value
+	}
+
+	for id := range answers {
+		if !known[id] {
+			errs["fields."+id] = "is not a field of this form"
+		}
+	}
+	return values, errs
+}
+
+// matchFieldOption returns the option equal to value, ignoring case
+func matchFieldOption(options []string, value string) (string, bool) {
+	for _, option := range options {
+		if strings.EqualFold(option, value) {
+			return option, true
+		}
+	}
+	return "", false
+}
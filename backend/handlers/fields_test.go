@@ -0,0 +1,227 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"event-registration-backend/models"
+	"event-registration-backend/store"
+)
+
+// workshopForm is a registration form using every field type
+var workshopForm = []models.RegistrationField{
+	{ID: "company", Type: "text", Label: "Company", Required: true},
+	{ID: "tshirtSize", Type: "select", Label: "T-shirt size", Required: true, Options: []string{"S", "M", "L"}},
+	{ID: "github", Type: "text", Label: "GitHub handle", Pattern: `[A-Za-z0-9-]{1,39}`},
+	{ID: "years", Type: "number", Label: "Years of experience"},
+	{ID: "vegetarian", Type: "checkbox", Label: "Vegetarian meal"},
+}
+
+// putRegistrationFields calls UpdateRegistrationFields with fields
+func putRegistrationFields(fields []models.RegistrationField) *httptest.ResponseRecorder {
+	body, _ := json.Marshal(models.RegistrationForm{Fields: fields})
+	req := httptest.NewRequest("PUT", "/api/admin/registration/fields", bytes.NewBuffer(body))
+	w := httptest.NewRecorder()
+	UpdateRegistrationFields(w, req)
+	return w
+}
+
+// setRegistrationFields saves fields as the registration form
+func setRegistrationFields(t *testing.T, fields []models.RegistrationField) {
+	t.Helper()
+	if w := putRegistrationFields(fields); w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200 saving the form, got %d: %s", w.Code, w.Body.String())
+	}
+}
+
+// registerWithFields registers email with answers to the registration form
+func registerWithFields(email string, answers map[string]string) *httptest.ResponseRecorder {
+	body, _ := json.Marshal(models.RegisterRequest{FullName: "Ada Lovelace", Email: email, Designation: "Developer", Fields: answers})
+	req := httptest.NewRequest("POST", "/api/attendees/register", bytes.NewBuffer(body))
+	w := httptest.NewRecorder()
+	RegisterAttendee(w, req)
+	return w
+}
+
+func TestRegistrationFields_RoundTrip(t *testing.T) {
+	setupTestClient(t)
+	defer teardownTestClient()
+
+	req := httptest.NewRequest("GET", "/api/registration/fields", nil)
+	w := httptest.NewRecorder()
+	GetRegistrationFields(w, req)
+	if w.Code != http.StatusOK || w.Body.String() != "{\"fields\":[]}\n" {
+		t.Fatalf("Expected an empty form, got %d: %s", w.Code, w.Body.String())
+	}
+
+	fields := append([]models.RegistrationField(nil), workshopForm...)
+	fields[1].Type = " Select "
+	fields[1].Options = []string{" S", "M ", "L"}
+	setRegistrationFields(t, fields)
+
+	w = httptest.NewRecorder()
+	GetRegistrationFields(w, req)
+	var form models.RegistrationForm
+	if err := json.Unmarshal(w.Body.Bytes(), &form); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+	if len(form.Fields) != len(workshopForm) {
+		t.Fatalf("Expected %d fields, got %+v", len(workshopForm), form.Fields)
+	}
+	for i, field := range form.Fields {
+		if field.ID != workshopForm[i].ID {
+			t.Errorf("Expected field %d to be %s, got %s", i, workshopForm[i].ID, field.ID)
+		}
+	}
+	if size := form.Fields[1]; size.Type != models.FieldSelect || size.Options[0] != "S" || size.Options[1] != "M" {
+		t.Errorf("Expected the select field to be normalized, got %+v", size)
+	}
+
+	// Saving an empty form removes every field
+	setRegistrationFields(t, nil)
+	if fields, _ := fieldStore.List(context.Background()); len(fields) != 0 {
+		t.Errorf("Expected no fields, got %+v", fields)
+	}
+}
+
+func TestUpdateRegistrationFields_Invalid(t *testing.T) {
+	setupTestClient(t)
+	defer teardownTestClient()
+
+	testCases := []struct {
+		name  string
+		field models.RegistrationField
+		key   string
+	}{
+		{"missing id", models.RegistrationField{Type: "text", Label: "Company"}, "fields.1.id"},
+		{"id with spaces", models.RegistrationField{ID: "t shirt", Type: "text", Label: "T-shirt"}, "fields.1.id"},
+		{"duplicate id", models.RegistrationField{ID: "company", Type: "text", Label: "Employer"}, "fields.1.id"},
+		{"missing label", models.RegistrationField{ID: "dietary", Type: "text"}, "fields.1.label"},
+		{"unknown type", models.RegistrationField{ID: "dob", Type: "date", Label: "Date of birth"}, "fields.1.type"},
+		{"select without options", models.RegistrationField{ID: "size", Type: "select", Label: "Size"}, "fields.1.options"},
+		{"repeated option", models.RegistrationField{ID: "size", Type: "select", Label: "Size", Options: []string{"M", "m"}}, "fields.1.options"},
+		{"options on text", models.RegistrationField{ID: "size", Type: "text", Label: "Size", Options: []string{"M"}}, "fields.1.options"},
+		{"invalid pattern", models.RegistrationField{ID: "github", Type: "text", Label: "GitHub", Pattern: "[a-z"}, "fields.1.pattern"},
+		{"pattern on number", models.RegistrationField{ID: "years", Type: "number", Label: "Years", Pattern: "[0-9]+"}, "fields.1.pattern"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			w := putRegistrationFields([]models.RegistrationField{workshopForm[0], tc.field})
+			if w.Code != http.StatusBadRequest {
+				t.Fatalf("Expected status 400, got %d", w.Code)
+			}
+			var resp models.ValidationErrorResponse
+			json.Unmarshal(w.Body.Bytes(), &resp)
+			if resp.Fields[tc.key] == "" {
+				t.Errorf("Expected an error for %s, got %v", tc.key, resp.Fields)
+			}
+		})
+	}
+
+	if fields, _ := fieldStore.List(context.Background()); len(fields) != 0 {
+		t.Errorf("Expected invalid forms not to be saved, got %+v", fields)
+	}
+}
+
+func TestRegisterAttendee_CustomFields(t *testing.T) {
+	setupTestClient(t)
+	defer teardownTestClient()
+	setRegistrationFields(t, workshopForm)
+
+	w := registerWithFields("ada@example.com", map[string]string{
+		"company":    " Analytical Engines ",
+		"tshirtSize": "m",
+		"github":     "ada-l",
+		"years":      "07.50",
+		"vegetarian": "1",
+	})
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	attendee, err := attendeeStore.Get(context.Background(), store.AttendeeID("ada@example.com"))
+	if err != nil {
+		t.Fatalf("Failed to get attendee: %v", err)
+	}
+	expected := map[string]string{
+		"company":    "Analytical Engines",
+		"tshirtSize": "M",
+		"github":     "ada-l",
+		"years":      "7.5",
+		"vegetarian": "true",
+	}
+	for id, value := range expected {
+		if attendee.Fields[id] != value {
+			t.Errorf("Expected %s to be stored as %q, got %q", id, value, attendee.Fields[id])
+		}
+	}
+}
+
+func TestRegisterAttendee_CustomFieldErrors(t *testing.T) {
+	setupTestClient(t)
+	defer teardownTestClient()
+	setRegistrationFields(t, workshopForm)
+
+	w := registerWithFields("ada@example.com", map[string]string{
+		"tshirtSize": "XXL",
+		"github":     "not a handle!",
+		"years":      "many",
+		"vegetarian": "maybe",
+		"twitter":    "@ada",
+	})
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("Expected status 400, got %d", w.Code)
+	}
+	var resp models.ValidationErrorResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+	for _, key := range []string{"fields.company", "fields.tshirtSize", "fields.github", "fields.years", "fields.vegetarian", "fields.twitter"} {
+		if resp.Fields[key] == "" {
+			t.Errorf("Expected an error for %s, got %v", key, resp.Fields)
+		}
+	}
+	if len(listAttendees(t)) != 0 {
+		t.Error("Expected the registration to be rejected")
+	}
+}
+
+func TestGetAttendeeStats_SelectField(t *testing.T) {
+	setupTestClient(t)
+	defer teardownTestClient()
+	setRegistrationFields(t, workshopForm)
+
+	for email, size := range map[string]string{"ada@example.com": "M", "bob@example.com": "m", "carol@example.com": "L"} {
+		if w := registerWithFields(email, map[string]string{"company": "Acme", "tshirtSize": size}); w.Code != http.StatusOK {
+			t.Fatalf("Expected status 200 registering %s, got %d: %s", email, w.Code, w.Body.String())
+		}
+	}
+
+	req := httptest.NewRequest("GET", "/api/admin/stats?field=tshirtSize", nil)
+	w := httptest.NewRecorder()
+	GetAttendeeStats(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var stats map[string]int
+	if err := json.Unmarshal(w.Body.Bytes(), &stats); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+	if len(stats) != 3 || stats["S"] != 0 || stats["M"] != 2 || stats["L"] != 1 {
+		t.Errorf("Expected S=0 M=2 L=1, got %v", stats)
+	}
+
+	for _, field := range []string{"company", "shoeSize"} {
+		req := httptest.NewRequest("GET", "/api/admin/stats?field="+field, nil)
+		w := httptest.NewRecorder()
+		GetAttendeeStats(w, req)
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("Expected status 400 for field %s, got %d", field, w.Code)
+		}
+	}
+}
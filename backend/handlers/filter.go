@@ -0,0 +1,174 @@
+package handlers
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"event-registration-backend/models"
+	"event-registration-backend/store"
+)
+
+// dateLayout is the date-only form accepted by the registeredFrom and
+// registeredTo query parameters
+const dateLayout = "2006-01-02"
+
+// parseAttendeeFilter reads the q (free-text search), designation, status,
+// registeredFrom and registeredTo query parameters. Designation and status
+// may repeat or hold comma-separated values. Dates are RFC 3339 timestamps or
+// YYYY-MM-DD, where a date-only registeredTo includes that whole day. Invalid
+// parameters are returned as per-field errors.
+func parseAttendeeFilter(query url.Values) (store.AttendeeFilter, map[string]string) {
+	filter := store.AttendeeFilter{
+		Search:       strings.TrimSpace(query.Get("q")),
+		Designations: queryList(query, "designation"),
+		Statuses:     queryList(query, "status"),
+	}
+	fields := make(map[string]string)
+
+	for _, status := range filter.Statuses {
+		switch status {
+		case models.AttendeePending, models.AttendeeConfirmed, models.AttendeeWaitlisted, models.AttendeeCancelled:
+		default:
+			fields["status"] = "Unknown status " + status
+		}
+	}
+	if v := query.Get("registeredFrom"); v != "" {
+		from, _, err := parseQueryTime(v)
+		if err != nil {
+			fields["registeredFrom"] = "Must be a date (YYYY-MM-DD) or RFC 3339 timestamp"
+		}
+		filter.RegisteredFrom = from
+	}
+	if v := query.Get("registeredTo"); v != "" {
+		to, dateOnly, err := parseQueryTime(v)
+		if err != nil {
+			fields["registeredTo"] = "Must be a date (YYYY-MM-DD) or RFC 3339 timestamp"
+		}
+		if dateOnly {
+			to = to.AddDate(0, 0, 1)
+		}
+		filter.RegisteredTo = to
+	}
+	return filter, fields
+}
+
+// parseQueryTime parses an RFC 3339 timestamp or a UTC date, reporting which
+func parseQueryTime(v string) (t time.Time, dateOnly bool, err error) {
+	if t, err := time.Parse(dateLayout, v); err == nil {
+		return t, true, nil
+	}
+	t, err = time.Parse(time.RFC3339, v)
+	return t, false, err
+}
+
+// queryList returns the non-empty values of a repeated or comma-separated
+// query parameter
+func queryList(query url.Values, name string) []string {
+	var values []string
+	for _, v := range query[name] {
+		for _, item := range strings.Split(v, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				values = append(values, item)
+			}
+		}
+	}
+	return values
+}
+
+// Page sizes for the attendee listing
+const (
+	defaultAttendeePageSize = 100
+	maxAttendeePageSize     = 1000
+)
+
+// pageToken is the decoded form of the opaque token that continues an
+// attendee listing. It records the sort order so a token cannot be replayed
+// against a different one.
+type pageToken struct {
+	Sort       string `json:"s"`
+	Descending bool   `json:"d,omitempty"`
+	// Key is the sort field value of the last attendee on the previous page;
+	// times use RFC 3339 with nanoseconds
+	Key string `json:"k"`
+	ID  string `json:"id"`
+}
+
+// encodePageToken returns the token continuing q after attendee
+func encodePageToken(q store.AttendeeQuery, attendee models.Attendee) string {
+	token := pageToken{Sort: q.Sort, Descending: q.Descending, ID: attendee.ID}
+	switch key := store.SortKey(attendee, q.Sort).(type) {
+	case time.Time:
+		token.Key = key.UTC().Format(time.RFC3339Nano)
+	case string:
+		token.Key = key
+	}
+	data, _ := json.Marshal(token)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// parseAttendeeQuery reads the filter of parseAttendeeFilter plus sort
+// (registeredAt, the default, fullName or email), order (asc or desc), limit
+// and pageToken, as returned in the X-Next-Page-Token header of the previous
+// page. Invalid parameters are returned as per-field errors.
+func parseAttendeeQuery(query url.Values) (store.AttendeeQuery, map[string]string) {
+	filter, fields := parseAttendeeFilter(query)
+	q := store.AttendeeQuery{AttendeeFilter: filter, Sort: query.Get("sort"), Limit: defaultAttendeePageSize}
+
+	switch q.Sort {
+	case "":
+		q.Sort = store.SortRegisteredAt
+	case store.SortRegisteredAt, store.SortFullName, store.SortEmail:
+	default:
+		fields["sort"] = "Must be registeredAt, fullName or email"
+	}
+	switch strings.ToLower(query.Get("order")) {
+	case "", "asc":
+	case "desc":
+		q.Descending = true
+	default:
+		fields["order"] = "Must be asc or desc"
+	}
+	if v := query.Get("limit"); v != "" {
+		limit, err := strconv.Atoi(v)
+		if err != nil || limit <= 0 || limit > maxAttendeePageSize {
+			fields["limit"] = fmt.Sprintf("Must be between 1 and %d", maxAttendeePageSize)
+		}
+		q.Limit = limit
+	}
+
+	if v := query.Get("pageToken"); v != "" {
+		cursor, msg := decodePageToken(v, q)
+		if msg != "" {
+			fields["pageToken"] = msg
+		}
+		q.After = cursor
+	}
+	return q, fields
+}
+
+// decodePageToken returns the position recorded in v, or a message when it is
+// malformed or was issued for a different sort order than q's
+func decodePageToken(v string, q store.AttendeeQuery) (*store.AttendeeCursor, string) {
+	var token pageToken
+	data, err := base64.RawURLEncoding.DecodeString(v)
+	if err != nil || json.Unmarshal(data, &token) != nil || token.ID == "" {
+		return nil, "Invalid page token"
+	}
+	if token.Sort != q.Sort || token.Descending != q.Descending {
+		return nil, "Page token was issued for a different sort order"
+	}
+	cursor := &store.AttendeeCursor{Key: token.Key, ID: token.ID}
+	if q.Sort == store.SortRegisteredAt {
+		t, err := time.Parse(time.RFC3339Nano, token.Key)
+		if err != nil {
+			return nil, "Invalid page token"
+		}
+		cursor.Key = t
+	}
+	return cursor, ""
+}
@@ -0,0 +1,64 @@
+package handlers
+
+import (
+	"net/url"
+	"testing"
+	"time"
+
+	"event-registration-backend/models"
+)
+
+func TestParseAttendeeFilter(t *testing.T) {
+	query, _ := url.ParseQuery("designation=Developer,Designer&designation=QA&status=confirmed&registeredFrom=2026-05-01&registeredTo=2026-05-02")
+	filter, fields := parseAttendeeFilter(query)
+	if len(fields) > 0 {
+		t.Fatalf("Unexpected errors: %v", fields)
+	}
+	if len(filter.Designations) != 3 || filter.Designations[2] != "QA" {
+		t.Errorf("Unexpected designations: %v", filter.Designations)
+	}
+
+	at := func(s string) time.Time {
+		v, _ := time.Parse(time.RFC3339, s)
+		return v
+	}
+	testCases := []struct {
+		attendee models.Attendee
+		expected bool
+	}{
+		{models.Attendee{Designation: "developer", Status: models.AttendeeConfirmed, RegisteredAt: at("2026-05-01T00:00:00Z")}, true},
+		{models.Attendee{Designation: "QA", Status: models.AttendeeConfirmed, RegisteredAt: at("2026-05-02T23:59:59Z")}, true},
+		{models.Attendee{Designation: "QA", Status: models.AttendeeConfirmed, RegisteredAt: at("2026-05-03T00:00:00Z")}, false},
+		{models.Attendee{Designation: "QA", Status: models.AttendeeConfirmed, RegisteredAt: at("2026-04-30T23:59:59Z")}, false},
+		{models.Attendee{Designation: "Manager", Status: models.AttendeeConfirmed, RegisteredAt: at("2026-05-01T12:00:00Z")}, false},
+		{models.Attendee{Designation: "QA", Status: models.AttendeeWaitlisted, RegisteredAt: at("2026-05-01T12:00:00Z")}, false},
+	}
+	for i, tc := range testCases {
+		if got := filter.Match(tc.attendee); got != tc.expected {
+			t.Errorf("Case %d: expected %v, got %v", i, tc.expected, got)
+		}
+	}
+}
+
+func TestParseAttendeeFilter_Invalid(t *testing.T) {
+	query, _ := url.ParseQuery("status=gone&registeredFrom=tomorrow&registeredTo=2026-13-01")
+	_, fields := parseAttendeeFilter(query)
+	for _, name := range []string{"status", "registeredFrom", "registeredTo"} {
+		if fields[name] == "" {
+			t.Errorf("Expected an error for %s, got %v", name, fields)
+		}
+	}
+}
+
+func TestParseAttendeeFilter_Search(t *testing.T) {
+	query, _ := url.ParseQuery("q=+LOVE+")
+	filter, _ := parseAttendeeFilter(query)
+	for email, expected := range map[string]bool{"ada@lovelace.dev": true, "bob@example.com": false} {
+		if got := filter.Match(models.Attendee{FullName: "Someone", Email: email}); got != expected {
+			t.Errorf("%s: expected %v, got %v", email, expected, got)
+		}
+	}
+	if !filter.Match(models.Attendee{FullName: "Ada Lovelace", Email: "ada@example.com"}) {
+		t.Error("Expected the search to match the name")
+	}
+}
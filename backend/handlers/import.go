@@ -0,0 +1,307 @@
+package handlers
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"event-registration-backend/auth"
+	"event-registration-backend/models"
+	"event-registration-backend/store"
+)
+
+// maxImportSize bounds the CSV accepted by ImportAttendees
+const maxImportSize = 5 << 20
+
+// importAliases maps normalized CSV headers to the attendee field they hold,
+// covering the column names of common event platform exports. firstName and
+// lastName are joined into fullName when the file has no full name column.
+var importAliases = map[string]string{
+	"fullname":     "fullName",
+	"name":         "fullName",
+	"attendeename": "fullName",
+	"firstname":    "firstName",
+	"givenname":    "firstName",
+	"lastname":     "lastName",
+	"surname":      "lastName",
+	"familyname":   "lastName",
+	"email":        "email",
+	"emailaddress": "email",
+	"designation":  "designation",
+	"title":        "designation",
+	"jobtitle":     "designation",
+	"role":         "designation",
+	"position":     "designation",
+}
+
+// importColumns is the position of each attendee field in the CSV; -1 when
+// the file has no such column. custom maps registration field IDs to their
+// columns.
+type importColumns struct {
+	fullName, firstName, lastName, email, designation int
+	custom                                            map[string]int
+}
+
+// ImportAttendees registers attendees from a CSV file, sent as the request
+// body or as the file field of a multipart form. The header row is matched to
+// attendee fields by common column names; the fullName, email and
+// designation query parameters name the column to use instead. Registration
+// field answers are read from columns named by the field's ID or label. Each
+// row goes through the same validation and duplicate-email rules as
+// RegisterAttendee, in file order, and rows that fail are reported rather than
+// stopping the import. With dryRun=true nothing is written, but each row is
+// checked against existing registrations as Create would. Imported attendees
+// are not asked to verify their email and are only emailed, with their manage
+// token, with notify=true.
+func ImportAttendees(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	query := r.URL.Query()
+	dryRun, _ := strconv.ParseBool(query.Get("dryRun"))
+	notify, _ := strconv.ParseBool(query.Get("notify"))
+
+	body, err := importBody(w, r)
+	if err != nil {
+		http.Error(w, "Missing CSV file", http.StatusBadRequest)
+		return
+	}
+	defer body.Close()
+
+	reader := csv.NewReader(body)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+	header, err := reader.Read()
+	if err != nil {
+		http.Error(w, "CSV file has no header row", http.StatusBadRequest)
+		return
+	}
+	form, err := fieldStore.List(r.Context())
+	if err != nil {
+		http.Error(w, "Failed to import attendees", http.StatusInternalServerError)
+		return
+	}
+	columns, fields := mapImportColumns(header, query, form)
+	if len(fields) > 0 {
+		writeValidationErrors(w, fields)
+		return
+	}
+
+	// Read the whole file first so a malformed CSV imports nothing
+	type importRow struct {
+		line   int
+		record []string
+	}
+	var rows []importRow
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			var parseErr *csv.ParseError
+			if errors.As(err, &parseErr) {
+				http.Error(w, fmt.Sprintf("Invalid CSV on line %d: %v", parseErr.Line, parseErr.Err), http.StatusBadRequest)
+			} else {
+				http.Error(w, "Failed to read CSV file", http.StatusBadRequest)
+			}
+			return
+		}
+		line, _ := reader.FieldPos(0)
+		rows = append(rows, importRow{line, record})
+	}
+
+	ctx := r.Context()
+	result := models.ImportResult{DryRun: dryRun, Rows: len(rows), Errors: []models.ImportRowError{}}
+	seen := make(map[string]int)
+	for _, row := range rows {
+		req := columns.request(row.record)
+		rowErr := models.ImportRowError{Line: row.line, Email: strings.TrimSpace(req.Email)}
+		fields := validateRegisterRequest(&req)
+		answers, answerErrors := validateFieldAnswers(form, req.Fields)
+		for key, msg := range answerErrors {
+			fields[key] = msg
+		}
+		if len(fields) > 0 {
+			rowErr.Fields = fields
+			result.Errors = append(result.Errors, rowErr)
+			continue
+		}
+		rowErr.Email = req.Email
+		// Attendees are keyed by case-insensitive email, as in the store
+		id := store.AttendeeID(req.Email)
+		if line, ok := seen[id]; ok {
+			rowErr.Fields = map[string]string{"email": fmt.Sprintf("duplicates line %d", line)}
+			result.Errors = append(result.Errors, rowErr)
+			continue
+		}
+		seen[id] = row.line
+
+		attendee := models.Attendee{
+			FullName:     req.FullName,
+			Email:        req.Email,
+			Designation:  req.Designation,
+			RegisteredAt: time.Now(),
+			Fields:       answers,
+		}
+		var manageToken string
+		if dryRun {
+			// The same duplicate check Create applies
+			err = attendeeStore.CheckAvailable(ctx, req.Email)
+		} else {
+			var manageTokenHash string
+			manageToken, manageTokenHash, err = auth.GenerateManageToken(id)
+			if err == nil {
+				attendee.ManageTokenHash = manageTokenHash
+				err = attendeeStore.Create(ctx, &attendee, eventCapacity)
+			}
+		}
+		if err == store.ErrAlreadyExists {
+			rowErr.Fields = map[string]string{"email": "is already registered"}
+			result.Errors = append(result.Errors, rowErr)
+			continue
+		}
+		if err != nil {
+			log.Printf("Failed to import attendee on line %d: %v", row.line, err)
+			rowErr.Error = "Failed to import attendee"
+			result.Errors = append(result.Errors, rowErr)
+			continue
+		}
+
+		result.Imported++
+		if !dryRun {
+			recordAudit(r, "attendee", attendee.ID, auditActionImport, nil, attendee)
+			if notify {
+				attendee.ManageToken = manageToken
+				sendConfirmation(attendee)
+			}
+		}
+	}
+
+	if result.Imported > 0 && !dryRun {
+		invalidateResponses(CacheAttendeeCount)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
+
+// importBody returns the uploaded CSV, limited to maxImportSize
+func importBody(w http.ResponseWriter, r *http.Request) (io.ReadCloser, error) {
+	r.Body = http.MaxBytesReader(w, r.Body, maxImportSize)
+	if !strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/form-data") {
+		return r.Body, nil
+	}
+	file, _, err := r.FormFile("file")
+	return file, err
+}
+
+// mapImportColumns locates each attendee field in header, preferring columns
+// named by query parameters over the built-in aliases, and each registration
+// field of form. It returns a message per attendee field that has no column;
+// registration fields without one are left blank.
+func mapImportColumns(header []string, query url.Values, form []models.RegistrationField) (importColumns, map[string]string) {
+	columns := importColumns{-1, -1, -1, -1, -1, make(map[string]int)}
+	positions := map[string]*int{
+		"fullName":    &columns.fullName,
+		"firstName":   &columns.firstName,
+		"lastName":    &columns.lastName,
+		"email":       &columns.email,
+		"designation": &columns.designation,
+	}
+	for i, name := range header {
+		if field, ok := importAliases[normalizeHeader(name)]; ok && *positions[field] < 0 {
+			*positions[field] = i
+		}
+	}
+
+	fields := make(map[string]string)
+	for _, field := range []string{"fullName", "email", "designation"} {
+		name := query.Get(field)
+		if name == "" {
+			continue
+		}
+		*positions[field] = -1
+		for i, column := range header {
+			if normalizeHeader(column) == normalizeHeader(name) {
+				*positions[field] = i
+				break
+			}
+		}
+		if *positions[field] < 0 {
+			fields[field] = "No column named " + name
+		}
+	}
+
+	for _, field := range []string{"fullName", "email", "designation"} {
+		if _, ok := fields[field]; ok || *positions[field] >= 0 {
+			continue
+		}
+		if field == "fullName" && (columns.firstName >= 0 || columns.lastName >= 0) {
+			continue
+		}
+		fields[field] = "No " + field + " column; name one with the " + field + " parameter"
+	}
+
+	for _, field := range form {
+		for i, name := range header {
+			name = normalizeHeader(name)
+			if name == normalizeHeader(field.ID) || name == normalizeHeader(field.Label) {
+				columns.custom[field.ID] = i
+				break
+			}
+		}
+	}
+	return columns, fields
+}
+
+// normalizeHeader reduces a column name to lowercase letters and digits, so
+// "Full Name", "full_name" and "FullName" compare equal. This also drops the
+// byte order mark spreadsheet applications put before the first column.
+func normalizeHeader(name string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return unicode.ToLower(r)
+		}
+		return -1
+	}, name)
+}
+
+// request builds the registration request held by one CSV record. Short
+// records leave the missing fields empty.
+func (c importColumns) request(record []string) models.RegisterRequest {
+	cell := func(i int) string {
+		if i < 0 || i >= len(record) {
+			return ""
+		}
+		return record[i]
+	}
+	fullName := cell(c.fullName)
+	if c.fullName < 0 {
+		fullName = strings.TrimSpace(strings.TrimSpace(cell(c.firstName)) + " " + strings.TrimSpace(cell(c.lastName)))
+	}
+	var answers map[string]string
+	for id, i := range c.custom {
+		if answers == nil {
+			answers = make(map[string]string)
+		}
+		answers[id] = cell(i)
+	}
+	return models.RegisterRequest{
+		FullName:    fullName,
+		Email:       cell(c.email),
+		Designation: cell(c.designation),
+		Fields:      answers,
+	}
+}
@@ -0,0 +1,239 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"event-registration-backend/mailer"
+	"event-registration-backend/models"
+	"event-registration-backend/store"
+)
+
+// importCSV posts csv to ImportAttendees with query
+func importCSV(t *testing.T, query, csv string) (*httptest.ResponseRecorder, models.ImportResult) {
+	t.Helper()
+	req := httptest.NewRequest("POST", "/api/admin/attendees/import"+query, strings.NewReader(csv))
+	req.Header.Set("Content-Type", "text/csv")
+	w := httptest.NewRecorder()
+	ImportAttendees(w, req)
+
+	var result models.ImportResult
+	if w.Code == http.StatusOK {
+		if err := json.Unmarshal(w.Body.Bytes(), &result); err != nil {
+			t.Fatalf("Failed to unmarshal response: %v", err)
+		}
+	}
+	return w, result
+}
+
+func listAttendees(t *testing.T) []models.Attendee {
+	t.Helper()
+	attendees, err := attendeeStore.List(context.Background())
+	if err != nil {
+		t.Fatalf("Failed to list attendees: %v", err)
+	}
+	return attendees
+}
+
+const partnerCSV = "\ufeffFirst Name,Last Name,Email Address,Job Title\n" +
+	"Ada,Lovelace,Ada@Example.com,Developer\n" +
+	"Bob,,bob@example,Designer\n" +
+	"Ada,Again,ada@example.com,Developer\n" +
+	"Carol,Shaw,carol@example.com,\n" +
+	"Dan,Brown,dan@example.com,Writer\n"
+
+func TestImportAttendees(t *testing.T) {
+	setupTestClient(t)
+	defer teardownTestClient()
+	registerAttendee(t, "dan@example.com")
+
+	w, result := importCSV(t, "", partnerCSV)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if result.DryRun || result.Rows != 5 || result.Imported != 1 {
+		t.Errorf("Expected 1 of 5 rows imported, got %+v", result)
+	}
+
+	want := map[int]string{3: "email", 4: "email", 5: "designation", 6: "email"}
+	if len(result.Errors) != len(want) {
+		t.Fatalf("Expected %d row errors, got %+v", len(want), result.Errors)
+	}
+	for _, rowErr := range result.Errors {
+		if _, ok := rowErr.Fields[want[rowErr.Line]]; !ok {
+			t.Errorf("Line %d: expected a %s error, got %+v", rowErr.Line, want[rowErr.Line], rowErr)
+		}
+	}
+	if msg := result.Errors[1].Fields["email"]; msg != "duplicates line 2" {
+		t.Errorf("Expected the in-file duplicate to name line 2, got %q", msg)
+	}
+	if msg := result.Errors[3].Fields["email"]; msg != "is already registered" {
+		t.Errorf("Expected dan to be already registered, got %q", msg)
+	}
+
+	ada, err := attendeeStore.Get(context.Background(), store.AttendeeID("ada@example.com"))
+	if err != nil {
+		t.Fatalf("Expected ada to be imported: %v", err)
+	}
+	if ada.Email != "Ada@example.com" || ada.FullName != "Ada Lovelace" || ada.Designation != "Developer" || ada.Status != models.AttendeeConfirmed {
+		t.Errorf("Unexpected imported attendee: %+v", ada)
+	}
+}
+
+func TestImportAttendees_DryRunWritesNothing(t *testing.T) {
+	setupTestClient(t)
+	defer teardownTestClient()
+	registerAttendee(t, "dan@example.com")
+
+	_, dryRun := importCSV(t, "?dryRun=true", partnerCSV)
+	if !dryRun.DryRun || dryRun.Imported != 1 || len(dryRun.Errors) != 4 {
+		t.Errorf("Expected the dry run to report 1 importable row and 4 errors, got %+v", dryRun)
+	}
+	if attendees := listAttendees(t); len(attendees) != 1 {
+		t.Errorf("Expected the dry run to write nothing, got %d attendees", len(attendees))
+	}
+
+	// The real import agrees with the dry run
+	_, result := importCSV(t, "", partnerCSV)
+	if result.Imported != dryRun.Imported || len(result.Errors) != len(dryRun.Errors) {
+		t.Errorf("Expected the import to match the dry run %+v, got %+v", dryRun, result)
+	}
+}
+
+func TestImportAttendees_ColumnMapping(t *testing.T) {
+	setupTestClient(t)
+	defer teardownTestClient()
+
+	csv := "Attendee,Contact,Company role\nAda Lovelace,ada@example.com,Developer\n"
+	w, _ := importCSV(t, "", csv)
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("Expected status 400 for unmapped columns, got %d", w.Code)
+	}
+	var resp models.ValidationErrorResponse
+	json.Unmarshal(w.Body.Bytes(), &resp)
+	if len(resp.Fields) != 3 {
+		t.Errorf("Expected all three fields to be unmapped, got %v", resp.Fields)
+	}
+
+	w, result := importCSV(t, "?fullName=Attendee&email=contact&designation=company_role", csv)
+	if w.Code != http.StatusOK || result.Imported != 1 {
+		t.Errorf("Expected the mapped columns to import 1 row, got %d: %s", w.Code, w.Body.String())
+	}
+
+	if w, _ := importCSV(t, "?email=Phone", csv); w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400 for a missing mapped column, got %d", w.Code)
+	}
+}
+
+func TestImportAttendees_RegistrationFields(t *testing.T) {
+	setupTestClient(t)
+	defer teardownTestClient()
+	setRegistrationFields(t, workshopForm)
+
+	// Answers are read from columns named by field label or ID
+	csv := "Name,Email,Title,Company,T-shirt Size,github\n" +
+		"Ada Lovelace,ada@example.com,Developer,Analytical Engines,l,ada-l\n" +
+		"Bob Smith,bob@example.com,Designer,,M,\n"
+	w, result := importCSV(t, "", csv)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if result.Imported != 1 || len(result.Errors) != 1 || result.Errors[0].Fields["fields.company"] == "" {
+		t.Fatalf("Expected bob to miss the required company, got %+v", result)
+	}
+
+	attendee, err := attendeeStore.Get(context.Background(), store.AttendeeID("ada@example.com"))
+	if err != nil {
+		t.Fatalf("Failed to get attendee: %v", err)
+	}
+	if attendee.Fields["company"] != "Analytical Engines" || attendee.Fields["tshirtSize"] != "L" || attendee.Fields["github"] != "ada-l" {
+		t.Errorf("Expected the answers to be imported, got %v", attendee.Fields)
+	}
+}
+
+func TestImportAttendees_Multipart(t *testing.T) {
+	setupTestClient(t)
+	defer teardownTestClient()
+
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	part, _ := form.CreateFormFile("file", "attendees.csv")
+	part.Write([]byte("Name,Email,Designation\nAda Lovelace,ada@example.com,Developer\n"))
+	form.Close()
+
+	req := httptest.NewRequest("POST", "/api/admin/attendees/import", &body)
+	req.Header.Set("Content-Type", form.FormDataContentType())
+	w := httptest.NewRecorder()
+	ImportAttendees(w, req)
+	if w.Code != http.StatusOK || len(listAttendees(t)) != 1 {
+		t.Errorf("Expected the uploaded file to import 1 attendee, got %d: %s", w.Code, w.Body.String())
+	}
+}
+
+func TestImportAttendees_MalformedCSVImportsNothing(t *testing.T) {
+	setupTestClient(t)
+	defer teardownTestClient()
+
+	csv := "Name,Email,Designation\nAda Lovelace,ada@example.com,Developer\n\"Bob,bob@example.com,Designer\n"
+	if w, _ := importCSV(t, "", csv); w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400, got %d", w.Code)
+	}
+	if attendees := listAttendees(t); len(attendees) != 0 {
+		t.Errorf("Expected nothing imported, got %d attendees", len(attendees))
+	}
+}
+
+func TestImportAttendees_Notify(t *testing.T) {
+	setupTestClient(t)
+	defer teardownTestClient()
+	recorder := &mailer.Recorder{}
+	SetMailer(recorder, models.EventDetails{Name: "Test Meetup"})
+	SetEmailVerification(true, 0, "http://localhost:8080")
+
+	csv := "Name,Email,Designation\nAda Lovelace,ada@example.com,Developer\n"
+	importCSV(t, "", csv)
+	pendingMail.Wait()
+	if n := len(recorder.Messages()); n != 0 {
+		t.Errorf("Expected no email without notify, got %d", n)
+	}
+	if attendees := listAttendees(t); len(attendees) != 1 || attendees[0].Status != models.AttendeeConfirmed {
+		t.Errorf("Expected the import to skip email verification, got %+v", attendees)
+	}
+
+	importCSV(t, "?notify=true", strings.Replace(csv, "ada@", "bob@", 1))
+	pendingMail.Wait()
+	messages := recorder.Messages()
+	if len(messages) != 1 {
+		t.Fatalf("Expected 1 confirmation email with notify, got %d", len(messages))
+	}
+	match := manageTokenPattern.FindStringSubmatch(messages[0].Text)
+	if match == nil {
+		t.Fatalf("Expected the confirmation email to carry a manage token:\n%s", messages[0].Text)
+	}
+	if w := manageRequest(GetRegistration, "GET", match[1], nil); w.Code != http.StatusOK {
+		t.Errorf("Expected the emailed manage token to work, got %d", w.Code)
+	}
+}
+
+func TestImportAttendees_DryRunMatchesPendingRegistration(t *testing.T) {
+	setupTestClient(t)
+	defer teardownTestClient()
+	recorder := &mailer.Recorder{}
+	SetMailer(recorder, models.EventDetails{Name: "Test Meetup"})
+	SetEmailVerification(true, time.Hour, "http://localhost:8080")
+	registerPending(t, recorder, "ada@example.com")
+
+	csv := "Name,Email,Designation\nAda Lovelace,ada@example.com,Developer\n"
+	_, dryRun := importCSV(t, "?dryRun=true", csv)
+	_, result := importCSV(t, "", csv)
+	if dryRun.Imported != 0 || result.Imported != 0 || len(dryRun.Errors) != 1 || len(result.Errors) != 1 {
+		t.Errorf("Expected the dry run and the import to both refuse the pending address, got %+v and %+v", dryRun, result)
+	}
+}
@@ -0,0 +1,134 @@
+package handlers
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+
+	"event-registration-backend/auth"
+	"event-registration-backend/models"
+	"event-registration-backend/store"
+)
+
+// manageAttendee authenticates the manage token sent as "Authorization: Bearer
+// <token>" and returns its attendee. On failure it writes the error response
+// and returns nil.
+func manageAttendee(w http.ResponseWriter, r *http.Request) *models.Attendee {
+	id, secret, ok := auth.ParseManageToken(bearerToken(r))
+	if !ok {
+		http.Error(w, "Missing or invalid manage token", http.StatusUnauthorized)
+		return nil
+	}
+
+	attendee, err := attendeeStore.Get(r.Context(), id)
+	if err == store.ErrNotFound {
+		http.Error(w, "Missing or invalid manage token", http.StatusUnauthorized)
+		return nil
+	}
+	if err != nil {
+		http.Error(w, "Failed to get registration", http.StatusInternalServerError)
+		return nil
+	}
+	if !auth.CheckManageToken(attendee.ManageTokenHash, secret) {
+		http.Error(w, "Missing or invalid manage token", http.StatusUnauthorized)
+		return nil
+	}
+	return attendee
+}
+
+// GetRegistration returns the registration belonging to a manage token
+func GetRegistration(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	attendee := manageAttendee(w, r)
+	if attendee == nil {
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(attendee)
+}
+
+// UpdateRegistration changes the name and designation of the registration
+// belonging to a manage token. The email cannot be changed; attendees cancel
+// and register again instead.
+func UpdateRegistration(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPut {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	attendee := manageAttendee(w, r)
+	if attendee == nil {
+		return
+	}
+
+	var req models.UpdateRegistrationRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	fields := make(map[string]string)
+	var msg string
+	if req.FullName, msg = validateText(req.FullName, maxNameLength); msg != "" {
+		fields["fullName"] = msg
+	}
+	if req.Designation, msg = validateText(req.Designation, maxDesignationLength); msg != "" {
+		fields["designation"] = msg
+	}
+	if len(fields) > 0 {
+		writeValidationErrors(w, fields)
+		return
+	}
+
+	updated, err := attendeeStore.UpdateDetails(r.Context(), attendee.ID, req.FullName, req.Designation)
+	if err == store.ErrNotFound {
+		http.Error(w, "Registration is cancelled", http.StatusConflict)
+		return
+	}
+	if err != nil {
+		log.Printf("Failed to update attendee %s: %v", attendee.ID, err)
+		http.Error(w, "Failed to update registration", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(updated)
+}
+
+// CancelRegistration cancels the registration belonging to a manage token. The
+// attendee is kept with a cancellation timestamp and the first waitlisted
+// attendee takes the freed seat.
+func CancelRegistration(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	attendee := manageAttendee(w, r)
+	if attendee == nil {
+		return
+	}
+
+	cancelled, promoted, err := attendeeStore.Cancel(r.Context(), attendee.ID, eventCapacity)
+	if err == store.ErrNotFound {
+		http.Error(w, "Registration is already cancelled", http.StatusConflict)
+		return
+	}
+	if err != nil {
+		log.Printf("Failed to cancel attendee %s: %v", attendee.ID, err)
+		http.Error(w, "Failed to cancel registration", http.StatusInternalServerError)
+		return
+	}
+	invalidateResponses(CacheAttendeeCount)
+	for _, promotedAttendee := range promoted {
+		log.Printf("Promoted attendee %s from the waitlist", promotedAttendee.ID)
+		sendConfirmation(promotedAttendee)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(cancelled)
+}
@@ -0,0 +1,119 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"event-registration-backend/models"
+)
+
+// manageRequest calls handler with the manage token and optional JSON body
+func manageRequest(handler http.HandlerFunc, method, token string, body interface{}) *httptest.ResponseRecorder {
+	var buf bytes.Buffer
+	if body != nil {
+		json.NewEncoder(&buf).Encode(body)
+	}
+	req := httptest.NewRequest(method, "/api/attendees/manage", &buf)
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	w := httptest.NewRecorder()
+	handler(w, req)
+	return w
+}
+
+func TestRegisterAttendee_ReturnsManageToken(t *testing.T) {
+	setupTestClient(t)
+	defer teardownTestClient()
+
+	attendee := registerAttendee(t, "jane@example.com")
+	if attendee.ManageToken == "" {
+		t.Fatal("Expected a manage token in the registration response")
+	}
+
+	w := manageRequest(GetRegistration, "GET", attendee.ManageToken, nil)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", w.Code)
+	}
+	var viewed map[string]interface{}
+	json.Unmarshal(w.Body.Bytes(), &viewed)
+	if viewed["email"] != "jane@example.com" {
+		t.Errorf("Expected the registration, got %v", viewed)
+	}
+	if _, ok := viewed["manageToken"]; ok {
+		t.Error("Expected the manage token not to be returned again")
+	}
+}
+
+func TestManageRegistration_InvalidToken(t *testing.T) {
+	setupTestClient(t)
+	defer teardownTestClient()
+	attendee := registerAttendee(t, "jane@example.com")
+
+	for name, token := range map[string]string{
+		"missing":      "",
+		"malformed":    "not-a-token",
+		"wrong secret": attendee.ID + ".0000",
+		"unknown id":   "missing." + attendee.ManageToken,
+	} {
+		if w := manageRequest(GetRegistration, "GET", token, nil); w.Code != http.StatusUnauthorized {
+			t.Errorf("%s: expected status 401, got %d", name, w.Code)
+		}
+	}
+}
+
+func TestUpdateRegistration(t *testing.T) {
+	setupTestClient(t)
+	defer teardownTestClient()
+	attendee := registerAttendee(t, "jane@example.com")
+
+	w := manageRequest(UpdateRegistration, "PUT", attendee.ManageToken, models.UpdateRegistrationRequest{FullName: " Jane Smith ", Designation: "Manager"})
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var updated models.Attendee
+	json.Unmarshal(w.Body.Bytes(), &updated)
+	if updated.FullName != "Jane Smith" || updated.Designation != "Manager" || updated.Email != "jane@example.com" {
+		t.Errorf("Unexpected updated registration: %+v", updated)
+	}
+
+	w = manageRequest(UpdateRegistration, "PUT", attendee.ManageToken, models.UpdateRegistrationRequest{FullName: "Jane"})
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400 without a designation, got %d", w.Code)
+	}
+}
+
+func TestCancelRegistration(t *testing.T) {
+	setupTestClient(t)
+	defer teardownTestClient()
+	SetEventCapacity(1)
+	attendee := registerAttendee(t, "jane@example.com")
+	waitlisted := registerAttendee(t, "john@example.com")
+
+	w := manageRequest(CancelRegistration, "POST", attendee.ManageToken, nil)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", w.Code)
+	}
+	var cancelled models.Attendee
+	json.Unmarshal(w.Body.Bytes(), &cancelled)
+	if cancelled.Status != models.AttendeeCancelled || cancelled.CancelledAt == nil {
+		t.Errorf("Expected a soft-deleted registration, got %+v", cancelled)
+	}
+
+	if count := fetchAttendeeCount(t); count.Confirmed != 1 || count.Waitlisted != 0 {
+		t.Errorf("Expected the waitlisted attendee promoted, got %+v", count)
+	}
+	if w := manageRequest(GetRegistration, "GET", waitlisted.ManageToken, nil); !bytes.Contains(w.Body.Bytes(), []byte(`"status":"confirmed"`)) {
+		t.Errorf("Expected the waitlisted attendee confirmed, got %s", w.Body.String())
+	}
+
+	if w := manageRequest(CancelRegistration, "POST", attendee.ManageToken, nil); w.Code != http.StatusConflict {
+		t.Errorf("Expected status 409 cancelling twice, got %d", w.Code)
+	}
+	if w := manageRequest(UpdateRegistration, "PUT", attendee.ManageToken, models.UpdateRegistrationRequest{FullName: "Jane", Designation: "Dev"}); w.Code != http.StatusConflict {
+		t.Errorf("Expected status 409 updating a cancelled registration, got %d", w.Code)
+	}
+}
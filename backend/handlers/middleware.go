@@ -0,0 +1,153 @@
+package handlers
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"strings"
+
+	"event-registration-backend/auth"
+	"event-registration-backend/firestore"
+)
+
+type contextKey string
+
+// trustProxyHeaders makes clientIP use X-Forwarded-For instead of the connection address
+var trustProxyHeaders bool
+
+// SetTrustProxyHeaders controls whether X-Forwarded-For is trusted for client IPs
+func SetTrustProxyHeaders(trust bool) {
+	trustProxyHeaders = trust
+}
+
+const adminClaimsKey contextKey = "adminClaims"
+
+// AdminClaimsFromContext returns the session claims stored by RequireAdmin
+func AdminClaimsFromContext(ctx context.Context) (*auth.Claims, bool) {
+	claims, ok := ctx.Value(adminClaimsKey).(*auth.Claims)
+	return claims, ok
+}
+
+// RequireAdmin rejects requests whose Authorization header does not carry
+// either a valid, unexpired and unrevoked admin session token or an active API key
+func RequireAdmin(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token := bearerToken(r)
+		if token == "" {
+			unauthorized(w, "Missing authorization token")
+			return
+		}
+
+		var claims *auth.Claims
+		if auth.IsAPIKey(token) {
+			var err error
+			claims, err = apiKeyClaims(r.Context(), token)
+			if err == auth.ErrInvalidToken {
+				unauthorized(w, "Invalid API key")
+				return
+			}
+			if err != nil {
+				http.Error(w, "Failed to verify API key", http.StatusInternalServerError)
+				return
+			}
+		} else {
+			claims = sessionClaims(w, r, token)
+			if claims == nil {
+				return
+			}
+		}
+
+		ctx := context.WithValue(r.Context(), adminClaimsKey, claims)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// sessionClaims verifies a session token, writing the error response and
+// returning nil if it is invalid, expired or revoked. Sessions of local
+// accounts take the account's current role, and end when the account is gone
+// or its password has changed since they were issued.
+func sessionClaims(w http.ResponseWriter, r *http.Request, token string) *auth.Claims {
+	claims, err := auth.ParseToken(sessionSecret, token)
+	if err == auth.ErrExpiredToken {
+		unauthorized(w, "Token expired")
+		return nil
+	}
+	if err != nil {
+		unauthorized(w, "Invalid token")
+		return nil
+	}
+
+	_, err = firestore.GetRevokedTokensCollection().Doc(claims.TokenID).Get(r.Context())
+	if err == nil {
+		unauthorized(w, "Token revoked")
+		return nil
+	}
+	if err != firestore.ErrNotFound {
+		http.Error(w, "Failed to verify token", http.StatusInternalServerError)
+		return nil
+	}
+
+	// Single sign-on roles come from the identity provider at login
+	if strings.HasPrefix(claims.Subject, oidcSubjectPrefix) {
+		return claims
+	}
+	user, err := getAdminUser(r.Context(), claims.Subject)
+	if err == firestore.ErrNotFound || (err == nil && user.SessionGeneration != claims.Generation) {
+		unauthorized(w, "Session ended, log in again")
+		return nil
+	}
+	if err != nil {
+		http.Error(w, "Failed to verify token", http.StatusInternalServerError)
+		return nil
+	}
+	claims.Role = user.Role
+	return claims
+}
+
+// RequirePermission wraps next so it only runs when the authenticated admin's
+// role, or the API key's scopes, grant permission. It must be used behind RequireAdmin.
+func RequirePermission(permission auth.Permission, next http.HandlerFunc) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		claims, ok := AdminClaimsFromContext(r.Context())
+		if !ok {
+			unauthorized(w, "Missing authorization token")
+			return
+		}
+		if !claims.Has(permission) {
+			http.Error(w, "Forbidden: missing permission "+string(permission), http.StatusForbidden)
+			return
+		}
+		next(w, r)
+	})
+}
+
+// bearerToken extracts the token from an "Authorization: Bearer <token>" header
+func bearerToken(r *http.Request) string {
+	header := r.Header.Get("Authorization")
+	const prefix = "Bearer "
+	if len(header) < len(prefix) || !strings.EqualFold(header[:len(prefix)], prefix) {
+		return ""
+	}
+	return strings.TrimSpace(header[len(prefix):])
+}
+
+func unauthorized(w http.ResponseWriter, message string) {
+	w.Header().Set("WWW-Authenticate", `Bearer realm="admin"`)
+	http.Error(w, message, http.StatusUnauthorized)
+}
+
+// clientIP returns the caller's IP address. Behind a trusted proxy this is the
+// right-most X-Forwarded-For entry, the one appended by the proxy itself.
+func clientIP(r *http.Request) string {
+	if trustProxyHeaders {
+		if forwarded := r.Header.Get("X-Forwarded-For"); forwarded != "" {
+			parts := strings.Split(forwarded, ",")
+			return strings.TrimSpace(parts[len(parts)-1])
+		}
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
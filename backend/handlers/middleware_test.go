@@ -0,0 +1,276 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"event-registration-backend/auth"
+	"event-registration-backend/models"
+)
+
+// protectedHandler wraps a trivial handler with RequireAdmin
+func protectedHandler() http.Handler {
+	return RequireAdmin(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, ok := AdminClaimsFromContext(r.Context()); !ok {
+			http.Error(w, "claims missing from context", http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+}
+
+// loginToken logs in with the given credentials and returns the issued token
+func loginToken(t *testing.T, username, password string) string {
+	t.Helper()
+	body, _ := json.Marshal(LoginRequest{Username: username, Password: password})
+	req := httptest.NewRequest("POST", "/api/admin/login", bytes.NewBuffer(body))
+	w := httptest.NewRecorder()
+	AdminLogin(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Login failed with status %d", w.Code)
+	}
+	var response LoginResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+	if response.Token == "" {
+		t.Fatal("Expected login to return a token")
+	}
+	return response.Token
+}
+
+func TestRequireAdmin_ValidToken(t *testing.T) {
+	setupTestClient(t)
+	defer teardownTestClient()
+	createTestAdmin(t, "alice", "testpassword123")
+
+	token := loginToken(t, "alice", "testpassword123")
+
+	req := httptest.NewRequest("GET", "/api/admin/attendees", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	w := httptest.NewRecorder()
+	protectedHandler().ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Errorf("Expected status 200, got %d", w.Code)
+	}
+}
+
+func TestRequireAdmin_Rejected(t *testing.T) {
+	setupTestClient(t)
+	defer teardownTestClient()
+
+	expired, _, _ := auth.IssueToken([]byte("test-session-secret"), "alice", auth.RoleOwner, 0, -time.Minute)
+	forged, _, _ := auth.IssueToken([]byte("some-other-secret"), "alice", auth.RoleOwner, 0, time.Hour)
+
+	testCases := []struct {
+		name   string
+		header string
+	}{
+		{"Missing header", ""},
+		{"Wrong scheme", "Basic YWRtaW46YWRtaW4="},
+		{"Garbage token", "Bearer not-a-token"},
+		{"Forged token", "Bearer " + forged},
+		{"Expired token", "Bearer " + expired},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/api/admin/attendees", nil)
+			if tc.header != "" {
+				req.Header.Set("Authorization", tc.header)
+			}
+			w := httptest.NewRecorder()
+			protectedHandler().ServeHTTP(w, req)
+
+			if w.Code != http.StatusUnauthorized {
+				t.Errorf("Expected status 401, got %d", w.Code)
+			}
+		})
+	}
+}
+
+func TestAdminLogout_RevokesToken(t *testing.T) {
+	setupTestClient(t)
+	defer teardownTestClient()
+	createTestAdmin(t, "alice", "testpassword123")
+
+	token := loginToken(t, "alice", "testpassword123")
+
+	req := httptest.NewRequest("POST", "/api/admin/logout", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	w := httptest.NewRecorder()
+	RequireAdmin(http.HandlerFunc(AdminLogout)).ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200 for logout, got %d", w.Code)
+	}
+
+	req2 := httptest.NewRequest("GET", "/api/admin/attendees", nil)
+	req2.Header.Set("Authorization", "Bearer "+token)
+	w2 := httptest.NewRecorder()
+	protectedHandler().ServeHTTP(w2, req2)
+
+	if w2.Code != http.StatusUnauthorized {
+		t.Errorf("Expected status 401 after logout, got %d", w2.Code)
+	}
+}
+
+func TestRevokeToken_LeakedToken(t *testing.T) {
+	setupTestClient(t)
+	defer teardownTestClient()
+	createTestAdmin(t, "alice", "testpassword123")
+
+	token := loginToken(t, "alice", "testpassword123")
+	leaked := loginToken(t, "alice", "testpassword123")
+
+	body, _ := json.Marshal(RevokeTokenRequest{Token: leaked})
+	req := httptest.NewRequest("POST", "/api/admin/tokens/revoke", bytes.NewBuffer(body))
+	req.Header.Set("Authorization", "Bearer "+token)
+	w := httptest.NewRecorder()
+	RequireAdmin(http.HandlerFunc(RevokeToken)).ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200 for revoke, got %d", w.Code)
+	}
+
+	for name, tok := range map[string]string{"leaked": leaked, "caller": token} {
+		req := httptest.NewRequest("GET", "/api/admin/attendees", nil)
+		req.Header.Set("Authorization", "Bearer "+tok)
+		w := httptest.NewRecorder()
+		protectedHandler().ServeHTTP(w, req)
+
+		expected := http.StatusOK
+		if name == "leaked" {
+			expected = http.StatusUnauthorized
+		}
+		if w.Code != expected {
+			t.Errorf("%s token: expected status %d, got %d", name, expected, w.Code)
+		}
+	}
+}
+
+func TestRevokeToken_InvalidToken(t *testing.T) {
+	setupTestClient(t)
+	defer teardownTestClient()
+
+	body, _ := json.Marshal(RevokeTokenRequest{Token: "garbage"})
+	req := httptest.NewRequest("POST", "/api/admin/tokens/revoke", bytes.NewBuffer(body))
+	w := httptest.NewRecorder()
+	RevokeToken(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400, got %d", w.Code)
+	}
+}
+
+func TestRequirePermission(t *testing.T) {
+	setupTestClient(t)
+	defer teardownTestClient()
+	createTestAdminWithRole(t, "viewer", "viewer-password", auth.RoleViewer)
+	createTestAdminWithRole(t, "editor", "editor-password", auth.RoleEditor)
+
+	ok := func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusOK) }
+
+	testCases := []struct {
+		name           string
+		username       string
+		password       string
+		permission     auth.Permission
+		expectedStatus int
+	}{
+		{"Viewer reads attendees", "viewer", "viewer-password", auth.PermissionAttendeesRead, http.StatusOK},
+		{"Viewer writes sessions", "viewer", "viewer-password", auth.PermissionSessionsWrite, http.StatusForbidden},
+		{"Editor writes speakers", "editor", "editor-password", auth.PermissionSpeakersWrite, http.StatusOK},
+		{"Editor manages admins", "editor", "editor-password", auth.PermissionAdminsManage, http.StatusForbidden},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			token := loginToken(t, tc.username, tc.password)
+
+			req := httptest.NewRequest("POST", "/api/admin/anything", nil)
+			req.Header.Set("Authorization", "Bearer "+token)
+			w := httptest.NewRecorder()
+			RequireAdmin(RequirePermission(tc.permission, ok)).ServeHTTP(w, req)
+
+			if w.Code != tc.expectedStatus {
+				t.Errorf("Expected status %d, got %d", tc.expectedStatus, w.Code)
+			}
+			if w.Code == http.StatusForbidden && !strings.Contains(w.Body.String(), string(tc.permission)) {
+				t.Errorf("Expected 403 body to name %s, got %q", tc.permission, w.Body.String())
+			}
+		})
+	}
+}
+
+// saveAdminUserRequest posts req to CreateOrUpdateAdminUser and returns the response
+func saveAdminUserRequest(req models.AdminUserRequest) *httptest.ResponseRecorder {
+	body, _ := json.Marshal(req)
+	w := httptest.NewRecorder()
+	CreateOrUpdateAdminUser(w, httptest.NewRequest("POST", "/api/admin/users", bytes.NewBuffer(body)))
+	return w
+}
+
+// requestWithPermission calls a handler requiring permission with token
+func requestWithPermission(token string, permission auth.Permission) int {
+	req := httptest.NewRequest("POST", "/api/admin/anything", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	w := httptest.NewRecorder()
+	RequireAdmin(RequirePermission(permission, func(w http.ResponseWriter, r *http.Request) {})).ServeHTTP(w, req)
+	return w.Code
+}
+
+func TestRequireAdmin_AccountChanges(t *testing.T) {
+	setupTestClient(t)
+	defer teardownTestClient()
+	createTestAdminWithRole(t, "owner", "owner-password", auth.RoleOwner)
+	createTestAdminWithRole(t, "editor", "editor-password", auth.RoleEditor)
+	token := loginToken(t, "editor", "editor-password")
+
+	// Demoting takes effect on the live session
+	if w := saveAdminUserRequest(models.AdminUserRequest{Username: "editor", Password: "editor-password", Role: "viewer"}); w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200 demoting, got %d", w.Code)
+	}
+	if code := requestWithPermission(token, auth.PermissionSpeakersWrite); code != http.StatusForbidden {
+		t.Errorf("Expected the demoted session to lose write access, got %d", code)
+	}
+	if code := requestWithPermission(token, auth.PermissionAttendeesRead); code != http.StatusOK {
+		t.Errorf("Expected the session to survive a role change, got %d", code)
+	}
+
+	// A password-only update keeps the role and ends existing sessions
+	if w := saveAdminUserRequest(models.AdminUserRequest{Username: "editor", Password: "new-editor-password"}); w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200 resetting the password, got %d", w.Code)
+	}
+	if user, _ := getAdminUser(context.Background(), "editor"); user.Role != auth.RoleViewer {
+		t.Errorf("Expected an omitted role to be kept, got %s", user.Role)
+	}
+	if code := requestWithPermission(token, auth.PermissionAttendeesRead); code != http.StatusUnauthorized {
+		t.Errorf("Expected the session to end after a password reset, got %d", code)
+	}
+	if code := requestWithPermission(loginToken(t, "editor", "new-editor-password"), auth.PermissionAttendeesRead); code != http.StatusOK {
+		t.Errorf("Expected a new session to work, got %d", code)
+	}
+}
+
+func TestCreateOrUpdateAdminUser_LastOwner(t *testing.T) {
+	setupTestClient(t)
+	defer teardownTestClient()
+	createTestAdminWithRole(t, "owner", "owner-password", auth.RoleOwner)
+
+	if w := saveAdminUserRequest(models.AdminUserRequest{Username: "owner", Password: "owner-password", Role: "editor"}); w.Code != http.StatusConflict {
+		t.Errorf("Expected status 409 demoting the last owner, got %d", w.Code)
+	}
+	saveAdminUserRequest(models.AdminUserRequest{Username: "second", Password: "second-password", Role: "owner"})
+	if w := saveAdminUserRequest(models.AdminUserRequest{Username: "owner", Password: "owner-password", Role: "editor"}); w.Code != http.StatusOK {
+		t.Errorf("Expected status 200 demoting one of two owners, got %d", w.Code)
+	}
+}
@@ -0,0 +1,213 @@
+package handlers
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"event-registration-backend/auth"
+	"event-registration-backend/firestore"
+	"event-registration-backend/models"
+)
+
+// oidcFlowTTL bounds how long a user may take at the identity provider
+const oidcFlowTTL = 10 * time.Minute
+
+// oidcStateCookie ties a sign-on flow to the browser that started it, so a
+// callback URL cannot be completed in another browser
+const oidcStateCookie = "oidc_state"
+
+// oidcSubjectPrefix marks session subjects of single sign-on users. Local
+// usernames cannot contain ':', so an SSO email never names a local account.
+const oidcSubjectPrefix = "oidc:"
+
+// maxExpiredOIDCFlowsPurged bounds the expired flows deleted per sign-on
+const maxExpiredOIDCFlowsPurged = 100
+
+var (
+	oidcProvider          *auth.OIDCProvider
+	oidcPostLoginRedirect string
+)
+
+// SetOIDCProvider enables single sign-on. If postLoginRedirect is set the
+// callback redirects there with the session token in the URL fragment,
+// otherwise it responds with a LoginResponse.
+func SetOIDCProvider(provider *auth.OIDCProvider, postLoginRedirect string) {
+	oidcProvider = provider
+	oidcPostLoginRedirect = postLoginRedirect
+}
+
+// OIDCLogin starts the authorization-code flow by redirecting to the identity provider
+func OIDCLogin(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if oidcProvider == nil {
+		http.Error(w, "Single sign-on is not configured", http.StatusNotFound)
+		return
+	}
+
+	state, err := auth.RandomID(16)
+	if err != nil {
+		http.Error(w, "Failed to start sign-on", http.StatusInternalServerError)
+		return
+	}
+	nonce, err := auth.RandomID(16)
+	if err != nil {
+		http.Error(w, "Failed to start sign-on", http.StatusInternalServerError)
+		return
+	}
+
+	flow := models.OIDCFlow{
+		Nonce:     nonce,
+		Verifier:  auth.NewPKCEVerifier(),
+		ExpiresAt: time.Now().Add(oidcFlowTTL),
+	}
+	if _, err := firestore.GetOIDCFlowsCollection().Doc(state).Set(r.Context(), flow); err != nil {
+		http.Error(w, "Failed to start sign-on", http.StatusInternalServerError)
+		return
+	}
+	purgeExpiredOIDCFlows(r.Context())
+
+	http.SetCookie(w, oidcStateCookieFor(r, state, int(oidcFlowTTL.Seconds())))
+	http.Redirect(w, r, oidcProvider.AuthCodeURL(state, flow.Nonce, flow.Verifier), http.StatusFound)
+}
+
+// OIDCCallback completes the flow and issues an admin session token
+func OIDCCallback(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if oidcProvider == nil {
+		http.Error(w, "Single sign-on is not configured", http.StatusNotFound)
+		return
+	}
+
+	q := r.URL.Query()
+	if errCode := q.Get("error"); errCode != "" {
+		http.Error(w, "Sign-on failed: "+errCode, http.StatusUnauthorized)
+		return
+	}
+	state, code := q.Get("state"), q.Get("code")
+	if state == "" || code == "" {
+		http.Error(w, "Missing state or code", http.StatusBadRequest)
+		return
+	}
+	cookie, err := r.Cookie(oidcStateCookie)
+	if err != nil || subtle.ConstantTimeCompare([]byte(cookie.Value), []byte(state)) != 1 {
+		http.Error(w, "Sign-on was not started in this browser", http.StatusBadRequest)
+		return
+	}
+	http.SetCookie(w, oidcStateCookieFor(r, "", -1))
+
+	// Each flow may only be completed once: reading and deleting it in one
+	// transaction lets only one of several concurrent callbacks through
+	ctx := r.Context()
+	flowRef := firestore.GetOIDCFlowsCollection().Doc(state)
+	var flow models.OIDCFlow
+	err = firestore.RunTransaction(ctx, func(ctx context.Context, tx firestore.TransactionInterface) error {
+		doc, err := tx.Get(flowRef)
+		if err != nil {
+			return err
+		}
+		flow = models.OIDCFlow{}
+		if err := doc.DataTo(&flow); err != nil {
+			return err
+		}
+		return tx.Delete(flowRef)
+	})
+	if err == firestore.ErrNotFound {
+		http.Error(w, "Unknown or already used sign-on state", http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		http.Error(w, "Failed to complete sign-on", http.StatusInternalServerError)
+		return
+	}
+	if time.Now().After(flow.ExpiresAt) {
+		http.Error(w, "Sign-on attempt expired", http.StatusBadRequest)
+		return
+	}
+
+	identity, err := oidcProvider.Exchange(ctx, code, flow.Verifier, flow.Nonce)
+	if errors.Is(err, auth.ErrOIDCAccessDenied) {
+		log.Printf("OIDC sign-on denied: %v", err)
+		http.Error(w, "Forbidden: "+err.Error(), http.StatusForbidden)
+		return
+	}
+	if err != nil {
+		log.Printf("OIDC sign-on failed: %v", err)
+		http.Error(w, "Sign-on failed", http.StatusUnauthorized)
+		return
+	}
+
+	token, claims, err := auth.IssueToken(sessionSecret, oidcSubjectPrefix+strings.ToLower(identity.Email), identity.Role, 0, sessionTTL)
+	if err != nil {
+		http.Error(w, "Failed to issue session token", http.StatusInternalServerError)
+		return
+	}
+
+	if oidcPostLoginRedirect != "" {
+		fragment := url.Values{"token": {token}, "role": {string(claims.Role)}}
+		http.Redirect(w, r, oidcPostLoginRedirect+"#"+fragment.Encode(), http.StatusFound)
+		return
+	}
+
+	expiresAt := claims.ExpiresAtTime()
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(LoginResponse{
+		Success:   true,
+		Message:   "Login successful",
+		Token:     token,
+		Role:      claims.Role,
+		ExpiresAt: &expiresAt,
+	})
+}
+
+// purgeExpiredOIDCFlows deletes flows that were never completed. Failures are
+// only logged, since a leftover flow can no longer be used.
+func purgeExpiredOIDCFlows(ctx context.Context) {
+	docs, err := firestore.GetOIDCFlowsCollection().
+		Where("expiresAt", "<", time.Now()).
+		Limit(maxExpiredOIDCFlowsPurged).
+		Documents(ctx).GetAll()
+	if err != nil {
+		log.Printf("Failed to list expired sign-on flows: %v", err)
+		return
+	}
+	for _, doc := range docs {
+		if err := doc.GetRef().Delete(ctx); err != nil {
+			log.Printf("Failed to delete expired sign-on flow: %v", err)
+			return
+		}
+	}
+}
+
+// oidcStateCookieFor returns the state cookie for r's sign-on flow; a
+// negative maxAge clears it
+func oidcStateCookieFor(r *http.Request, state string, maxAge int) *http.Cookie {
+	return &http.Cookie{
+		Name:     oidcStateCookie,
+		Value:    state,
+		Path:     "/api/admin/oidc",
+		MaxAge:   maxAge,
+		HttpOnly: true,
+		Secure:   isHTTPS(r),
+		// Lax still sends the cookie on the identity provider's redirect back
+		SameSite: http.SameSiteLaxMode,
+	}
+}
+
+// isHTTPS reports whether the client reached us over HTTPS, directly or
+// through a proxy that sets X-Forwarded-Proto
+func isHTTPS(r *http.Request) bool {
+	return r.TLS != nil || r.Header.Get("X-Forwarded-Proto") == "https"
+}
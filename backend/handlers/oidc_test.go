@@ -0,0 +1,207 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"event-registration-backend/auth"
+	"event-registration-backend/auth/oidctest"
+	"event-registration-backend/firestore"
+	"event-registration-backend/models"
+)
+
+// setupTestOIDC points the handlers at a fake issuer
+func setupTestOIDC(t *testing.T) *oidctest.Issuer {
+	t.Helper()
+	issuer := oidctest.NewIssuer(t, "event-admin")
+	provider, err := auth.NewOIDCProvider(context.Background(), auth.OIDCConfig{
+		IssuerURL:      issuer.URL(),
+		ClientID:       "event-admin",
+		RedirectURL:    "http://localhost:8080/api/admin/oidc/callback",
+		AllowedDomains: []string{"example.com"},
+		RoleMapping:    map[string]auth.Role{"event-editors": auth.RoleEditor},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create provider: %v", err)
+	}
+	SetOIDCProvider(provider, "")
+	t.Cleanup(func() { SetOIDCProvider(nil, "") })
+	return issuer
+}
+
+// runOIDCFlow starts sign-on, follows the fake issuer and returns the callback
+// URL with the state cookie set by the login
+func runOIDCFlow(t *testing.T, issuer *oidctest.Issuer) (string, *http.Cookie) {
+	t.Helper()
+	req := httptest.NewRequest("GET", "/api/admin/oidc/login", nil)
+	w := httptest.NewRecorder()
+	OIDCLogin(w, req)
+
+	if w.Code != http.StatusFound {
+		t.Fatalf("Expected status 302, got %d", w.Code)
+	}
+	callback, err := issuer.Authorize(w.Header().Get("Location"))
+	if err != nil {
+		t.Fatalf("Authorize failed: %v", err)
+	}
+	cookies := w.Result().Cookies()
+	if len(cookies) != 1 || cookies[0].Name != oidcStateCookie || !cookies[0].HttpOnly || cookies[0].SameSite != http.SameSiteLaxMode {
+		t.Fatalf("Expected an HttpOnly SameSite=Lax state cookie, got %v", cookies)
+	}
+	return callback, cookies[0]
+}
+
+// callOIDCCallback completes sign-on, sending cookie unless it is nil
+func callOIDCCallback(callback string, cookie *http.Cookie) *httptest.ResponseRecorder {
+	u, _ := url.Parse(callback)
+	req := httptest.NewRequest("GET", "/api/admin/oidc/callback?"+u.RawQuery, nil)
+	if cookie != nil {
+		req.AddCookie(cookie)
+	}
+	w := httptest.NewRecorder()
+	OIDCCallback(w, req)
+	return w
+}
+
+func TestOIDCFlow_IssuesSessionToken(t *testing.T) {
+	setupTestClient(t)
+	defer teardownTestClient()
+	issuer := setupTestOIDC(t)
+	issuer.SetUser(map[string]interface{}{"sub": "u1", "email": "ed@example.com", "groups": []string{"event-editors"}})
+
+	w := callOIDCCallback(runOIDCFlow(t, issuer))
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	var response LoginResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+	if response.Role != auth.RoleEditor {
+		t.Errorf("Expected role editor, got %q", response.Role)
+	}
+	// SSO subjects cannot collide with local usernames, which may contain '@'
+	if claims, err := auth.ParseToken(sessionSecret, response.Token); err != nil || claims.Subject != "oidc:ed@example.com" {
+		t.Errorf("Expected subject oidc:ed@example.com, got %+v %v", claims, err)
+	}
+
+	if code := callWithBearer(response.Token, auth.PermissionSessionsWrite); code != http.StatusOK {
+		t.Errorf("Expected SSO token to grant editor access, got %d", code)
+	}
+	if code := callWithBearer(response.Token, auth.PermissionAdminsManage); code != http.StatusForbidden {
+		t.Errorf("Expected SSO editor to lack owner permissions, got %d", code)
+	}
+}
+
+func TestOIDCFlow_StateSingleUse(t *testing.T) {
+	setupTestClient(t)
+	defer teardownTestClient()
+	issuer := setupTestOIDC(t)
+	issuer.SetUser(map[string]interface{}{"sub": "u1", "email": "ed@example.com", "groups": []string{"event-editors"}})
+
+	callback, cookie := runOIDCFlow(t, issuer)
+	callOIDCCallback(callback, cookie)
+
+	if w := callOIDCCallback(callback, cookie); w.Code != http.StatusBadRequest {
+		t.Errorf("Expected replayed callback to be rejected with 400, got %d", w.Code)
+	}
+}
+
+func TestOIDCFlow_ConcurrentCallbacks(t *testing.T) {
+	setupTestClient(t)
+	defer teardownTestClient()
+	issuer := setupTestOIDC(t)
+	issuer.SetUser(map[string]interface{}{"sub": "u1", "email": "ed@example.com", "groups": []string{"event-editors"}})
+
+	callback, cookie := runOIDCFlow(t, issuer)
+	codes := make(chan int, 5)
+	var wg sync.WaitGroup
+	for i := 0; i < cap(codes); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			codes <- callOIDCCallback(callback, cookie).Code
+		}()
+	}
+	wg.Wait()
+	close(codes)
+
+	succeeded := 0
+	for code := range codes {
+		if code == http.StatusOK {
+			succeeded++
+		}
+	}
+	if succeeded != 1 {
+		t.Errorf("Expected exactly one of the concurrent callbacks to sign on, got %d", succeeded)
+	}
+}
+
+func TestOIDCFlow_RequiresStateCookie(t *testing.T) {
+	setupTestClient(t)
+	defer teardownTestClient()
+	issuer := setupTestOIDC(t)
+	issuer.SetUser(map[string]interface{}{"sub": "u1", "email": "ed@example.com", "groups": []string{"event-editors"}})
+
+	// A callback URL forwarded to another browser lacks the state cookie
+	callback, cookie := runOIDCFlow(t, issuer)
+	if w := callOIDCCallback(callback, nil); w.Code != http.StatusBadRequest {
+		t.Errorf("Expected a callback without the state cookie to be rejected with 400, got %d", w.Code)
+	}
+	_, other := runOIDCFlow(t, issuer)
+	if w := callOIDCCallback(callback, other); w.Code != http.StatusBadRequest {
+		t.Errorf("Expected another flow's state cookie to be rejected with 400, got %d", w.Code)
+	}
+	if w := callOIDCCallback(callback, cookie); w.Code != http.StatusOK {
+		t.Errorf("Expected the starting browser to complete sign-on, got %d: %s", w.Code, w.Body.String())
+	}
+}
+
+func TestOIDCLogin_PurgesExpiredFlows(t *testing.T) {
+	setupTestClient(t)
+	defer teardownTestClient()
+	issuer := setupTestOIDC(t)
+	ctx := context.Background()
+
+	flows := firestore.GetOIDCFlowsCollection()
+	stale := models.OIDCFlow{Nonce: "n", Verifier: "v", ExpiresAt: time.Now().Add(-time.Minute)}
+	if _, err := flows.Doc("abandoned").Set(ctx, stale); err != nil {
+		t.Fatalf("Failed to store flow: %v", err)
+	}
+
+	runOIDCFlow(t, issuer)
+	if _, err := flows.Doc("abandoned").Get(ctx); err != firestore.ErrNotFound {
+		t.Errorf("Expected the expired flow to be deleted, got %v", err)
+	}
+	if docs, _ := flows.Documents(ctx).GetAll(); len(docs) != 1 {
+		t.Errorf("Expected only the new flow to remain, got %d flows", len(docs))
+	}
+}
+
+func TestOIDCFlow_DeniedDomain(t *testing.T) {
+	setupTestClient(t)
+	defer teardownTestClient()
+	issuer := setupTestOIDC(t)
+	issuer.SetUser(map[string]interface{}{"sub": "u2", "email": "eve@elsewhere.com", "groups": []string{"event-editors"}})
+
+	if w := callOIDCCallback(runOIDCFlow(t, issuer)); w.Code != http.StatusForbidden {
+		t.Errorf("Expected status 403, got %d", w.Code)
+	}
+}
+
+func TestOIDCLogin_NotConfigured(t *testing.T) {
+	req := httptest.NewRequest("GET", "/api/admin/oidc/login", nil)
+	w := httptest.NewRecorder()
+	OIDCLogin(w, req)
+
+	if w.Code != http.StatusNotFound {
+		t.Errorf("Expected status 404, got %d", w.Code)
+	}
+}
@@ -1,11 +1,12 @@
 package handlers
 
 import (
+	"context"
 	"encoding/json"
 	"net/http"
 
-	"event-registration-backend/firestore"
 	"event-registration-backend/models"
+	"event-registration-backend/store"
 )
 
 // GetSessions returns all sessions with speaker details
@@ -15,53 +16,46 @@ func GetSessions(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	ctx := r.Context()
-	sessionsRef := firestore.GetSessionsCollection()
-	sessionsDocs, err := sessionsRef.Documents(ctx).GetAll()
+	sessions, err := loadAgenda(r.Context())
 	if err != nil {
 		http.Error(w, "Failed to get sessions", http.StatusInternalServerError)
 		return
 	}
 
-	speakersRef := firestore.GetSpeakersCollection()
-	speakersDocs, err := speakersRef.Documents(ctx).GetAll()
+	// Ensure we return an empty array, not null
+	if sessions == nil {
+		sessions = []models.Session{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(sessions)
+}
+
+// loadAgenda returns all sessions with their speaker attached
+func loadAgenda(ctx context.Context) ([]models.Session, error) {
+	sessions, err := sessionStore.List(ctx)
 	if err != nil {
-		http.Error(w, "Failed to get speakers", http.StatusInternalServerError)
-		return
+		return nil, err
+	}
+
+	speakers, err := speakerStore.List(ctx)
+	if err != nil {
+		return nil, err
 	}
 
 	// Create speakers map
 	speakersMap := make(map[string]*models.Speaker)
-	for _, doc := range speakersDocs {
-		var speaker models.Speaker
-		if err := doc.DataTo(&speaker); err != nil {
-			continue
-		}
-		speaker.ID = doc.GetID()
-		speakersMap[speaker.ID] = &speaker
+	for i := range speakers {
+		speakersMap[speakers[i].ID] = &speakers[i]
 	}
 
-	// Build sessions with speaker details
-	var sessions []models.Session
-	for _, doc := range sessionsDocs {
-		var session models.Session
-		if err := doc.DataTo(&session); err != nil {
-			continue
+	// Attach speaker details to sessions
+	for i := range sessions {
+		if speaker, ok := speakersMap[sessions[i].SpeakerID]; ok {
+			sessions[i].Speaker = speaker
 		}
-		session.ID = doc.GetID()
-		if speaker, ok := speakersMap[session.SpeakerID]; ok {
-			session.Speaker = speaker
-		}
-		sessions = append(sessions, session)
 	}
-
-	// Ensure we return an empty array, not null
-	if sessions == nil {
-		sessions = []models.Session{}
-	}
-
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(sessions)
+	return sessions, nil
 }
 
 // CreateOrUpdateSession creates or updates a session (admin only)
@@ -84,7 +78,6 @@ func CreateOrUpdateSession(w http.ResponseWriter, r *http.Request) {
 	}
 
 	ctx := r.Context()
-	sessionsRef := firestore.GetSessionsCollection()
 
 	session := models.Session{
 		Title:       req.Title,
@@ -94,24 +87,35 @@ func CreateOrUpdateSession(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if req.ID != "" {
-		// Update existing session
-		_, err := sessionsRef.Doc(req.ID).Set(ctx, session)
-		if err != nil {
+		// Keep the previous version for the audit log
+		before, err := sessionStore.Get(ctx, req.ID)
+		if err != nil && err != store.ErrNotFound {
 			http.Error(w, "Failed to update session", http.StatusInternalServerError)
 			return
 		}
+
+		// Update existing session
 		session.ID = req.ID
+		if err := sessionStore.Save(ctx, &session); err != nil {
+			http.Error(w, "Failed to update session", http.StatusInternalServerError)
+			return
+		}
+
+		action := auditActionUpdate
+		if before == nil {
+			action = auditActionCreate
+		}
+		recordAudit(r, "session", session.ID, action, before, session)
 	} else {
 		// Create new session
-		docRef, _, err := sessionsRef.Add(ctx, session)
-		if err != nil {
+		if err := sessionStore.Create(ctx, &session); err != nil {
 			http.Error(w, "Failed to create session", http.StatusInternalServerError)
 			return
 		}
-		session.ID = docRef.GetID()
+		recordAudit(r, "session", session.ID, auditActionCreate, nil, session)
 	}
+	invalidateResponses(CacheSessions)
 
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(session)
 }
-
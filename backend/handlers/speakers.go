@@ -4,8 +4,8 @@ import (
 	"encoding/json"
 	"net/http"
 
-	"event-registration-backend/firestore"
 	"event-registration-backend/models"
+	"event-registration-backend/store"
 )
 
 // GetSpeakers returns all speakers
@@ -15,24 +15,12 @@ func GetSpeakers(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	ctx := r.Context()
-	speakersRef := firestore.GetSpeakersCollection()
-	docs, err := speakersRef.Documents(ctx).GetAll()
+	speakers, err := speakerStore.List(r.Context())
 	if err != nil {
 		http.Error(w, "Failed to get speakers", http.StatusInternalServerError)
 		return
 	}
 
-	var speakers []models.Speaker
-	for _, doc := range docs {
-		var speaker models.Speaker
-		if err := doc.DataTo(&speaker); err != nil {
-			continue
-		}
-		speaker.ID = doc.GetID()
-		speakers = append(speakers, speaker)
-	}
-
 	// Ensure we return an empty array, not null
 	if speakers == nil {
 		speakers = []models.Speaker{}
@@ -62,7 +50,6 @@ func CreateOrUpdateSpeaker(w http.ResponseWriter, r *http.Request) {
 	}
 
 	ctx := r.Context()
-	speakersRef := firestore.GetSpeakersCollection()
 
 	speaker := models.Speaker{
 		Name:     req.Name,
@@ -71,24 +58,36 @@ func CreateOrUpdateSpeaker(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if req.ID != "" {
-		// Update existing speaker
-		_, err := speakersRef.Doc(req.ID).Set(ctx, speaker)
-		if err != nil {
+		// Keep the previous version for the audit log
+		before, err := speakerStore.Get(ctx, req.ID)
+		if err != nil && err != store.ErrNotFound {
 			http.Error(w, "Failed to update speaker", http.StatusInternalServerError)
 			return
 		}
+
+		// Update existing speaker
 		speaker.ID = req.ID
+		if err := speakerStore.Save(ctx, &speaker); err != nil {
+			http.Error(w, "Failed to update speaker", http.StatusInternalServerError)
+			return
+		}
+
+		action := auditActionUpdate
+		if before == nil {
+			action = auditActionCreate
+		}
+		recordAudit(r, "speaker", speaker.ID, action, before, speaker)
 	} else {
 		// Create new speaker
-		docRef, _, err := speakersRef.Add(ctx, speaker)
-		if err != nil {
+		if err := speakerStore.Create(ctx, &speaker); err != nil {
 			http.Error(w, "Failed to create speaker", http.StatusInternalServerError)
 			return
 		}
-		speaker.ID = docRef.GetID()
+		recordAudit(r, "speaker", speaker.ID, auditActionCreate, nil, speaker)
 	}
+	// Sessions embed their speaker
+	invalidateResponses(CacheSpeakers, CacheSessions)
 
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(speaker)
 }
-
@@ -0,0 +1,19 @@
+package handlers
+
+import "event-registration-backend/store"
+
+var (
+	attendeeStore store.AttendeeStore
+	sessionStore  store.SessionStore
+	speakerStore  store.SpeakerStore
+	fieldStore    store.FieldStore
+)
+
+// SetStores sets the repositories used by the attendee, session, speaker and
+// registration form handlers
+func SetStores(stores store.Stores) {
+	attendeeStore = stores.Attendees
+	sessionStore = stores.Sessions
+	speakerStore = stores.Speakers
+	fieldStore = stores.Fields
+}
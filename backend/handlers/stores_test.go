@@ -0,0 +1,85 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"event-registration-backend/models"
+	"event-registration-backend/store"
+)
+
+var errStoreDown = errors.New("store unavailable")
+
+// failingStore implements the repositories with every call failing
+type failingStore struct{}
+
+func (failingStore) Create(ctx context.Context, attendee *models.Attendee, capacity int) error {
+	return errStoreDown
+}
+func (failingStore) CheckAvailable(ctx context.Context, email string) error {
+	return errStoreDown
+}
+func (failingStore) Get(ctx context.Context, id string) (*models.Attendee, error) {
+	return nil, errStoreDown
+}
+func (failingStore) List(ctx context.Context) ([]models.Attendee, error) { return nil, errStoreDown }
+func (failingStore) CountByStatus(ctx context.Context) (map[string]int, error) {
+	return nil, errStoreDown
+}
+func (failingStore) CountByDesignation(ctx context.Context, status string) (map[string]int, error) {
+	return nil, errStoreDown
+}
+func (failingStore) CountByField(ctx context.Context, status, id string, options []string) (map[string]int, error) {
+	return nil, errStoreDown
+}
+func (failingStore) Search(ctx context.Context, q store.AttendeeQuery) ([]models.Attendee, error) {
+	return nil, errStoreDown
+}
+func (failingStore) Each(ctx context.Context, fn func(models.Attendee) error) error {
+	return errStoreDown
+}
+func (failingStore) UpdateDetails(ctx context.Context, id, fullName, designation string) (*models.Attendee, error) {
+	return nil, errStoreDown
+}
+func (failingStore) Cancel(ctx context.Context, id string, capacity int) (*models.Attendee, []models.Attendee, error) {
+	return nil, nil, errStoreDown
+}
+func (failingStore) Verify(ctx context.Context, id, manageTokenHash string, capacity int) (*models.Attendee, bool, error) {
+	return nil, false, errStoreDown
+}
+func (failingStore) MarkVerificationSent(ctx context.Context, id string, at time.Time, cooldown time.Duration) (bool, error) {
+	return false, errStoreDown
+}
+func (failingStore) PurgePending(ctx context.Context, cutoff time.Time) (int, error) {
+	return 0, errStoreDown
+}
+func (failingStore) CheckInStats(ctx context.Context) (*models.CheckInStats, error) {
+	return nil, errStoreDown
+}
+func (failingStore) CheckIn(ctx context.Context, id string, at time.Time) (*models.Attendee, error) {
+	return nil, errStoreDown
+}
+
+func TestSetStores_InjectedStoreErrors(t *testing.T) {
+	setupTestClient(t)
+	defer teardownTestClient()
+	SetStores(store.Stores{Attendees: failingStore{}})
+
+	for name, handler := range map[string]http.HandlerFunc{
+		"count":     GetAttendeeCount,
+		"attendees": GetAttendees,
+		"stats":     GetAttendeeStats,
+	} {
+		req := httptest.NewRequest("GET", "/api/attendees", nil)
+		w := httptest.NewRecorder()
+		handler(w, req)
+
+		if w.Code != http.StatusInternalServerError {
+			t.Errorf("%s: expected status 500 from failing store, got %d", name, w.Code)
+		}
+	}
+}
@@ -1,19 +1,45 @@
 package handlers
 
 import (
+	"context"
 	"testing"
+	"time"
 
+	"event-registration-backend/auth"
 	"event-registration-backend/firestore"
+	"event-registration-backend/mailer"
+	"event-registration-backend/models"
 )
 
-// setupTestClient creates a mock Firestore client for testing
+// setupTestClient creates a mock Firestore client for testing. When
+// FIRESTORE_EMULATOR_HOST is set the suite runs against the emulator instead,
+// with a fresh tenant per test so no data leaks between tests.
 func setupTestClient(t *testing.T) {
-	testClientID := "test-client-id"
-	mockClient := firestore.NewMockFirestoreClient(testClientID)
-	firestore.SetMockClient(mockClient, testClientID)
+	if firestore.EmulatorHost() != "" {
+		suffix, err := auth.RandomID(8)
+		if err != nil {
+			t.Fatalf("Failed to generate test client ID: %v", err)
+		}
+		if err := firestore.SetEmulatorTestClient(context.Background(), "test-"+suffix); err != nil {
+			t.Fatalf("Failed to connect to Firestore emulator: %v", err)
+		}
+	} else {
+		testClientID := "test-client-id"
+		mockClient := firestore.NewMockFirestoreClient(testClientID)
+		firestore.SetMockClient(mockClient, testClientID)
+	}
+	SetStores(firestore.NewStores())
+	SetEventCapacity(0)
+	SetMailer(mailer.Noop{}, models.EventDetails{Name: "Test Meetup"})
+	SetEmailVerification(false, 48*time.Hour, "http://localhost:8080")
+	SetSessionSecret("test-session-secret")
+	SetResponseCacheTTL(0)
+	SetLoginThrottle(auth.NewThrottle(auth.NewMemoryAttemptStore(), 5, time.Minute, time.Hour))
 }
 
 func teardownTestClient() {
+	// Background emails still read the stores being torn down
+	pendingMail.Wait()
 	firestore.ClearTestClient()
 }
 
@@ -0,0 +1,135 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/mail"
+	"strings"
+	"unicode/utf8"
+
+	"event-registration-backend/models"
+)
+
+// Field length limits for registrations. The email limits follow RFC 5321.
+const (
+	maxNameLength        = 100
+	maxDesignationLength = 100
+	maxEmailLength       = 254
+	maxEmailLocalLength  = 64
+)
+
+// DisposableEmailDomains are well-known throwaway mailbox providers, blocked
+// by default. Subdomains of a listed domain are blocked too.
+var DisposableEmailDomains = []string{
+	"10minutemail.com",
+	"discard.email",
+	"dispostable.com",
+	"getnada.com",
+	"guerrillamail.com",
+	"mailinator.com",
+	"maildrop.cc",
+	"sharklasers.com",
+	"temp-mail.org",
+	"tempmail.com",
+	"throwawaymail.com",
+	"trashmail.com",
+	"yopmail.com",
+}
+
+// EmailPolicy controls how attendee emails are normalized and which domains are refused
+type EmailPolicy struct {
+	// FoldLocalPart lowercases the part before the @ as well as the domain
+	FoldLocalPart bool
+	// BlockedDomains are refused, including their subdomains
+	BlockedDomains []string
+}
+
+var emailPolicy = EmailPolicy{BlockedDomains: DisposableEmailDomains}
+
+// SetEmailPolicy sets the normalization and blocklist applied to attendee emails
+func SetEmailPolicy(policy EmailPolicy) {
+	blocked := make([]string, 0, len(policy.BlockedDomains))
+	for _, domain := range policy.BlockedDomains {
+		if domain = strings.ToLower(strings.TrimSpace(domain)); domain != "" {
+			blocked = append(blocked, domain)
+		}
+	}
+	policy.BlockedDomains = blocked
+	emailPolicy = policy
+}
+
+// normalizeEmail trims and case-folds an email address, returning a
+// user-facing message when it is not a valid, allowed address
+func normalizeEmail(email string) (string, string) {
+	email = strings.TrimSpace(email)
+	if email == "" {
+		return "", "is required"
+	}
+	if len(email) > maxEmailLength {
+		return "", fmt.Sprintf("must be at most %d characters", maxEmailLength)
+	}
+
+	// ParseAddress also accepts "Name <addr>" forms; only a bare addr-spec is allowed
+	addr, err := mail.ParseAddress(email)
+	if err != nil || addr.Name != "" || strings.ContainsAny(email, "<>") {
+		return "", "is not a valid email address"
+	}
+	at := strings.LastIndex(email, "@")
+	local, domain := email[:at], strings.ToLower(email[at+1:])
+	if len(local) > maxEmailLocalLength {
+		return "", fmt.Sprintf("must have at most %d characters before the @", maxEmailLocalLength)
+	}
+	// Domain literals and single-label hosts are valid syntax but never deliverable for attendees
+	if strings.HasPrefix(domain, "[") || !strings.Contains(domain, ".") {
+		return "", "is not a valid email address"
+	}
+	for _, blocked := range emailPolicy.BlockedDomains {
+		if domain == blocked || strings.HasSuffix(domain, "."+blocked) {
+			return "", "uses a disposable email provider; please use a permanent address"
+		}
+	}
+
+	if emailPolicy.FoldLocalPart {
+		local = strings.ToLower(local)
+	}
+	return local + "@" + domain, ""
+}
+
+// validateText trims a required free-text field and checks its length
+func validateText(value string, maxLength int) (string, string) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return "", "is required"
+	}
+	if utf8.RuneCountInString(value) > maxLength {
+		return "", fmt.Sprintf("must be at most %d characters", maxLength)
+	}
+	return value, ""
+}
+
+// validateRegisterRequest normalizes req in place and returns a message per invalid field
+func validateRegisterRequest(req *models.RegisterRequest) map[string]string {
+	fields := make(map[string]string)
+	var msg string
+	if req.FullName, msg = validateText(req.FullName, maxNameLength); msg != "" {
+		fields["fullName"] = msg
+	}
+	if req.Email, msg = normalizeEmail(req.Email); msg != "" {
+		fields["email"] = msg
+	}
+	if req.Designation, msg = validateText(req.Designation, maxDesignationLength); msg != "" {
+		fields["designation"] = msg
+	}
+	return fields
+}
+
+// writeValidationErrors responds with status 400 and the per-field messages
+func writeValidationErrors(w http.ResponseWriter, fields map[string]string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusBadRequest)
+	json.NewEncoder(w).Encode(models.ValidationErrorResponse{
+		Error:  "Validation failed",
+		Fields: fields,
+	})
+}
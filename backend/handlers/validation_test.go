@@ -0,0 +1,110 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"event-registration-backend/models"
+)
+
+func TestNormalizeEmail(t *testing.T) {
+	defer SetEmailPolicy(EmailPolicy{BlockedDomains: DisposableEmailDomains})
+
+	testCases := []struct {
+		email string
+		want  string
+		valid bool
+	}{
+		{"  Foo@Example.COM ", "Foo@example.com", true},
+		{"first.last+tag@sub.example.org", "first.last+tag@sub.example.org", true},
+		{`"john doe"@example.com`, `"john doe"@example.com`, true},
+		{"", "", false},
+		{"not-an-email", "", false},
+		{"two@@example.com", "", false},
+		{"John <john@example.com>", "", false},
+		{"john@localhost", "", false},
+		{"john@[192.0.2.1]", "", false},
+		{"john@example..com", "", false},
+		{strings.Repeat("a", 65) + "@example.com", "", false},
+		{"a@" + strings.Repeat("b", 250) + ".com", "", false},
+		{"someone@mailinator.com", "", false},
+		{"someone@eu.Mailinator.com", "", false},
+	}
+
+	for _, tc := range testCases {
+		got, msg := normalizeEmail(tc.email)
+		if tc.valid && (msg != "" || got != tc.want) {
+			t.Errorf("normalizeEmail(%q) = %q, %q; want %q", tc.email, got, msg, tc.want)
+		}
+		if !tc.valid && msg == "" {
+			t.Errorf("normalizeEmail(%q) = %q; want a validation error", tc.email, got)
+		}
+	}
+
+	SetEmailPolicy(EmailPolicy{FoldLocalPart: true, BlockedDomains: []string{" Competitor.com "}})
+	if got, msg := normalizeEmail("Foo@Example.com"); got != "foo@example.com" || msg != "" {
+		t.Errorf("Expected local part folded, got %q, %q", got, msg)
+	}
+	if _, msg := normalizeEmail("someone@mailinator.com"); msg != "" {
+		t.Errorf("Expected default blocklist to be replaced, got %q", msg)
+	}
+	if _, msg := normalizeEmail("spy@competitor.com"); msg == "" {
+		t.Error("Expected configured domain to be blocked")
+	}
+}
+
+func TestRegisterAttendee_FieldErrors(t *testing.T) {
+	setupTestClient(t)
+	defer teardownTestClient()
+
+	body, _ := json.Marshal(models.RegisterRequest{
+		FullName:    "   ",
+		Email:       "john@mailinator.com",
+		Designation: strings.Repeat("x", maxDesignationLength+1),
+	})
+	req := httptest.NewRequest("POST", "/api/attendees/register", bytes.NewBuffer(body))
+	w := httptest.NewRecorder()
+	RegisterAttendee(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("Expected status 400, got %d", w.Code)
+	}
+	var resp models.ValidationErrorResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+	for _, field := range []string{"fullName", "email", "designation"} {
+		if resp.Fields[field] == "" {
+			t.Errorf("Expected an error for %s, got %+v", field, resp.Fields)
+		}
+	}
+}
+
+func TestRegisterAttendee_NormalizesInput(t *testing.T) {
+	setupTestClient(t)
+	defer teardownTestClient()
+
+	body, _ := json.Marshal(models.RegisterRequest{FullName: " Jane Doe ", Email: " Jane@Example.COM ", Designation: " Developer "})
+	req := httptest.NewRequest("POST", "/api/attendees/register", bytes.NewBuffer(body))
+	w := httptest.NewRecorder()
+	RegisterAttendee(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var attendee models.Attendee
+	if err := json.Unmarshal(w.Body.Bytes(), &attendee); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+	if attendee.FullName != "Jane Doe" || attendee.Email != "Jane@example.com" || attendee.Designation != "Developer" {
+		t.Errorf("Expected trimmed and case-folded attendee, got %+v", attendee)
+	}
+
+	if code := registerEmail("jane@EXAMPLE.com"); code != http.StatusConflict {
+		t.Errorf("Expected status 409 for the same address, got %d", code)
+	}
+}
@@ -0,0 +1,141 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"log"
+	"net/http"
+	"net/url"
+	"time"
+
+	"event-registration-backend/auth"
+	"event-registration-backend/mailer"
+	"event-registration-backend/models"
+	"event-registration-backend/store"
+)
+
+// Results of following a verification link, passed to the frontend as the
+// verification query parameter
+const (
+	verificationExpired = "expired"
+	verificationInvalid = "invalid"
+)
+
+var (
+	verificationRequired bool
+	verificationTTL      = 48 * time.Hour
+	publicURL            = "http://localhost:8080"
+	// verificationResendCooldown is how long a repeated registration of a
+	// pending email waits before the verification link is sent again
+	verificationResendCooldown = 10 * time.Minute
+)
+
+// SetEmailVerification turns double opt-in on or off. While it is on, new
+// registrations stay pending until the attendee follows the emailed link,
+// which expires after ttl. baseURL is the public address the link points to.
+func SetEmailVerification(required bool, ttl time.Duration, baseURL string) {
+	verificationRequired = required
+	verificationTTL = ttl
+	publicURL = baseURL
+}
+
+// verificationData is the input of the verification templates
+type verificationData struct {
+	Event     models.EventDetails
+	Attendee  models.Attendee
+	Link      string
+	ExpiresAt time.Time
+}
+
+// sendVerification emails attendee the link that completes its registration
+func sendVerification(attendee models.Attendee) {
+	sendInBackground("verification", attendee, func(ctx context.Context) (mailer.Message, error) {
+		return verificationMessage(attendee)
+	})
+}
+
+// verificationMessage renders the verification email for attendee
+func verificationMessage(attendee models.Attendee) (mailer.Message, error) {
+	expiresAt := attendee.RegisteredAt.Add(verificationTTL)
+	token, err := auth.IssueVerificationToken(sessionSecret, attendee.ID, expiresAt)
+	if err != nil {
+		return mailer.Message{}, err
+	}
+	data := verificationData{
+		Event:     eventDetails,
+		Attendee:  attendee,
+		Link:      publicURL + "/api/attendees/verify?token=" + url.QueryEscape(token),
+		ExpiresAt: expiresAt,
+	}
+
+	var text, html bytes.Buffer
+	if err := verificationText.Execute(&text, data); err != nil {
+		return mailer.Message{}, err
+	}
+	if err := verificationHTML.Execute(&html, data); err != nil {
+		return mailer.Message{}, err
+	}
+	subject := "Confirm your registration: " + eventDetails.Name
+	return mailer.Message{To: attendee.Email, Subject: subject, Text: text.String(), HTML: html.String()}, nil
+}
+
+// VerifyAttendee completes a pending registration from the emailed link. The
+// attendee is confirmed or waitlisted as if registering now and receives the
+// usual confirmation email, which carries the manage token: it is only handed
+// out once the address is proven to belong to the attendee. It redirects to
+// the frontend with the outcome in the verification query parameter: the
+// attendee's status, expired or invalid.
+func VerifyAttendee(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	id, err := auth.ParseVerificationToken(sessionSecret, r.URL.Query().Get("token"))
+	if err == auth.ErrExpiredToken {
+		redirectVerification(w, r, verificationExpired)
+		return
+	}
+	if err != nil {
+		redirectVerification(w, r, verificationInvalid)
+		return
+	}
+
+	manageToken, manageTokenHash, err := auth.GenerateManageToken(id)
+	if err != nil {
+		http.Error(w, "Failed to verify registration", http.StatusInternalServerError)
+		return
+	}
+	attendee, verified, err := attendeeStore.Verify(r.Context(), id, manageTokenHash, eventCapacity)
+	if err == store.ErrNotFound {
+		// Purged after expiring, or cancelled
+		redirectVerification(w, r, verificationInvalid)
+		return
+	}
+	if err != nil {
+		log.Printf("Failed to verify attendee %s: %v", id, err)
+		http.Error(w, "Failed to verify registration", http.StatusInternalServerError)
+		return
+	}
+	if verified {
+		invalidateResponses(CacheAttendeeCount)
+		attendee.ManageToken = manageToken
+		sendConfirmation(*attendee)
+	}
+	redirectVerification(w, r, attendee.Status)
+}
+
+// redirectVerification sends the browser to the frontend with result
+func redirectVerification(w http.ResponseWriter, r *http.Request, result string) {
+	http.Redirect(w, r, publicURL+"/?verification="+url.QueryEscape(result), http.StatusSeeOther)
+}
+
+// PurgeExpiredRegistrations deletes pending registrations whose verification
+// link has expired and returns how many were deleted. It does nothing while
+// verification is off.
+func PurgeExpiredRegistrations(ctx context.Context) (int, error) {
+	if !verificationRequired {
+		return 0, nil
+	}
+	return attendeeStore.PurgePending(ctx, time.Now().Add(-verificationTTL))
+}
@@ -0,0 +1,209 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"event-registration-backend/mailer"
+	"event-registration-backend/models"
+)
+
+var (
+	verificationLinkPattern = regexp.MustCompile(`http://\S+/api/attendees/verify\?token=\S+`)
+	manageTokenPattern      = regexp.MustCompile(`manage token\. Keep it private:\s+(\S+)`)
+)
+
+// registerPending registers email while verification is required and returns
+// the verification link that was emailed
+func registerPending(t *testing.T, recorder *mailer.Recorder, email string) string {
+	t.Helper()
+	return registerPendingAs(t, recorder, "John Doe", email)
+}
+
+// registerPendingAs is registerPending for an attendee named fullName
+func registerPendingAs(t *testing.T, recorder *mailer.Recorder, fullName, email string) string {
+	t.Helper()
+	body, _ := json.Marshal(models.RegisterRequest{FullName: fullName, Email: email, Designation: "Developer"})
+	req := httptest.NewRequest("POST", "/api/attendees/register", bytes.NewBuffer(body))
+	w := httptest.NewRecorder()
+	RegisterAttendee(w, req)
+
+	if w.Code != http.StatusAccepted {
+		t.Fatalf("Expected status 202 registering %s, got %d: %s", email, w.Code, w.Body.String())
+	}
+	var attendee models.Attendee
+	json.Unmarshal(w.Body.Bytes(), &attendee)
+	if attendee.Status != models.AttendeePending || attendee.ManageToken != "" {
+		t.Fatalf("Expected a pending registration without a manage token, got %+v", attendee)
+	}
+
+	pendingMail.Wait()
+	messages := recorder.Messages()
+	msg := messages[len(messages)-1]
+	link := verificationLinkPattern.FindString(msg.Text)
+	if !strings.EqualFold(msg.To, email) || link == "" || !strings.Contains(msg.HTML, "Confirm my registration") {
+		t.Fatalf("Expected a verification email with a link, got %q:\n%s", msg.Subject, msg.Text)
+	}
+	return link
+}
+
+// followLink requests link and returns the verification result it redirects to
+func followLink(t *testing.T, link string) string {
+	t.Helper()
+	req := httptest.NewRequest("GET", link, nil)
+	w := httptest.NewRecorder()
+	VerifyAttendee(w, req)
+
+	if w.Code != http.StatusSeeOther {
+		t.Fatalf("Expected status 303, got %d: %s", w.Code, w.Body.String())
+	}
+	location, err := url.Parse(w.Header().Get("Location"))
+	if err != nil {
+		t.Fatalf("Invalid redirect: %v", err)
+	}
+	return location.Query().Get("verification")
+}
+
+func TestVerifyAttendee_DoubleOptIn(t *testing.T) {
+	setupTestClient(t)
+	defer teardownTestClient()
+	recorder := &mailer.Recorder{}
+	SetMailer(recorder, models.EventDetails{Name: "Go Meetup"})
+	SetEmailVerification(true, time.Hour, "http://meetup.test")
+	SetEventCapacity(1)
+
+	link := registerPending(t, recorder, "ada@example.com")
+	if count := fetchAttendeeCount(t); count.Confirmed != 0 || count.Waitlisted != 0 {
+		t.Errorf("Expected pending attendees not to be counted, got %+v", count)
+	}
+
+	if result := followLink(t, link); result != models.AttendeeConfirmed {
+		t.Errorf("Expected the attendee to be confirmed, got %q", result)
+	}
+	if count := fetchAttendeeCount(t); count.Confirmed != 1 || *count.Remaining != 0 {
+		t.Errorf("Expected the verified attendee to take the seat, got %+v", count)
+	}
+	pendingMail.Wait()
+	messages := recorder.Messages()
+	if len(messages) != 2 || messages[1].Subject != "Registration confirmed: Go Meetup" {
+		t.Fatalf("Expected a confirmation email after verifying, got %d emails", len(messages))
+	}
+	// The manage token is only handed out by email, once the address is verified
+	match := manageTokenPattern.FindStringSubmatch(messages[1].Text)
+	if match == nil || !strings.Contains(messages[1].HTML, match[1]) {
+		t.Fatalf("Expected the confirmation email to carry the manage token:\n%s", messages[1].Text)
+	}
+	if w := manageRequest(GetRegistration, "GET", match[1], nil); w.Code != http.StatusOK {
+		t.Errorf("Expected the emailed manage token to work, got %d", w.Code)
+	}
+
+	if result := followLink(t, link); result != models.AttendeeConfirmed {
+		t.Errorf("Expected following the link again to report the status, got %q", result)
+	}
+	pendingMail.Wait()
+	if len(recorder.Messages()) != 2 {
+		t.Error("Expected no further email when following the link again")
+	}
+}
+
+func TestVerifyAttendee_ExpiredAndPurged(t *testing.T) {
+	setupTestClient(t)
+	defer teardownTestClient()
+	recorder := &mailer.Recorder{}
+	SetMailer(recorder, models.EventDetails{Name: "Go Meetup"})
+	SetEmailVerification(true, -time.Minute, "http://meetup.test")
+
+	link := registerPending(t, recorder, "ada@example.com")
+	if result := followLink(t, link); result != verificationExpired {
+		t.Errorf("Expected an expired link, got %q", result)
+	}
+
+	purged, err := PurgeExpiredRegistrations(t.Context())
+	if err != nil || purged != 1 {
+		t.Fatalf("Expected 1 purged registration, got %d (err %v)", purged, err)
+	}
+	SetEmailVerification(true, time.Hour, "http://meetup.test")
+	registerPending(t, recorder, "ada@example.com")
+}
+
+func TestVerifyAttendee_InvalidToken(t *testing.T) {
+	setupTestClient(t)
+	defer teardownTestClient()
+	SetEmailVerification(true, time.Hour, "http://meetup.test")
+
+	if result := followLink(t, "/api/attendees/verify?token=forged.token"); result != verificationInvalid {
+		t.Errorf("Expected an invalid link, got %q", result)
+	}
+}
+
+func TestRegisterAttendee_PendingRegistrationNotReplaced(t *testing.T) {
+	setupTestClient(t)
+	defer teardownTestClient()
+	recorder := &mailer.Recorder{}
+	SetMailer(recorder, models.EventDetails{Name: "Go Meetup"})
+	SetEmailVerification(true, time.Hour, "http://meetup.test")
+
+	first := registerPendingAs(t, recorder, "Ada Lovelace", "ada@example.com")
+	// Registering the same address again right away sends nothing
+	if code := registerEmail("ada@example.com"); code != http.StatusAccepted {
+		t.Fatalf("Expected status 202 registering again, got %d", code)
+	}
+	pendingMail.Wait()
+	if messages := recorder.Messages(); len(messages) != 1 {
+		t.Fatalf("Expected no resend within the cooldown, got %d emails", len(messages))
+	}
+
+	// Once the cooldown has passed it resends the original verification
+	// instead of replacing the registration with someone else's details
+	cooldown := verificationResendCooldown
+	verificationResendCooldown = 0
+	defer func() { verificationResendCooldown = cooldown }()
+	resent := registerPendingAs(t, recorder, "Mallory", "Ada@example.com")
+	if resent != first {
+		t.Errorf("Expected the original verification link to be resent, got %s", resent)
+	}
+	pendingMail.Wait()
+	if messages := recorder.Messages(); !strings.Contains(messages[1].Text, "Hi Ada Lovelace") {
+		t.Errorf("Expected the resent email to address the original registrant:\n%s", messages[1].Text)
+	}
+
+	if result := followLink(t, resent); result != models.AttendeeConfirmed {
+		t.Errorf("Expected the registration to verify, got %q", result)
+	}
+	attendees := listAttendees(t)
+	if len(attendees) != 1 || attendees[0].FullName != "Ada Lovelace" {
+		t.Errorf("Expected the original registration to be kept, got %+v", attendees)
+	}
+	if code := registerEmail("ada@example.com"); code != http.StatusConflict {
+		t.Errorf("Expected status 409 once verified, got %d", code)
+	}
+}
+
+func TestRegisterAttendee_ExpiredPendingRegistrationReplaced(t *testing.T) {
+	setupTestClient(t)
+	defer teardownTestClient()
+	recorder := &mailer.Recorder{}
+	SetMailer(recorder, models.EventDetails{Name: "Go Meetup"})
+	SetEmailVerification(true, time.Hour, "http://meetup.test")
+
+	// The link of this registration has expired, but it was not purged yet
+	expired := models.Attendee{FullName: "Ada Lovelace", Email: "ada@example.com", Designation: "Developer", RegisteredAt: time.Now().Add(-2 * time.Hour), Status: models.AttendeePending}
+	if err := attendeeStore.Create(t.Context(), &expired, 0); err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	link := registerPendingAs(t, recorder, "Ada King", "ada@example.com")
+
+	if attendees := listAttendees(t); len(attendees) != 1 || attendees[0].FullName != "Ada King" {
+		t.Errorf("Expected the expired registration to be replaced, got %+v", attendees)
+	}
+	if result := followLink(t, link); result != models.AttendeeConfirmed {
+		t.Errorf("Expected the new registration to verify, got %q", result)
+	}
+}
@@ -0,0 +1,61 @@
+// Package mailer sends transactional email. SMTP delivers through any SMTP
+// server, including local sinks such as MailHog; Noop and Recorder stand in
+// when email is disabled and in tests.
+package mailer
+
+import (
+	"context"
+	"sync"
+)
+
+// Message is one email with a plain-text body and an optional HTML alternative
+type Message struct {
+	To      string
+	Subject string
+	Text    string
+	HTML    string
+	// Inline holds images the HTML body shows with src="cid:<ContentID>"
+	Inline []Attachment
+}
+
+// Attachment is a file sent with a message
+type Attachment struct {
+	ContentID   string
+	ContentType string
+	Filename    string
+	Data        []byte
+}
+
+// Mailer delivers messages
+type Mailer interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+// Noop discards every message
+type Noop struct{}
+
+func (Noop) Send(ctx context.Context, msg Message) error {
+	return nil
+}
+
+// Recorder keeps every message it is asked to send, for tests
+type Recorder struct {
+	mu       sync.Mutex
+	messages []Message
+	// Err, if set, is returned by Send after recording the message
+	Err error
+}
+
+func (r *Recorder) Send(ctx context.Context, msg Message) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.messages = append(r.messages, msg)
+	return r.Err
+}
+
+// Messages returns a copy of the recorded messages in send order
+func (r *Recorder) Messages() []Message {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]Message(nil), r.messages...)
+}
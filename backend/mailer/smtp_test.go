@@ -0,0 +1,193 @@
+package mailer
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/base64"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net"
+	"net/mail"
+	"strings"
+	"testing"
+	"time"
+)
+
+// smtpSink is a minimal SMTP server that captures one message
+type smtpSink struct {
+	listener net.Listener
+	from     string
+	to       string
+	data     chan string
+}
+
+func startSMTPSink(t *testing.T) *smtpSink {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	t.Cleanup(func() { listener.Close() })
+	sink := &smtpSink{listener: listener, data: make(chan string, 1)}
+	go sink.serve()
+	return sink
+}
+
+func (s *smtpSink) port() int {
+	return s.listener.Addr().(*net.TCPAddr).Port
+}
+
+func (s *smtpSink) serve() {
+	conn, err := s.listener.Accept()
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+	reply := func(line string) { io.WriteString(conn, line+"\r\n") }
+
+	reply("220 sink ready")
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		cmd := strings.TrimSpace(line)
+		switch {
+		case strings.HasPrefix(cmd, "EHLO"), strings.HasPrefix(cmd, "HELO"):
+			reply("250 sink")
+		case strings.HasPrefix(cmd, "MAIL FROM:"):
+			s.from = strings.Trim(strings.TrimPrefix(cmd, "MAIL FROM:"), "<>")
+			reply("250 OK")
+		case strings.HasPrefix(cmd, "RCPT TO:"):
+			s.to = strings.Trim(strings.TrimPrefix(cmd, "RCPT TO:"), "<>")
+			reply("250 OK")
+		case cmd == "DATA":
+			reply("354 go ahead")
+			var data strings.Builder
+			for {
+				line, err := r.ReadString('\n')
+				if err != nil || line == ".\r\n" {
+					break
+				}
+				data.WriteString(line)
+			}
+			s.data <- data.String()
+			reply("250 queued")
+		case cmd == "QUIT":
+			reply("221 bye")
+			return
+		default:
+			reply("502 not implemented")
+		}
+	}
+}
+
+func TestSMTP_Send(t *testing.T) {
+	sink := startSMTPSink(t)
+	sender := &SMTP{Host: "127.0.0.1", Port: sink.port(), From: "events@example.com"}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	err := sender.Send(ctx, Message{
+		To:      "ada@example.com",
+		Subject: "You're registered – see you soon",
+		Text:    "Hello Ada",
+		HTML:    "<p>Hello Ada</p>",
+	})
+	if err != nil {
+		t.Fatalf("Send failed: %v", err)
+	}
+	if sink.from != "events@example.com" || sink.to != "ada@example.com" {
+		t.Errorf("Unexpected envelope: from %q to %q", sink.from, sink.to)
+	}
+
+	msg, err := mail.ReadMessage(strings.NewReader(<-sink.data))
+	if err != nil {
+		t.Fatalf("Failed to parse message: %v", err)
+	}
+	subject, _ := new(mime.WordDecoder).DecodeHeader(msg.Header.Get("Subject"))
+	if subject != "You're registered – see you soon" {
+		t.Errorf("Unexpected subject %q", subject)
+	}
+	mediaType, params, err := mime.ParseMediaType(msg.Header.Get("Content-Type"))
+	if err != nil || mediaType != "multipart/alternative" {
+		t.Fatalf("Expected multipart/alternative, got %q (%v)", mediaType, err)
+	}
+
+	parts := multipart.NewReader(msg.Body, params["boundary"])
+	var bodies []string
+	for {
+		part, err := parts.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("Failed to read part: %v", err)
+		}
+		body, _ := io.ReadAll(part)
+		bodies = append(bodies, string(body))
+	}
+	if len(bodies) != 2 || bodies[0] != "Hello Ada" || bodies[1] != "<p>Hello Ada</p>" {
+		t.Errorf("Unexpected bodies: %q", bodies)
+	}
+}
+
+func TestSMTP_Unreachable(t *testing.T) {
+	listener, _ := net.Listen("tcp", "127.0.0.1:0")
+	port := listener.Addr().(*net.TCPAddr).Port
+	listener.Close()
+
+	sender := &SMTP{Host: "127.0.0.1", Port: port, From: "events@example.com"}
+	if err := sender.Send(context.Background(), Message{To: "ada@example.com", Subject: "Hi", Text: "Hi"}); err == nil {
+		t.Error("Expected an error when the server is unreachable")
+	}
+}
+
+func TestSMTP_SendInlineImage(t *testing.T) {
+	sink := startSMTPSink(t)
+	sender := &SMTP{Host: "127.0.0.1", Port: sink.port(), From: "events@example.com"}
+
+	image := bytes.Repeat([]byte{0x89, 'P', 'N', 'G'}, 40)
+	err := sender.Send(context.Background(), Message{
+		To:      "ada@example.com",
+		Subject: "Your ticket",
+		Text:    "Hello Ada",
+		HTML:    `<img src="cid:ticket">`,
+		Inline:  []Attachment{{ContentID: "ticket", ContentType: "image/png", Filename: "ticket.png", Data: image}},
+	})
+	if err != nil {
+		t.Fatalf("Send failed: %v", err)
+	}
+
+	msg, err := mail.ReadMessage(strings.NewReader(<-sink.data))
+	if err != nil {
+		t.Fatalf("Failed to parse message: %v", err)
+	}
+	mediaType, params, _ := mime.ParseMediaType(msg.Header.Get("Content-Type"))
+	if mediaType != "multipart/related" {
+		t.Fatalf("Expected multipart/related, got %q", mediaType)
+	}
+
+	parts := multipart.NewReader(msg.Body, params["boundary"])
+	alternative, err := parts.NextPart()
+	if err != nil {
+		t.Fatalf("Failed to read the body part: %v", err)
+	}
+	if mediaType, _, _ := mime.ParseMediaType(alternative.Header.Get("Content-Type")); mediaType != "multipart/alternative" {
+		t.Errorf("Expected the first part to be multipart/alternative, got %q", mediaType)
+	}
+	inline, err := parts.NextPart()
+	if err != nil {
+		t.Fatalf("Failed to read the image part: %v", err)
+	}
+	if inline.Header.Get("Content-ID") != "<ticket>" || inline.Header.Get("Content-Type") != "image/png" {
+		t.Errorf("Unexpected image headers: %v", inline.Header)
+	}
+	data, _ := io.ReadAll(base64.NewDecoder(base64.StdEncoding, inline))
+	if !bytes.Equal(data, image) {
+		t.Errorf("Image did not survive encoding")
+	}
+}
@@ -2,49 +2,167 @@ package main
 
 import (
 	"context"
+	"fmt"
 	"log"
 	"net/http"
 	"os"
+	"os/signal"
 	"path/filepath"
+	"syscall"
+	"time"
+	// Attendee exports convert timestamps to any time zone, also in images
+	// without a zoneinfo database
+	_ "time/tzdata"
 
+	"event-registration-backend/auth"
 	"event-registration-backend/config"
 	"event-registration-backend/firestore"
 	"event-registration-backend/handlers"
+	"event-registration-backend/mailer"
+	"event-registration-backend/models"
+	"event-registration-backend/sqlstore"
 
 	"github.com/gorilla/mux"
 	"github.com/rs/cors"
 )
 
+// purgeInterval is how often unverified registrations are checked for expiry
+const purgeInterval = 15 * time.Minute
+
+// shutdownTimeout is how long in-flight requests and queued emails get to
+// finish on shutdown
+const shutdownTimeout = 10 * time.Second
+
 func main() {
 	cfg := config.LoadConfig()
+	if err := cfg.Validate(); err != nil {
+		log.Fatalf("Invalid configuration: %v", err)
+	}
+
+	// Initialize storage. ctx is cancelled on SIGINT or SIGTERM, which stops
+	// background work and shuts the server down.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	if err := initStorage(ctx, cfg); err != nil {
+		log.Fatalf("Failed to initialize storage: %v", err)
+	}
+
+	// Create the first admin account if the tenant has none
+	created, err := handlers.BootstrapAdmin(ctx, cfg.AdminUsername, cfg.AdminPassword)
+	if err != nil {
+		log.Fatalf("Failed to bootstrap admin account: %v", err)
+	}
+	if created {
+		log.Printf("Created initial admin account %q", cfg.AdminUsername)
+	}
+
+	// Set admin session signing key
+	sessionSecret := cfg.SessionSecret
+	if sessionSecret == "" {
+		generated, err := auth.RandomID(32)
+		if err != nil {
+			log.Fatalf("Failed to generate session secret: %v", err)
+		}
+		sessionSecret = generated
+		log.Println("SESSION_SECRET not set, using a random key; admin sessions, QR tickets and verification links will stop working on restart")
+	}
+	handlers.SetSessionSecret(sessionSecret)
+	handlers.SetSessionTTL(cfg.SessionTTL)
+
+	// Lock out accounts and IPs after repeated failed admin logins
+	handlers.SetLoginThrottle(auth.NewThrottle(firestore.NewAttemptStore(), cfg.LoginMaxFailures, cfg.LoginLockoutBase, cfg.LoginLockoutMax))
+	handlers.SetTrustProxyHeaders(cfg.TrustProxyHeaders)
 
-	// Initialize Firestore
-	ctx := context.Background()
-	if err := firestore.InitializeFirestore(ctx, cfg.ServiceAccountPath, cfg.GCPProjectID, cfg.ClientID); err != nil {
-		log.Fatalf("Failed to initialize Firestore: %v", err)
+	// Registrations beyond the venue capacity go to the waitlist
+	handlers.SetEventCapacity(cfg.EventCapacity)
+	handlers.SetResponseCacheTTL(cfg.ResponseCacheTTL)
+	if cfg.EventCapacity > 0 {
+		log.Printf("Event capacity: %d seats", cfg.EventCapacity)
 	}
-	log.Printf("Firestore initialized with client_id: %s", firestore.ClientID)
 
-	// Set admin password in handlers
-	handlers.SetAdminPassword(cfg.AdminPassword)
+	// Attendee email normalization and domain blocklist
+	blockedDomains := cfg.BlockedEmailDomains
+	if cfg.BlockDisposableEmails {
+		blockedDomains = append(blockedDomains, handlers.DisposableEmailDomains...)
+	}
+	handlers.SetEmailPolicy(handlers.EmailPolicy{FoldLocalPart: cfg.EmailFoldLocalPart, BlockedDomains: blockedDomains})
+
+	// Attendee emails go through SMTP when configured
+	var sender mailer.Mailer = mailer.Noop{}
+	if cfg.MailEnabled() {
+		sender = &mailer.SMTP{Host: cfg.SMTPHost, Port: cfg.SMTPPort, Username: cfg.SMTPUsername, Password: cfg.SMTPPassword, From: cfg.MailFrom}
+		log.Printf("Sending attendee emails through %s:%d", cfg.SMTPHost, cfg.SMTPPort)
+	} else {
+		log.Println("SMTP_HOST not set, attendee emails are disabled")
+	}
+	handlers.SetMailer(sender, models.EventDetails{Name: cfg.EventName, Date: cfg.EventDate, Venue: cfg.EventVenue, Address: cfg.EventAddress})
+
+	// Double opt-in: registrations stay pending until the emailed link is followed
+	handlers.SetEmailVerification(cfg.RequireEmailVerification, cfg.VerificationTTL, cfg.PublicURL)
+	if cfg.RequireEmailVerification {
+		if !cfg.MailEnabled() {
+			log.Println("REQUIRE_EMAIL_VERIFICATION is set without SMTP_HOST; registrations cannot be verified")
+		}
+		go purgeExpiredRegistrations(ctx)
+	}
+
+	// Optional single sign-on through the company identity provider
+	if cfg.OIDCEnabled() {
+		provider, err := newOIDCProvider(ctx, cfg)
+		if err != nil {
+			log.Fatalf("Failed to initialize OIDC: %v", err)
+		}
+		handlers.SetOIDCProvider(provider, cfg.OIDCPostLoginRedirect)
+		log.Printf("OIDC single sign-on enabled for issuer %s", cfg.OIDCIssuerURL)
+	}
 
 	// Setup router
 	r := mux.NewRouter()
 
 	// Public API routes
 	api := r.PathPrefix("/api").Subrouter()
-	api.HandleFunc("/sessions", handlers.GetSessions).Methods("GET")
-	api.HandleFunc("/speakers", handlers.GetSpeakers).Methods("GET")
-	api.HandleFunc("/attendees/count", handlers.GetAttendeeCount).Methods("GET")
+	api.HandleFunc("/sessions", handlers.CacheResponse(handlers.CacheSessions, handlers.GetSessions)).Methods("GET")
+	api.HandleFunc("/speakers", handlers.CacheResponse(handlers.CacheSpeakers, handlers.GetSpeakers)).Methods("GET")
+	api.HandleFunc("/attendees/count", handlers.CacheResponse(handlers.CacheAttendeeCount, handlers.GetAttendeeCount)).Methods("GET")
+	api.HandleFunc("/registration/fields", handlers.CacheResponse(handlers.CacheRegistrationFields, handlers.GetRegistrationFields)).Methods("GET")
 	api.HandleFunc("/attendees/register", handlers.RegisterAttendee).Methods("POST")
+	// Double opt-in link emailed at registration
+	api.HandleFunc("/attendees/verify", handlers.VerifyAttendee).Methods("GET")
+	// Self-service with the manage token returned at registration
+	api.HandleFunc("/attendees/manage", handlers.GetRegistration).Methods("GET")
+	api.HandleFunc("/attendees/manage", handlers.UpdateRegistration).Methods("PUT")
+	api.HandleFunc("/attendees/manage/cancel", handlers.CancelRegistration).Methods("POST")
+	api.HandleFunc("/attendees/manage/ticket", handlers.GetRegistrationTicket).Methods("GET")
 
 	// Admin API routes
 	admin := api.PathPrefix("/admin").Subrouter()
 	admin.HandleFunc("/login", handlers.AdminLogin).Methods("POST")
-	admin.HandleFunc("/attendees", handlers.GetAttendees).Methods("GET")
-	admin.HandleFunc("/stats", handlers.GetAttendeeStats).Methods("GET")
-	admin.HandleFunc("/speakers", handlers.CreateOrUpdateSpeaker).Methods("POST")
-	admin.HandleFunc("/sessions", handlers.CreateOrUpdateSession).Methods("POST")
+	admin.HandleFunc("/oidc/login", handlers.OIDCLogin).Methods("GET")
+	admin.HandleFunc("/oidc/callback", handlers.OIDCCallback).Methods("GET")
+
+	// Everything else under /api/admin requires a valid session token
+	protected := admin.NewRoute().Subrouter()
+	protected.Use(handlers.RequireAdmin)
+	protected.HandleFunc("/logout", handlers.AdminLogout).Methods("POST")
+	protected.Handle("/tokens/revoke", handlers.RequirePermission(auth.PermissionAdminsManage, handlers.RevokeToken)).Methods("POST")
+	protected.Handle("/users", handlers.RequirePermission(auth.PermissionAdminsManage, handlers.GetAdminUsers)).Methods("GET")
+	protected.Handle("/users", handlers.RequirePermission(auth.PermissionAdminsManage, handlers.CreateOrUpdateAdminUser)).Methods("POST")
+	protected.Handle("/apikeys", handlers.RequirePermission(auth.PermissionAPIKeysManage, handlers.GetAPIKeys)).Methods("GET")
+	protected.Handle("/apikeys", handlers.RequirePermission(auth.PermissionAPIKeysManage, handlers.CreateAPIKey)).Methods("POST")
+	protected.Handle("/apikeys/{id}", handlers.RequirePermission(auth.PermissionAPIKeysManage, handlers.RevokeAPIKey)).Methods("DELETE")
+	protected.Handle("/audit", handlers.RequirePermission(auth.PermissionAuditRead, handlers.GetAuditLog)).Methods("GET")
+	protected.Handle("/attendees", handlers.RequirePermission(auth.PermissionAttendeesRead, handlers.GetAttendees)).Methods("GET")
+	protected.Handle("/attendees/{id}/cancel", handlers.RequirePermission(auth.PermissionAttendeesWrite, handlers.CancelAttendee)).Methods("POST")
+	protected.Handle("/attendees/import", handlers.RequirePermission(auth.PermissionAttendeesWrite, handlers.ImportAttendees)).Methods("POST")
+	protected.Handle("/attendees/export", handlers.RequirePermission(auth.PermissionAttendeesRead, handlers.ExportAttendees)).Methods("GET")
+	protected.Handle("/attendees/badges", handlers.RequirePermission(auth.PermissionAttendeesRead, handlers.GetAttendeeBadges)).Methods("GET")
+	protected.Handle("/attendees/{id}/ticket", handlers.RequirePermission(auth.PermissionAttendeesRead, handlers.GetAttendeeTicket)).Methods("GET")
+	protected.Handle("/stats", handlers.RequirePermission(auth.PermissionAttendeesRead, handlers.GetAttendeeStats)).Methods("GET")
+	protected.Handle("/checkin", handlers.RequirePermission(auth.PermissionCheckIn, handlers.CheckInAttendee)).Methods("POST")
+	protected.Handle("/checkin/stats", handlers.RequirePermission(auth.PermissionAttendeesRead, handlers.GetCheckInStats)).Methods("GET")
+	protected.Handle("/speakers", handlers.RequirePermission(auth.PermissionSpeakersWrite, handlers.CreateOrUpdateSpeaker)).Methods("POST")
+	protected.Handle("/sessions", handlers.RequirePermission(auth.PermissionSessionsWrite, handlers.CreateOrUpdateSession)).Methods("POST")
+	protected.Handle("/registration/fields", handlers.RequirePermission(auth.PermissionFieldsWrite, handlers.UpdateRegistrationFields)).Methods("PUT")
 
 	// Serve static files from frontend/dist
 	frontendDir := cfg.FrontendDir
@@ -104,13 +222,131 @@ func main() {
 		AllowedOrigins: []string{"*"},
 		AllowedMethods: []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
 		AllowedHeaders: []string{"*"},
+		ExposedHeaders: []string{"X-Next-Page-Token"},
 	})
 
 	handler := c.Handler(r)
 
+	server := &http.Server{Addr: ":" + cfg.Port, Handler: handler}
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+		if err := server.Shutdown(shutdownCtx); err != nil {
+			log.Printf("Server shutdown failed: %v", err)
+		}
+		// Requests that finished may have queued confirmation emails
+		if err := handlers.WaitForPendingMail(shutdownCtx); err != nil {
+			log.Printf("Gave up waiting for pending emails: %v", err)
+		}
+	}()
+
 	log.Printf("Server starting on port %s", cfg.Port)
-	if err := http.ListenAndServe(":"+cfg.Port, handler); err != nil {
+	if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
 		log.Fatalf("Server failed to start: %v", err)
 	}
+	<-stopped
+	log.Println("Server stopped")
 }
 
+
+// initStorage connects the configured storage backend and installs its repositories
+func initStorage(ctx context.Context, cfg *config.Config) error {
+	switch cfg.StorageBackend {
+	case config.StorageSQLite, config.StoragePostgres:
+		driver := sqlstore.DriverSQLite
+		if cfg.StorageBackend == config.StoragePostgres {
+			driver = sqlstore.DriverPostgres
+		}
+		db, err := sqlstore.Open(ctx, driver, cfg.DatabaseURL, cfg.ClientID)
+		if err != nil {
+			return err
+		}
+		handlers.SetStores(db.Stores())
+
+		// Admin accounts, tokens, API keys, login throttling, single sign-on
+		// flows and the audit log still go through the Firestore collection
+		// API, served by the local store here. That file belongs to one
+		// process, so a second instance for the tenant must not start.
+		err = db.LockInstance(ctx)
+		if err == sqlstore.ErrInstanceRunning {
+			return fmt.Errorf("another instance already serves client_id %s; with %s storage only one instance may run, since admin data is kept in %s", cfg.ClientID, cfg.StorageBackend, cfg.LocalDataPath)
+		}
+		if err != nil {
+			return err
+		}
+		if err := firestore.InitializeLocal(cfg.ClientID, cfg.LocalDataPath); err != nil {
+			return err
+		}
+		log.Printf("Using %s storage with client_id: %s; admin data in %s", cfg.StorageBackend, cfg.ClientID, cfg.LocalDataPath)
+		return nil
+	case config.StorageLocal:
+		if err := firestore.InitializeLocal(cfg.ClientID, cfg.LocalDataPath); err != nil {
+			return err
+		}
+		handlers.SetStores(firestore.NewStores())
+		log.Printf("Using local storage in %s with client_id: %s", cfg.LocalDataPath, cfg.ClientID)
+		return nil
+	default:
+		if err := firestore.InitializeFirestore(ctx, cfg.ServiceAccountPath, cfg.GCPProjectID, cfg.ClientID); err != nil {
+			return err
+		}
+		handlers.SetStores(firestore.NewStores())
+		log.Printf("Firestore initialized with client_id: %s", firestore.ClientID)
+		return nil
+	}
+}
+
+// purgeExpiredRegistrations periodically deletes registrations whose
+// verification link has expired, until ctx is cancelled
+func purgeExpiredRegistrations(ctx context.Context) {
+	ticker := time.NewTicker(purgeInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		purged, err := handlers.PurgeExpiredRegistrations(ctx)
+		if err != nil {
+			log.Printf("Failed to purge expired registrations: %v", err)
+		} else if purged > 0 {
+			log.Printf("Purged %d expired registrations", purged)
+		}
+	}
+}
+
+// newOIDCProvider builds the single sign-on provider from config, validating role names
+func newOIDCProvider(ctx context.Context, cfg *config.Config) (*auth.OIDCProvider, error) {
+	roleMapping := make(map[string]auth.Role)
+	for group, name := range cfg.OIDCRoleMapping {
+		role, err := auth.ParseRole(name)
+		if err != nil {
+			return nil, fmt.Errorf("OIDC_ROLE_MAPPING for group %q: %w", group, err)
+		}
+		roleMapping[group] = role
+	}
+
+	var defaultRole auth.Role
+	if cfg.OIDCDefaultRole != "" {
+		role, err := auth.ParseRole(cfg.OIDCDefaultRole)
+		if err != nil {
+			return nil, fmt.Errorf("OIDC_DEFAULT_ROLE: %w", err)
+		}
+		defaultRole = role
+	}
+
+	return auth.NewOIDCProvider(ctx, auth.OIDCConfig{
+		IssuerURL:      cfg.OIDCIssuerURL,
+		ClientID:       cfg.OIDCClientID,
+		ClientSecret:   cfg.OIDCClientSecret,
+		RedirectURL:    cfg.OIDCRedirectURL,
+		AllowedDomains: cfg.OIDCAllowedDomains,
+		GroupsClaim:    cfg.OIDCGroupsClaim,
+		RoleMapping:    roleMapping,
+		DefaultRole:    defaultRole,
+	})
+}
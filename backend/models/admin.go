@@ -0,0 +1,59 @@
+package models
+
+import (
+	"time"
+
+	"event-registration-backend/auth"
+)
+
+type RevokedToken struct {
+	TokenID   string    `json:"tokenId" firestore:"-"`
+	Subject   string    `json:"subject" firestore:"subject"`
+	RevokedAt time.Time `json:"revokedAt" firestore:"revokedAt"`
+	ExpiresAt time.Time `json:"expiresAt" firestore:"expiresAt"`
+}
+
+type AdminUser struct {
+	Username     string    `json:"username" firestore:"-"`
+	PasswordHash string    `json:"-" firestore:"passwordHash"`
+	Role         auth.Role `json:"role" firestore:"role"`
+	CreatedAt    time.Time `json:"createdAt" firestore:"createdAt"`
+	// SessionGeneration is bumped when the password changes, ending the
+	// sessions issued before
+	SessionGeneration int `json:"-" firestore:"sessionGeneration,omitempty"`
+}
+
+type AdminUserRequest struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+	Role     string `json:"role"`
+}
+
+type APIKey struct {
+	ID         string            `json:"id" firestore:"-"`
+	Name       string            `json:"name" firestore:"name"`
+	SecretHash string            `json:"-" firestore:"secretHash"`
+	Scopes     []auth.Permission `json:"scopes" firestore:"scopes"`
+	CreatedBy  string            `json:"createdBy" firestore:"createdBy"`
+	CreatedAt  time.Time         `json:"createdAt" firestore:"createdAt"`
+	RevokedAt  *time.Time        `json:"revokedAt,omitempty" firestore:"revokedAt"`
+}
+
+type APIKeyRequest struct {
+	Name   string   `json:"name"`
+	Scopes []string `json:"scopes"`
+}
+
+// APIKeyResponse is returned once when a key is minted; Key is never shown again
+type APIKeyResponse struct {
+	Key    string `json:"key"`
+	APIKey APIKey `json:"apiKey"`
+}
+
+// OIDCFlow is the server-side state of an in-progress single sign-on, keyed by its state parameter
+type OIDCFlow struct {
+	State     string    `json:"state" firestore:"-"`
+	Nonce     string    `json:"-" firestore:"nonce"`
+	Verifier  string    `json:"-" firestore:"verifier"`
+	ExpiresAt time.Time `json:"expiresAt" firestore:"expiresAt"`
+}
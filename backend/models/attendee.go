@@ -2,17 +2,95 @@ package models
 
 import "time"
 
+// Attendee statuses. Attendees registered before the waitlist existed have no
+// stored status and are treated as confirmed. Pending attendees have not yet
+// verified their email and hold no seat.
+const (
+	AttendeePending    = "pending"
+	AttendeeConfirmed  = "confirmed"
+	AttendeeWaitlisted = "waitlisted"
+	AttendeeCancelled  = "cancelled"
+)
+
 type Attendee struct {
-	ID          string    `json:"id" firestore:"-"`
-	FullName    string    `json:"fullName" firestore:"fullName"`
-	Email       string    `json:"email" firestore:"email"`
-	Designation string    `json:"designation" firestore:"designation"`
+	ID           string    `json:"id" firestore:"-"`
+	FullName     string    `json:"fullName" firestore:"fullName"`
+	Email        string    `json:"email" firestore:"email"`
+	Designation  string    `json:"designation" firestore:"designation"`
 	RegisteredAt time.Time `json:"registeredAt" firestore:"registeredAt"`
+	Status       string    `json:"status" firestore:"status"`
+	// WaitlistPosition is the 1-based place in line while the attendee is waitlisted
+	WaitlistPosition int        `json:"waitlistPosition,omitempty" firestore:"waitlistPosition,omitempty"`
+	CancelledAt      *time.Time `json:"cancelledAt,omitempty" firestore:"cancelledAt,omitempty"`
+	// ManageTokenHash verifies the attendee's manage token, which is only
+	// handed out once, in ManageToken of the registration response and of the
+	// confirmation email
+	ManageTokenHash string `json:"-" firestore:"manageTokenHash,omitempty"`
+	ManageToken     string `json:"manageToken,omitempty" firestore:"-"`
+	// VerificationSentAt is when a pending attendee was last emailed its
+	// verification link
+	VerificationSentAt *time.Time `json:"-" firestore:"verificationSentAt,omitempty"`
+	// CheckedInAt is when the attendee's ticket was scanned at the door
+	CheckedInAt *time.Time `json:"checkedInAt,omitempty" firestore:"checkedInAt,omitempty"`
+	// Fields holds the answers to the event's extra registration fields by
+	// field ID
+	Fields map[string]string `json:"fields,omitempty" firestore:"fields,omitempty"`
 }
 
 type RegisterRequest struct {
 	FullName    string `json:"fullName"`
 	Email       string `json:"email"`
 	Designation string `json:"designation"`
+	// Fields answers the event's extra registration fields by field ID
+	Fields map[string]string `json:"fields,omitempty"`
+}
+
+// UpdateRegistrationRequest is the body of a self-service registration change
+type UpdateRegistrationRequest struct {
+	FullName    string `json:"fullName"`
+	Designation string `json:"designation"`
+}
+
+// AttendeeCount is the public registration summary. Count equals Confirmed
+// and is kept for older clients; Capacity and Remaining are omitted when the
+// event has no capacity limit.
+type AttendeeCount struct {
+	Count      int  `json:"count"`
+	Confirmed  int  `json:"confirmed"`
+	Waitlisted int  `json:"waitlisted"`
+	Capacity   int  `json:"capacity,omitempty"`
+	Remaining  *int `json:"remaining,omitempty"`
 }
 
+// CheckInRequest carries the code read from an attendee's QR ticket
+type CheckInRequest struct {
+	Code string `json:"code"`
+}
+
+// CheckInStats is the live check-in progress. ByDesignation counts checked-in
+// attendees per designation.
+type CheckInStats struct {
+	Confirmed     int            `json:"confirmed"`
+	CheckedIn     int            `json:"checkedIn"`
+	Remaining     int            `json:"remaining"`
+	ByDesignation map[string]int `json:"byDesignation"`
+}
+
+// ImportResult reports a bulk attendee import. Imported counts the rows
+// written, or in a dry run the rows that would be.
+type ImportResult struct {
+	DryRun   bool             `json:"dryRun"`
+	Rows     int              `json:"rows"`
+	Imported int              `json:"imported"`
+	Errors   []ImportRowError `json:"errors"`
+}
+
+// ImportRowError describes why one CSV row was not imported. Line is the
+// row's line number in the file; Fields maps attendee fields to messages as
+// in ValidationErrorResponse.
+type ImportRowError struct {
+	Line   int               `json:"line"`
+	Email  string            `json:"email,omitempty"`
+	Fields map[string]string `json:"fields,omitempty"`
+	Error  string            `json:"error,omitempty"`
+}
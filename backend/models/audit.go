@@ -0,0 +1,21 @@
+package models
+
+import "time"
+
+// AuditEntry records one admin mutation. Entries are only ever appended.
+type AuditEntry struct {
+	ID        string                 `json:"id" firestore:"-"`
+	Actor     string                 `json:"actor" firestore:"actor"`
+	Timestamp time.Time              `json:"timestamp" firestore:"timestamp"`
+	Entity    string                 `json:"entity" firestore:"entity"`
+	EntityID  string                 `json:"entityId" firestore:"entityId"`
+	Action    string                 `json:"action" firestore:"action"`
+	Changes   map[string]FieldChange `json:"changes,omitempty" firestore:"changes"`
+	IP        string                 `json:"ip" firestore:"ip"`
+}
+
+// FieldChange holds the before and after value of one changed field
+type FieldChange struct {
+	Before interface{} `json:"before" firestore:"before"`
+	After  interface{} `json:"after" firestore:"after"`
+}
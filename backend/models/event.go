@@ -0,0 +1,9 @@
+package models
+
+// EventDetails describes the event in attendee emails
+type EventDetails struct {
+	Name    string
+	Date    string
+	Venue   string
+	Address string
+}
@@ -0,0 +1,28 @@
+package models
+
+// Registration field types
+const (
+	FieldText     = "text"
+	FieldNumber   = "number"
+	FieldSelect   = "select"
+	FieldCheckbox = "checkbox"
+)
+
+// RegistrationField is an extra question on the registration form, defined by
+// the event's admins. Answers are stored on the attendee under ID.
+type RegistrationField struct {
+	// ID names the answer in RegisterRequest.Fields and Attendee.Fields
+	ID       string `json:"id" firestore:"id"`
+	Type     string `json:"type" firestore:"type"`
+	Label    string `json:"label" firestore:"label"`
+	Required bool   `json:"required" firestore:"required"`
+	// Options lists the allowed answers of a select field
+	Options []string `json:"options,omitempty" firestore:"options,omitempty"`
+	// Pattern is a regular expression a text answer must match in full
+	Pattern string `json:"pattern,omitempty" firestore:"pattern,omitempty"`
+}
+
+// RegistrationForm is the ordered list of an event's extra registration fields
+type RegistrationForm struct {
+	Fields []RegistrationField `json:"fields" firestore:"fields"`
+}
@@ -0,0 +1,8 @@
+package models
+
+// ValidationErrorResponse is returned with status 400 when request fields fail
+// validation. Fields maps each offending JSON field name to a message.
+type ValidationErrorResponse struct {
+	Error  string            `json:"error"`
+	Fields map[string]string `json:"fields"`
+}
@@ -0,0 +1,163 @@
+// Package sqlstore implements the store repositories on SQLite or PostgreSQL.
+// Every row carries the tenant's client ID, mirroring the clients/{clientID}
+// layout used in Firestore, and every query is scoped to it.
+package sqlstore
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+
+	"event-registration-backend/store"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	_ "github.com/jackc/pgx/v5/stdlib"
+	"modernc.org/sqlite"
+	sqlite3 "modernc.org/sqlite/lib"
+)
+
+// Supported database drivers
+const (
+	DriverSQLite   = "sqlite"
+	DriverPostgres = "pgx"
+)
+
+// ErrInstanceRunning is returned by LockInstance when another process
+// already serves the tenant
+var ErrInstanceRunning = errors.New("another instance is serving this client ID")
+
+// DB is a migrated database scoped to one tenant
+type DB struct {
+	db       *sql.DB
+	driver   string
+	clientID string
+	// instance holds the tenant's instance lock, see LockInstance
+	instance *sql.Conn
+}
+
+// Open connects with driver (DriverSQLite or DriverPostgres), applies pending
+// migrations and returns a handle scoped to clientID
+func Open(ctx context.Context, driver, dsn, clientID string) (*DB, error) {
+	if driver != DriverSQLite && driver != DriverPostgres {
+		return nil, fmt.Errorf("unsupported SQL driver %q", driver)
+	}
+	if clientID == "" {
+		return nil, fmt.Errorf("client ID is required")
+	}
+
+	db, err := sql.Open(driver, dsn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database: %w", err)
+	}
+	if driver == DriverSQLite {
+		// SQLite allows a single writer; serializing through one connection
+		// avoids SQLITE_BUSY and keeps ":memory:" databases shared
+		db.SetMaxOpenConns(1)
+	}
+	if err := db.PingContext(ctx); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to connect to database: %w", err)
+	}
+	if err := migrate(ctx, db); err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	return &DB{db: db, driver: driver, clientID: clientID}, nil
+}
+
+// Close releases the instance lock and closes the underlying connection pool
+func (d *DB) Close() error {
+	if d.instance != nil {
+		d.instance.ExecContext(context.Background(), `SELECT pg_advisory_unlock(hashtext($1))`, d.instanceKey())
+		d.instance.Close()
+		d.instance = nil
+	}
+	return d.db.Close()
+}
+
+// LockInstance claims the tenant for this process until Close, returning
+// ErrInstanceRunning if another process holds it. Callers that keep tenant
+// state outside the database use it to refuse running more than one instance.
+// On PostgreSQL this is a session advisory lock, which the server also
+// releases when the process dies. A SQLite database is a file on one host, so
+// there is nothing to lock.
+func (d *DB) LockInstance(ctx context.Context) error {
+	if d.driver != DriverPostgres || d.instance != nil {
+		return nil
+	}
+	conn, err := d.db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	var locked bool
+	if err := conn.QueryRowContext(ctx, `SELECT pg_try_advisory_lock(hashtext($1))`, d.instanceKey()).Scan(&locked); err != nil {
+		conn.Close()
+		return err
+	}
+	if !locked {
+		conn.Close()
+		return ErrInstanceRunning
+	}
+	d.instance = conn
+	return nil
+}
+
+// instanceKey names the tenant's instance lock; attendee transactions lock
+// a different key
+func (d *DB) instanceKey() string {
+	return "instance/" + d.clientID
+}
+
+// Stores returns the repositories backed by this database
+func (d *DB) Stores() store.Stores {
+	return store.Stores{
+		Attendees: &AttendeeStore{d},
+		Sessions:  &SessionStore{d},
+		Speakers:  &SpeakerStore{d},
+		Fields:    &FieldStore{d},
+	}
+}
+
+// beginAttendeeTx starts a transaction that holds the tenant's attendee lock,
+// so capacity decisions see every earlier registration
+func (d *DB) beginAttendeeTx(ctx context.Context) (*sql.Tx, error) {
+	tx, err := d.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	// SQLite runs on a single connection, so its transactions are already serialized
+	if d.driver == DriverPostgres {
+		if _, err := tx.ExecContext(ctx, `SELECT pg_advisory_xact_lock(hashtext($1))`, "attendees/"+d.clientID); err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+	}
+	return tx, nil
+}
+
+// newID returns a random 20-character document ID, the same length Firestore generates
+func newID() (string, error) {
+	b := make([]byte, 10)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// isUniqueViolation reports whether err is a primary key or unique index conflict
+func isUniqueViolation(err error) bool {
+	var sqliteErr *sqlite.Error
+	if errors.As(err, &sqliteErr) {
+		code := sqliteErr.Code()
+		return code == sqlite3.SQLITE_CONSTRAINT_UNIQUE || code == sqlite3.SQLITE_CONSTRAINT_PRIMARYKEY
+	}
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		return pgErr.Code == "23505"
+	}
+	return false
+}
@@ -0,0 +1,105 @@
+package sqlstore
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+// migrations are applied in order and recorded in schema_migrations. Never
+// edit an applied migration; append a new one instead. Statements must run on
+// both SQLite and PostgreSQL.
+var migrations = []string{
+	// 1: initial schema
+	`CREATE TABLE attendees (
+		client_id     TEXT NOT NULL,
+		id            TEXT NOT NULL,
+		full_name     TEXT NOT NULL,
+		email         TEXT NOT NULL,
+		designation   TEXT NOT NULL,
+		registered_at TIMESTAMP NOT NULL,
+		PRIMARY KEY (client_id, id)
+	);
+	CREATE INDEX attendees_email ON attendees (client_id, email);
+	CREATE TABLE speakers (
+		client_id TEXT NOT NULL,
+		id        TEXT NOT NULL,
+		name      TEXT NOT NULL,
+		bio       TEXT NOT NULL,
+		photo_url TEXT NOT NULL,
+		PRIMARY KEY (client_id, id)
+	);
+	CREATE TABLE sessions (
+		client_id   TEXT NOT NULL,
+		id          TEXT NOT NULL,
+		title       TEXT NOT NULL,
+		description TEXT NOT NULL,
+		time        TEXT NOT NULL,
+		speaker_id  TEXT NOT NULL,
+		PRIMARY KEY (client_id, id)
+	);`,
+	// 2: one registration per email address, ignoring case
+	`CREATE UNIQUE INDEX attendees_email_unique ON attendees (client_id, lower(email));`,
+	// 3: capacity and waitlist; existing attendees stay confirmed
+	`ALTER TABLE attendees ADD COLUMN status TEXT NOT NULL DEFAULT 'confirmed';
+	ALTER TABLE attendees ADD COLUMN waitlist_position INTEGER NOT NULL DEFAULT 0;
+	ALTER TABLE attendees ADD COLUMN cancelled_at TIMESTAMP;`,
+	// 4: self-service manage tokens; older registrations have none
+	`ALTER TABLE attendees ADD COLUMN manage_token_hash TEXT NOT NULL DEFAULT '';`,
+	// 5: door check-in
+	`ALTER TABLE attendees ADD COLUMN checked_in_at TIMESTAMP;`,
+	// 6: custom registration fields; answers are a JSON object by field ID
+	`ALTER TABLE attendees ADD COLUMN fields TEXT NOT NULL DEFAULT '{}';
+	CREATE TABLE registration_fields (
+		client_id TEXT NOT NULL,
+		ordinal   INTEGER NOT NULL,
+		id        TEXT NOT NULL,
+		type      TEXT NOT NULL,
+		label     TEXT NOT NULL,
+		required  BOOLEAN NOT NULL,
+		options   TEXT NOT NULL,
+		pattern   TEXT NOT NULL,
+		PRIMARY KEY (client_id, id)
+	);`,
+	// 7: seat counts and the waitlist order without scanning every registration
+	`CREATE INDEX attendees_status ON attendees (client_id, status, waitlist_position);`,
+	// 8: throttled resends of the verification link
+	`ALTER TABLE attendees ADD COLUMN verification_sent_at TIMESTAMP;`,
+}
+
+// migrate applies every migration newer than the recorded schema version
+func migrate(ctx context.Context, db *sql.DB) error {
+	if _, err := db.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS schema_migrations (version INTEGER PRIMARY KEY)`); err != nil {
+		return fmt.Errorf("failed to create schema_migrations: %w", err)
+	}
+
+	var current int
+	if err := db.QueryRowContext(ctx, `SELECT COALESCE(MAX(version), 0) FROM schema_migrations`).Scan(&current); err != nil {
+		return fmt.Errorf("failed to read schema version: %w", err)
+	}
+
+	for i := current; i < len(migrations); i++ {
+		version := i + 1
+		if err := applyMigration(ctx, db, version, migrations[i]); err != nil {
+			return fmt.Errorf("failed to apply migration %d: %w", version, err)
+		}
+	}
+	return nil
+}
+
+// applyMigration runs one migration and records it in the same transaction
+func applyMigration(ctx context.Context, db *sql.DB, version int, statements string) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, statements); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, `INSERT INTO schema_migrations (version) VALUES ($1)`, version); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
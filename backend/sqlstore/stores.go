@@ -0,0 +1,742 @@
+package sqlstore
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"event-registration-backend/models"
+	"event-registration-backend/store"
+)
+
+// AttendeeStore implements store.AttendeeStore on the attendees table
+type AttendeeStore struct {
+	d *DB
+}
+
+const attendeeColumns = `id, full_name, email, designation, registered_at, status, waitlist_position, cancelled_at, manage_token_hash, checked_in_at, verification_sent_at, fields`
+
+// rowScanner is satisfied by *sql.Row and *sql.Rows
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanAttendee(row rowScanner) (models.Attendee, error) {
+	var attendee models.Attendee
+	var cancelledAt, checkedInAt, verificationSentAt sql.NullTime
+	var fields string
+	err := row.Scan(&attendee.ID, &attendee.FullName, &attendee.Email, &attendee.Designation, &attendee.RegisteredAt,
+		&attendee.Status, &attendee.WaitlistPosition, &cancelledAt, &attendee.ManageTokenHash, &checkedInAt, &verificationSentAt, &fields)
+	if err != nil {
+		return attendee, err
+	}
+	if err := decodeJSON(fields, &attendee.Fields); err != nil {
+		return attendee, err
+	}
+	if cancelledAt.Valid {
+		attendee.CancelledAt = &cancelledAt.Time
+	}
+	if checkedInAt.Valid {
+		attendee.CheckedInAt = &checkedInAt.Time
+	}
+	if verificationSentAt.Valid {
+		attendee.VerificationSentAt = &verificationSentAt.Time
+	}
+	return attendee, nil
+}
+
+// encodeJSON stores a map or slice column as JSON text, nil as its empty form
+func encodeJSON(v interface{}, empty string) (string, error) {
+	b, err := json.Marshal(v)
+	if err != nil || string(b) == "null" {
+		return empty, err
+	}
+	return string(b), nil
+}
+
+// decodeJSON reads a column written by encodeJSON, leaving empty
+// collections nil
+func decodeJSON(s string, dest interface{}) error {
+	if s == "" || s == "{}" || s == "[]" {
+		return nil
+	}
+	return json.Unmarshal([]byte(s), dest)
+}
+
+// queryer is satisfied by *sql.DB and *sql.Tx
+type queryer interface {
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+}
+
+// queryAttendees runs a query selecting attendeeColumns
+func queryAttendees(ctx context.Context, q queryer, query string, args ...interface{}) ([]models.Attendee, error) {
+	rows, err := q.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var attendees []models.Attendee
+	for rows.Next() {
+		attendee, err := scanAttendee(rows)
+		if err != nil {
+			return nil, err
+		}
+		attendees = append(attendees, attendee)
+	}
+	return attendees, rows.Err()
+}
+
+func (s *AttendeeStore) Create(ctx context.Context, attendee *models.Attendee, capacity int) error {
+	tx, err := s.d.beginAttendeeTx(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := s.checkAvailable(ctx, tx, attendee.Email); err != nil {
+		return err
+	}
+	confirmed, waitlisted, err := s.countSeats(ctx, tx)
+	if err != nil {
+		return err
+	}
+	store.Seat(attendee, confirmed, waitlisted, capacity)
+
+	// A cancelled registration makes way for the new one
+	if _, err := tx.ExecContext(ctx,
+		`DELETE FROM attendees WHERE client_id = $1 AND lower(email) = lower($2) AND status = $3`,
+		s.d.clientID, attendee.Email, models.AttendeeCancelled); err != nil {
+		return err
+	}
+	id := store.AttendeeID(attendee.Email)
+	fields, err := encodeJSON(attendee.Fields, "{}")
+	if err != nil {
+		return err
+	}
+	var verificationSentAt sql.NullTime
+	if attendee.VerificationSentAt != nil {
+		verificationSentAt = sql.NullTime{Time: attendee.VerificationSentAt.UTC(), Valid: true}
+	}
+	_, err = tx.ExecContext(ctx,
+		`INSERT INTO attendees (client_id, id, full_name, email, designation, registered_at, status, waitlist_position, manage_token_hash, verification_sent_at, fields) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)`,
+		s.d.clientID, id, attendee.FullName, attendee.Email, attendee.Designation, attendee.RegisteredAt.UTC(), attendee.Status, attendee.WaitlistPosition, attendee.ManageTokenHash, verificationSentAt, fields)
+	if isUniqueViolation(err) {
+		return store.ErrAlreadyExists
+	}
+	if err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	attendee.ID = id
+	return nil
+}
+
+func (s *AttendeeStore) CheckAvailable(ctx context.Context, email string) error {
+	return s.checkAvailable(ctx, s.d.db, email)
+}
+
+// checkAvailable returns store.ErrAlreadyExists if an active registration
+// holds email
+func (s *AttendeeStore) checkAvailable(ctx context.Context, q queryer, email string) error {
+	rows, err := q.QueryContext(ctx,
+		`SELECT id FROM attendees WHERE client_id = $1 AND lower(email) = lower($2) AND status <> $3`,
+		s.d.clientID, email, models.AttendeeCancelled)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	if rows.Next() {
+		return store.ErrAlreadyExists
+	}
+	return rows.Err()
+}
+
+func (s *AttendeeStore) Get(ctx context.Context, id string) (*models.Attendee, error) {
+	row := s.d.db.QueryRowContext(ctx,
+		`SELECT `+attendeeColumns+` FROM attendees WHERE client_id = $1 AND id = $2`,
+		s.d.clientID, id)
+	attendee, err := scanAttendee(row)
+	if err == sql.ErrNoRows {
+		return nil, store.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &attendee, nil
+}
+
+func (s *AttendeeStore) List(ctx context.Context) ([]models.Attendee, error) {
+	return queryAttendees(ctx, s.d.db, `SELECT `+attendeeColumns+` FROM attendees WHERE client_id = $1`, s.d.clientID)
+}
+
+// eachBatchSize is how many attendees Each reads per query
+var eachBatchSize = 500
+
+func (s *AttendeeStore) Each(ctx context.Context, fn func(models.Attendee) error) error {
+	// Reading in keyset-paginated batches keeps memory bounded without holding
+	// a connection, which SQLite has only one of, while fn runs
+	batch, err := queryAttendees(ctx, s.d.db,
+		`SELECT `+attendeeColumns+` FROM attendees WHERE client_id = $1 ORDER BY registered_at, id LIMIT $2`,
+		s.d.clientID, eachBatchSize)
+	for err == nil && len(batch) > 0 {
+		for _, attendee := range batch {
+			if err := fn(attendee); err != nil {
+				return err
+			}
+		}
+		if len(batch) < eachBatchSize {
+			return nil
+		}
+		last := batch[len(batch)-1]
+		batch, err = queryAttendees(ctx, s.d.db,
+			`SELECT `+attendeeColumns+` FROM attendees WHERE client_id = $1 AND (registered_at > $2 OR (registered_at = $2 AND id > $3)) ORDER BY registered_at, id LIMIT $4`,
+			s.d.clientID, last.RegisteredAt.UTC(), last.ID, eachBatchSize)
+	}
+	return err
+}
+
+func (s *AttendeeStore) CountByStatus(ctx context.Context) (map[string]int, error) {
+	rows, err := s.d.db.QueryContext(ctx,
+		`SELECT status, COUNT(*) FROM attendees WHERE client_id = $1 GROUP BY status`, s.d.clientID)
+	if err != nil {
+		return nil, err
+	}
+	counts := map[string]int{
+		models.AttendeeConfirmed:  0,
+		models.AttendeeWaitlisted: 0,
+		models.AttendeeCancelled:  0,
+		models.AttendeePending:    0,
+	}
+	if err := scanCounts(rows, counts); err != nil {
+		return nil, err
+	}
+	return counts, nil
+}
+
+func (s *AttendeeStore) CountByDesignation(ctx context.Context, status string) (map[string]int, error) {
+	rows, err := s.d.db.QueryContext(ctx,
+		`SELECT designation, COUNT(*) FROM attendees WHERE client_id = $1 AND status = $2 GROUP BY designation`,
+		s.d.clientID, status)
+	if err != nil {
+		return nil, err
+	}
+	counts := make(map[string]int)
+	if err := scanCounts(rows, counts); err != nil {
+		return nil, err
+	}
+	return counts, nil
+}
+
+// CountByField decodes the answers in Go, since SQLite and PostgreSQL read
+// JSON with different functions
+func (s *AttendeeStore) CountByField(ctx context.Context, status, id string, options []string) (map[string]int, error) {
+	rows, err := s.d.db.QueryContext(ctx,
+		`SELECT fields FROM attendees WHERE client_id = $1 AND status = $2 AND fields <> '{}'`,
+		s.d.clientID, status)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	counts := make(map[string]int, len(options))
+	for _, option := range options {
+		counts[option] = 0
+	}
+	for rows.Next() {
+		var encoded string
+		var fields map[string]string
+		if err := rows.Scan(&encoded); err != nil {
+			return nil, err
+		}
+		if err := decodeJSON(encoded, &fields); err != nil {
+			return nil, err
+		}
+		if n, ok := counts[fields[id]]; ok {
+			counts[fields[id]] = n + 1
+		}
+	}
+	return counts, rows.Err()
+}
+
+// scanCounts reads key and count rows into counts and closes rows
+func scanCounts(rows *sql.Rows, counts map[string]int) error {
+	defer rows.Close()
+	for rows.Next() {
+		var key string
+		var n int
+		if err := rows.Scan(&key, &n); err != nil {
+			return err
+		}
+		counts[key] = n
+	}
+	return rows.Err()
+}
+
+// attendeeSortColumns maps the store.Sort fields to their columns
+var attendeeSortColumns = map[string]string{
+	store.SortRegisteredAt: "registered_at",
+	store.SortFullName:     "full_name",
+	store.SortEmail:        "email",
+}
+
+func (s *AttendeeStore) Search(ctx context.Context, q store.AttendeeQuery) ([]models.Attendee, error) {
+	column, ok := attendeeSortColumns[q.Sort]
+	if !ok {
+		column = "registered_at"
+	}
+	op, dir := ">", "ASC"
+	if q.Descending {
+		op, dir = "<", "DESC"
+	}
+
+	conditions := []string{"client_id = $1"}
+	args := []interface{}{s.d.clientID}
+	param := func(value interface{}) string {
+		if t, ok := value.(time.Time); ok {
+			value = t.UTC()
+		}
+		args = append(args, value)
+		return "$" + strconv.Itoa(len(args))
+	}
+	in := func(values []string) string {
+		params := make([]string, len(values))
+		for i, v := range values {
+			params[i] = param(strings.ToLower(v))
+		}
+		return "(" + strings.Join(params, ", ") + ")"
+	}
+
+	if q.Search != "" {
+		p := param("%" + escapeLike(strings.ToLower(q.Search)) + "%")
+		conditions = append(conditions, `(LOWER(full_name) LIKE `+p+` ESCAPE '\' OR LOWER(email) LIKE `+p+` ESCAPE '\')`)
+	}
+	if len(q.Designations) > 0 {
+		conditions = append(conditions, "LOWER(designation) IN "+in(q.Designations))
+	}
+	if len(q.Statuses) > 0 {
+		conditions = append(conditions, "LOWER(status) IN "+in(q.Statuses))
+	}
+	if !q.RegisteredFrom.IsZero() {
+		conditions = append(conditions, "registered_at >= "+param(q.RegisteredFrom))
+	}
+	if !q.RegisteredTo.IsZero() {
+		conditions = append(conditions, "registered_at < "+param(q.RegisteredTo))
+	}
+	if q.After != nil {
+		key, id := param(q.After.Key), param(q.After.ID)
+		conditions = append(conditions, "("+column+" "+op+" "+key+" OR ("+column+" = "+key+" AND id "+op+" "+id+"))")
+	}
+
+	query := `SELECT ` + attendeeColumns + ` FROM attendees WHERE ` + strings.Join(conditions, " AND ") +
+		` ORDER BY ` + column + ` ` + dir + `, id ` + dir
+	if q.Limit > 0 {
+		query += ` LIMIT ` + param(q.Limit)
+	}
+	return queryAttendees(ctx, s.d.db, query, args...)
+}
+
+// escapeLike escapes the LIKE wildcards in s, for use with ESCAPE '\'
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
+
+func (s *AttendeeStore) UpdateDetails(ctx context.Context, id, fullName, designation string) (*models.Attendee, error) {
+	result, err := s.d.db.ExecContext(ctx,
+		`UPDATE attendees SET full_name = $1, designation = $2 WHERE client_id = $3 AND id = $4 AND status <> $5`,
+		fullName, designation, s.d.clientID, id, models.AttendeeCancelled)
+	if err != nil {
+		return nil, err
+	}
+	if n, err := result.RowsAffected(); err != nil {
+		return nil, err
+	} else if n == 0 {
+		return nil, store.ErrNotFound
+	}
+	return s.Get(ctx, id)
+}
+
+func (s *AttendeeStore) Cancel(ctx context.Context, id string, capacity int) (*models.Attendee, []models.Attendee, error) {
+	tx, err := s.d.beginAttendeeTx(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer tx.Rollback()
+
+	cancelled, err := s.getActive(ctx, tx, id)
+	if err != nil {
+		return nil, nil, err
+	}
+	var vacated []int
+	if position := store.Cancel(cancelled, time.Now()); position > 0 {
+		vacated = append(vacated, position)
+	}
+	if err := updateSeat(ctx, tx, s.d.clientID, *cancelled); err != nil {
+		return nil, nil, err
+	}
+
+	confirmed, waitlisted, err := s.countSeats(ctx, tx)
+	if err != nil {
+		return nil, nil, err
+	}
+	var promoted []models.Attendee
+	for ; waitlisted > 0 && store.HasSeat(confirmed, capacity); confirmed, waitlisted = confirmed+1, waitlisted-1 {
+		row := tx.QueryRowContext(ctx,
+			`SELECT `+attendeeColumns+` FROM attendees WHERE client_id = $1 AND status = $2 ORDER BY waitlist_position LIMIT 1`,
+			s.d.clientID, models.AttendeeWaitlisted)
+		next, err := scanAttendee(row)
+		if err != nil {
+			return nil, nil, err
+		}
+		vacated = append(vacated, store.Promote(&next))
+		if err := updateSeat(ctx, tx, s.d.clientID, next); err != nil {
+			return nil, nil, err
+		}
+		promoted = append(promoted, next)
+	}
+
+	// Close the gaps from the back, so that each shift sees the positions
+	// the previous ones left
+	sort.Sort(sort.Reverse(sort.IntSlice(vacated)))
+	for _, position := range vacated {
+		if _, err := tx.ExecContext(ctx,
+			`UPDATE attendees SET waitlist_position = waitlist_position - 1 WHERE client_id = $1 AND status = $2 AND waitlist_position > $3`,
+			s.d.clientID, models.AttendeeWaitlisted, position); err != nil {
+			return nil, nil, err
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, nil, err
+	}
+	return cancelled, promoted, nil
+}
+
+func (s *AttendeeStore) Verify(ctx context.Context, id, manageTokenHash string, capacity int) (*models.Attendee, bool, error) {
+	tx, err := s.d.beginAttendeeTx(ctx)
+	if err != nil {
+		return nil, false, err
+	}
+	defer tx.Rollback()
+
+	attendee, err := s.getActive(ctx, tx, id)
+	if err != nil {
+		return nil, false, err
+	}
+	confirmed, waitlisted, err := s.countSeats(ctx, tx)
+	if err != nil {
+		return nil, false, err
+	}
+	if !store.Verify(attendee, confirmed, waitlisted, capacity) {
+		return attendee, false, nil
+	}
+	attendee.ManageTokenHash = manageTokenHash
+	if _, err := tx.ExecContext(ctx,
+		`UPDATE attendees SET status = $1, waitlist_position = $2, manage_token_hash = $3 WHERE client_id = $4 AND id = $5`,
+		attendee.Status, attendee.WaitlistPosition, manageTokenHash, s.d.clientID, id); err != nil {
+		return nil, false, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, false, err
+	}
+	return attendee, true, nil
+}
+
+// getActive reads the attendee with id within tx, returning ErrNotFound if
+// there is no such active registration
+func (s *AttendeeStore) getActive(ctx context.Context, tx *sql.Tx, id string) (*models.Attendee, error) {
+	row := tx.QueryRowContext(ctx,
+		`SELECT `+attendeeColumns+` FROM attendees WHERE client_id = $1 AND id = $2 AND status <> $3`,
+		s.d.clientID, id, models.AttendeeCancelled)
+	attendee, err := scanAttendee(row)
+	if err == sql.ErrNoRows {
+		return nil, store.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &attendee, nil
+}
+
+// countSeats counts the confirmed and waitlisted attendees within tx
+func (s *AttendeeStore) countSeats(ctx context.Context, tx *sql.Tx) (confirmed, waitlisted int, err error) {
+	rows, err := tx.QueryContext(ctx,
+		`SELECT status, COUNT(*) FROM attendees WHERE client_id = $1 AND status IN ($2, $3) GROUP BY status`,
+		s.d.clientID, models.AttendeeConfirmed, models.AttendeeWaitlisted)
+	if err != nil {
+		return 0, 0, err
+	}
+	counts := make(map[string]int)
+	if err := scanCounts(rows, counts); err != nil {
+		return 0, 0, err
+	}
+	return counts[models.AttendeeConfirmed], counts[models.AttendeeWaitlisted], nil
+}
+
+// updateSeat stores the status and waitlist position of attendee within tx
+func updateSeat(ctx context.Context, tx *sql.Tx, clientID string, attendee models.Attendee) error {
+	var cancelledAt interface{}
+	if attendee.CancelledAt != nil {
+		cancelledAt = attendee.CancelledAt.UTC()
+	}
+	_, err := tx.ExecContext(ctx,
+		`UPDATE attendees SET status = $1, waitlist_position = $2, cancelled_at = $3 WHERE client_id = $4 AND id = $5`,
+		attendee.Status, attendee.WaitlistPosition, cancelledAt, clientID, attendee.ID)
+	return err
+}
+
+func (s *AttendeeStore) MarkVerificationSent(ctx context.Context, id string, at time.Time, cooldown time.Duration) (bool, error) {
+	// The condition on verification_sent_at lets only one of several
+	// concurrent resends through
+	result, err := s.d.db.ExecContext(ctx,
+		`UPDATE attendees SET verification_sent_at = $1 WHERE client_id = $2 AND id = $3 AND status = $4 AND (verification_sent_at IS NULL OR verification_sent_at <= $5)`,
+		at.UTC(), s.d.clientID, id, models.AttendeePending, at.Add(-cooldown).UTC())
+	if err != nil {
+		return false, err
+	}
+	updated, err := result.RowsAffected()
+	if err != nil || updated > 0 {
+		return updated > 0, err
+	}
+
+	attendee, err := s.Get(ctx, id)
+	if err != nil {
+		return false, err
+	}
+	if attendee.Status != models.AttendeePending {
+		return false, store.ErrNotFound
+	}
+	return false, nil
+}
+
+func (s *AttendeeStore) PurgePending(ctx context.Context, cutoff time.Time) (int, error) {
+	result, err := s.d.db.ExecContext(ctx,
+		`DELETE FROM attendees WHERE client_id = $1 AND status = $2 AND registered_at < $3`,
+		s.d.clientID, models.AttendeePending, cutoff.UTC())
+	if err != nil {
+		return 0, err
+	}
+	purged, err := result.RowsAffected()
+	return int(purged), err
+}
+
+func (s *AttendeeStore) CheckIn(ctx context.Context, id string, at time.Time) (*models.Attendee, error) {
+	// The condition on checked_in_at makes concurrent scans of one ticket
+	// check the attendee in only once
+	result, err := s.d.db.ExecContext(ctx,
+		`UPDATE attendees SET checked_in_at = $1 WHERE client_id = $2 AND id = $3 AND status = $4 AND checked_in_at IS NULL`,
+		at.UTC(), s.d.clientID, id, models.AttendeeConfirmed)
+	if err != nil {
+		return nil, err
+	}
+	updated, err := result.RowsAffected()
+	if err != nil {
+		return nil, err
+	}
+
+	attendee, err := s.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if updated == 0 {
+		if attendee.Status != models.AttendeeConfirmed {
+			return nil, store.ErrNotFound
+		}
+		return attendee, store.ErrAlreadyCheckedIn
+	}
+	return attendee, nil
+}
+
+func (s *AttendeeStore) CheckInStats(ctx context.Context) (*models.CheckInStats, error) {
+	stats := models.CheckInStats{ByDesignation: make(map[string]int)}
+	if err := s.d.db.QueryRowContext(ctx,
+		`SELECT COUNT(*) FROM attendees WHERE client_id = $1 AND status = $2`,
+		s.d.clientID, models.AttendeeConfirmed).Scan(&stats.Confirmed); err != nil {
+		return nil, err
+	}
+	rows, err := s.d.db.QueryContext(ctx,
+		`SELECT designation, COUNT(*) FROM attendees WHERE client_id = $1 AND status = $2 AND checked_in_at IS NOT NULL GROUP BY designation`,
+		s.d.clientID, models.AttendeeConfirmed)
+	if err != nil {
+		return nil, err
+	}
+	if err := scanCounts(rows, stats.ByDesignation); err != nil {
+		return nil, err
+	}
+	for _, n := range stats.ByDesignation {
+		stats.CheckedIn += n
+	}
+	stats.Remaining = stats.Confirmed - stats.CheckedIn
+	return &stats, nil
+}
+
+// SessionStore implements store.SessionStore on the sessions table
+type SessionStore struct {
+	d *DB
+}
+
+func (s *SessionStore) Get(ctx context.Context, id string) (*models.Session, error) {
+	row := s.d.db.QueryRowContext(ctx,
+		`SELECT id, title, description, time, speaker_id FROM sessions WHERE client_id = $1 AND id = $2`,
+		s.d.clientID, id)
+	var session models.Session
+	err := row.Scan(&session.ID, &session.Title, &session.Description, &session.Time, &session.SpeakerID)
+	if err == sql.ErrNoRows {
+		return nil, store.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
+
+func (s *SessionStore) List(ctx context.Context) ([]models.Session, error) {
+	rows, err := s.d.db.QueryContext(ctx,
+		`SELECT id, title, description, time, speaker_id FROM sessions WHERE client_id = $1`,
+		s.d.clientID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var sessions []models.Session
+	for rows.Next() {
+		var session models.Session
+		if err := rows.Scan(&session.ID, &session.Title, &session.Description, &session.Time, &session.SpeakerID); err != nil {
+			return nil, err
+		}
+		sessions = append(sessions, session)
+	}
+	return sessions, rows.Err()
+}
+
+func (s *SessionStore) Create(ctx context.Context, session *models.Session) error {
+	id, err := newID()
+	if err != nil {
+		return err
+	}
+	session.ID = id
+	return s.Save(ctx, session)
+}
+
+func (s *SessionStore) Save(ctx context.Context, session *models.Session) error {
+	_, err := s.d.db.ExecContext(ctx,
+		`INSERT INTO sessions (client_id, id, title, description, time, speaker_id) VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (client_id, id) DO UPDATE SET title = excluded.title, description = excluded.description, time = excluded.time, speaker_id = excluded.speaker_id`,
+		s.d.clientID, session.ID, session.Title, session.Description, session.Time, session.SpeakerID)
+	return err
+}
+
+// SpeakerStore implements store.SpeakerStore on the speakers table
+type SpeakerStore struct {
+	d *DB
+}
+
+func (s *SpeakerStore) Get(ctx context.Context, id string) (*models.Speaker, error) {
+	row := s.d.db.QueryRowContext(ctx,
+		`SELECT id, name, bio, photo_url FROM speakers WHERE client_id = $1 AND id = $2`,
+		s.d.clientID, id)
+	var speaker models.Speaker
+	err := row.Scan(&speaker.ID, &speaker.Name, &speaker.Bio, &speaker.PhotoURL)
+	if err == sql.ErrNoRows {
+		return nil, store.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &speaker, nil
+}
+
+func (s *SpeakerStore) List(ctx context.Context) ([]models.Speaker, error) {
+	rows, err := s.d.db.QueryContext(ctx,
+		`SELECT id, name, bio, photo_url FROM speakers WHERE client_id = $1`,
+		s.d.clientID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var speakers []models.Speaker
+	for rows.Next() {
+		var speaker models.Speaker
+		if err := rows.Scan(&speaker.ID, &speaker.Name, &speaker.Bio, &speaker.PhotoURL); err != nil {
+			return nil, err
+		}
+		speakers = append(speakers, speaker)
+	}
+	return speakers, rows.Err()
+}
+
+func (s *SpeakerStore) Create(ctx context.Context, speaker *models.Speaker) error {
+	id, err := newID()
+	if err != nil {
+		return err
+	}
+	speaker.ID = id
+	return s.Save(ctx, speaker)
+}
+
+func (s *SpeakerStore) Save(ctx context.Context, speaker *models.Speaker) error {
+	_, err := s.d.db.ExecContext(ctx,
+		`INSERT INTO speakers (client_id, id, name, bio, photo_url) VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (client_id, id) DO UPDATE SET name = excluded.name, bio = excluded.bio, photo_url = excluded.photo_url`,
+		s.d.clientID, speaker.ID, speaker.Name, speaker.Bio, speaker.PhotoURL)
+	return err
+}
+
+// FieldStore implements store.FieldStore on the registration_fields table
+type FieldStore struct {
+	d *DB
+}
+
+func (s *FieldStore) List(ctx context.Context) ([]models.RegistrationField, error) {
+	rows, err := s.d.db.QueryContext(ctx,
+		`SELECT id, type, label, required, options, pattern FROM registration_fields WHERE client_id = $1 ORDER BY ordinal`,
+		s.d.clientID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var fields []models.RegistrationField
+	for rows.Next() {
+		var field models.RegistrationField
+		var options string
+		if err := rows.Scan(&field.ID, &field.Type, &field.Label, &field.Required, &options, &field.Pattern); err != nil {
+			return nil, err
+		}
+		if err := decodeJSON(options, &field.Options); err != nil {
+			return nil, err
+		}
+		fields = append(fields, field)
+	}
+	return fields, rows.Err()
+}
+
+func (s *FieldStore) Replace(ctx context.Context, fields []models.RegistrationField) error {
+	tx, err := s.d.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM registration_fields WHERE client_id = $1`, s.d.clientID); err != nil {
+		return err
+	}
+	for i, field := range fields {
+		options, err := encodeJSON(field.Options, "[]")
+		if err != nil {
+			return err
+		}
+		if _, err := tx.ExecContext(ctx,
+			`INSERT INTO registration_fields (client_id, ordinal, id, type, label, required, options, pattern) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`,
+			s.d.clientID, i, field.ID, field.Type, field.Label, field.Required, options, field.Pattern); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
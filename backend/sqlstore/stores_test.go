@@ -0,0 +1,499 @@
+package sqlstore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"event-registration-backend/models"
+	"event-registration-backend/store"
+)
+
+// openTestDB opens a migrated database for clientID. It uses PostgreSQL when
+// SQLSTORE_POSTGRES_URL is set and otherwise a SQLite file shared by the test.
+func openTestDB(t *testing.T, dsn, clientID string) *DB {
+	t.Helper()
+	driver := DriverSQLite
+	if url := os.Getenv("SQLSTORE_POSTGRES_URL"); url != "" {
+		driver, dsn = DriverPostgres, url
+	}
+	db, err := Open(context.Background(), driver, dsn, clientID)
+	if err != nil {
+		t.Fatalf("Failed to open database: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+func testDSN(t *testing.T) string {
+	return filepath.Join(t.TempDir(), "events.db")
+}
+
+func TestAttendeeStore(t *testing.T) {
+	ctx := context.Background()
+	attendees := openTestDB(t, testDSN(t), "client-"+t.Name()).Stores().Attendees
+
+	registeredAt := time.Date(2026, 3, 14, 9, 30, 0, 0, time.UTC)
+	attendee := models.Attendee{FullName: "Ada Lovelace", Email: "ada@example.com", Designation: "Engineer", RegisteredAt: registeredAt, ManageTokenHash: "hash"}
+	if err := attendees.Create(ctx, &attendee, 0); err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	if attendee.ID == "" {
+		t.Fatal("Expected Create to set the attendee ID")
+	}
+
+	found, err := attendees.Get(ctx, store.AttendeeID("ADA@example.com"))
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	if found.ID != attendee.ID || found.FullName != "Ada Lovelace" || !found.RegisteredAt.Equal(registeredAt) || found.ManageTokenHash != "hash" {
+		t.Errorf("Unexpected attendee: %+v", found)
+	}
+	if _, err := attendees.Get(ctx, store.AttendeeID("grace@example.com")); err != store.ErrNotFound {
+		t.Errorf("Expected ErrNotFound for unknown attendee, got %v", err)
+	}
+
+	duplicate := models.Attendee{FullName: "Ada", Email: "ADA@example.com", Designation: "Engineer", RegisteredAt: registeredAt}
+	if err := attendees.Create(ctx, &duplicate, 0); err != store.ErrAlreadyExists {
+		t.Errorf("Expected ErrAlreadyExists for the same email in different case, got %v", err)
+	}
+
+	updated, err := attendees.UpdateDetails(ctx, attendee.ID, "Ada King", "Mathematician")
+	if err != nil || updated.FullName != "Ada King" || updated.Designation != "Mathematician" || updated.Email != "ada@example.com" {
+		t.Errorf("Unexpected UpdateDetails result: %+v (err %v)", updated, err)
+	}
+	if _, err := attendees.UpdateDetails(ctx, "missing", "A", "B"); err != store.ErrNotFound {
+		t.Errorf("Expected ErrNotFound updating unknown attendee, got %v", err)
+	}
+
+	list, err := attendees.List(ctx)
+	if err != nil || len(list) != 1 {
+		t.Fatalf("Expected 1 attendee, got %d (err %v)", len(list), err)
+	}
+}
+
+func TestAttendeeStore_Waitlist(t *testing.T) {
+	ctx := context.Background()
+	attendees := openTestDB(t, testDSN(t), "client-"+t.Name()).Stores().Attendees
+
+	ids := make(map[string]string)
+	for _, name := range []string{"ada", "bob", "cat", "dan"} {
+		attendee := models.Attendee{FullName: name, Email: name + "@example.com", Designation: "Engineer", RegisteredAt: time.Now()}
+		if err := attendees.Create(ctx, &attendee, 2); err != nil {
+			t.Fatalf("Create %s failed: %v", name, err)
+		}
+		ids[name] = attendee.ID
+	}
+	if dan, _ := attendees.Get(ctx, ids["dan"]); dan.Status != models.AttendeeWaitlisted || dan.WaitlistPosition != 2 {
+		t.Fatalf("Expected dan second on the waitlist, got %+v", dan)
+	}
+
+	cancelled, promoted, err := attendees.Cancel(ctx, ids["ada"], 2)
+	if err != nil {
+		t.Fatalf("Cancel failed: %v", err)
+	}
+	if cancelled.Status != models.AttendeeCancelled || cancelled.CancelledAt == nil {
+		t.Errorf("Expected ada cancelled, got %+v", cancelled)
+	}
+	if len(promoted) != 1 || promoted[0].ID != ids["cat"] {
+		t.Errorf("Expected cat promoted, got %+v", promoted)
+	}
+	if dan, _ := attendees.Get(ctx, ids["dan"]); dan.WaitlistPosition != 1 {
+		t.Errorf("Expected dan to move up, got %+v", dan)
+	}
+	if _, _, err := attendees.Cancel(ctx, ids["ada"], 2); err != store.ErrNotFound {
+		t.Errorf("Expected ErrNotFound cancelling twice, got %v", err)
+	}
+
+	again := models.Attendee{FullName: "Ada", Email: "ada@example.com", Designation: "Engineer", RegisteredAt: time.Now()}
+	if err := attendees.Create(ctx, &again, 2); err != nil {
+		t.Fatalf("Expected a cancelled attendee to register again, got %v", err)
+	}
+	if again.Status != models.AttendeeWaitlisted || again.WaitlistPosition != 2 {
+		t.Errorf("Expected ada at the back of the waitlist, got %+v", again)
+	}
+}
+
+func TestAttendeeStore_Verification(t *testing.T) {
+	ctx := context.Background()
+	attendees := openTestDB(t, testDSN(t), "client-"+t.Name()).Stores().Attendees
+
+	confirmed := models.Attendee{FullName: "Ada", Email: "ada@example.com", Designation: "Engineer", RegisteredAt: time.Now()}
+	attendees.Create(ctx, &confirmed, 1)
+	pending := models.Attendee{FullName: "Bob", Email: "bob@example.com", Designation: "Engineer", RegisteredAt: time.Now(), Status: models.AttendeePending}
+	if err := attendees.Create(ctx, &pending, 1); err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	if pending.Status != models.AttendeePending {
+		t.Fatalf("Expected bob to stay pending, got %+v", pending)
+	}
+
+	retry := models.Attendee{FullName: "Mallory", Email: "BOB@example.com", Designation: "Engineer", RegisteredAt: time.Now(), Status: models.AttendeePending}
+	if err := attendees.Create(ctx, &retry, 1); err != store.ErrAlreadyExists {
+		t.Fatalf("Expected a pending registration not to be replaced, got %v", err)
+	}
+
+	if sent, err := attendees.MarkVerificationSent(ctx, pending.ID, time.Now(), time.Minute); err != nil || !sent {
+		t.Fatalf("Expected a never-sent link to be sent, got %v (err %v)", sent, err)
+	}
+	if sent, err := attendees.MarkVerificationSent(ctx, pending.ID, time.Now(), time.Minute); err != nil || sent {
+		t.Errorf("Expected a resend within the cooldown to be refused, got %v (err %v)", sent, err)
+	}
+	if sent, err := attendees.MarkVerificationSent(ctx, pending.ID, time.Now().Add(2*time.Minute), time.Minute); err != nil || !sent {
+		t.Errorf("Expected a resend after the cooldown, got %v (err %v)", sent, err)
+	}
+	if _, err := attendees.MarkVerificationSent(ctx, confirmed.ID, time.Now(), time.Minute); err != store.ErrNotFound {
+		t.Errorf("Expected ErrNotFound for a verified attendee, got %v", err)
+	}
+
+	verified, changed, err := attendees.Verify(ctx, pending.ID, "token-hash", 1)
+	if err != nil || !changed {
+		t.Fatalf("Verify failed: changed %v, err %v", changed, err)
+	}
+	if verified.Status != models.AttendeeWaitlisted || verified.WaitlistPosition != 1 {
+		t.Errorf("Expected bob waitlisted after verifying, got %+v", verified)
+	}
+	if stored, _ := attendees.Get(ctx, pending.ID); stored.FullName != "Bob" || stored.ManageTokenHash != "token-hash" {
+		t.Errorf("Expected bob's registration with the manage token hash, got %+v", stored)
+	}
+	if again, changed, err := attendees.Verify(ctx, pending.ID, "other-hash", 1); err != nil || changed || again.Status != models.AttendeeWaitlisted || again.ManageTokenHash != "token-hash" {
+		t.Errorf("Expected verifying twice to be a no-op, got %+v (changed %v, err %v)", again, changed, err)
+	}
+	if _, _, err := attendees.Verify(ctx, "missing", "token-hash", 1); err != store.ErrNotFound {
+		t.Errorf("Expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestAttendeeStore_PurgePending(t *testing.T) {
+	ctx := context.Background()
+	attendees := openTestDB(t, testDSN(t), "client-"+t.Name()).Stores().Attendees
+
+	old := models.Attendee{FullName: "Ada", Email: "ada@example.com", RegisteredAt: time.Now().Add(-2 * time.Hour), Status: models.AttendeePending}
+	fresh := models.Attendee{FullName: "Bob", Email: "bob@example.com", RegisteredAt: time.Now(), Status: models.AttendeePending}
+	verified := models.Attendee{FullName: "Cat", Email: "cat@example.com", RegisteredAt: time.Now().Add(-2 * time.Hour)}
+	for _, attendee := range []*models.Attendee{&old, &fresh, &verified} {
+		if err := attendees.Create(ctx, attendee, 0); err != nil {
+			t.Fatalf("Create failed: %v", err)
+		}
+	}
+
+	purged, err := attendees.PurgePending(ctx, time.Now().Add(-time.Hour))
+	if err != nil || purged != 1 {
+		t.Fatalf("Expected 1 purged attendee, got %d (err %v)", purged, err)
+	}
+	if _, err := attendees.Get(ctx, old.ID); err != store.ErrNotFound {
+		t.Errorf("Expected the expired registration to be deleted, got %v", err)
+	}
+	list, _ := attendees.List(ctx)
+	if len(list) != 2 {
+		t.Errorf("Expected 2 remaining attendees, got %d", len(list))
+	}
+}
+
+func TestAttendeeStore_CheckIn(t *testing.T) {
+	ctx := context.Background()
+	attendees := openTestDB(t, testDSN(t), "client-"+t.Name()).Stores().Attendees
+
+	ada := models.Attendee{FullName: "Ada", Email: "ada@example.com", RegisteredAt: time.Now()}
+	bob := models.Attendee{FullName: "Bob", Email: "bob@example.com", RegisteredAt: time.Now()}
+	attendees.Create(ctx, &ada, 1)
+	attendees.Create(ctx, &bob, 1)
+
+	checkedIn, err := attendees.CheckIn(ctx, ada.ID, time.Now())
+	if err != nil || checkedIn.CheckedInAt == nil {
+		t.Fatalf("Expected ada checked in, got %+v (err %v)", checkedIn, err)
+	}
+	again, err := attendees.CheckIn(ctx, ada.ID, time.Now())
+	if err != store.ErrAlreadyCheckedIn || !again.CheckedInAt.Equal(*checkedIn.CheckedInAt) {
+		t.Errorf("Expected ErrAlreadyCheckedIn with the first check-in time, got %+v (err %v)", again, err)
+	}
+	if _, err := attendees.CheckIn(ctx, bob.ID, time.Now()); err != store.ErrNotFound {
+		t.Errorf("Expected ErrNotFound for a waitlisted attendee, got %v", err)
+	}
+	if _, err := attendees.CheckIn(ctx, "missing", time.Now()); err != store.ErrNotFound {
+		t.Errorf("Expected ErrNotFound for an unknown attendee, got %v", err)
+	}
+
+	if stats, err := attendees.CheckInStats(ctx); err != nil || stats.Confirmed != 1 || stats.CheckedIn != 1 || stats.Remaining != 0 || stats.ByDesignation[""] != 1 {
+		t.Errorf("Unexpected check-in stats: %+v (err %v)", stats, err)
+	}
+}
+
+func TestAttendeeStore_Each(t *testing.T) {
+	ctx := context.Background()
+	attendees := openTestDB(t, testDSN(t), "client-"+t.Name()).Stores().Attendees
+	defer func(size int) { eachBatchSize = size }(eachBatchSize)
+	eachBatchSize = 2
+
+	// Two attendees share a registration time so the cursor must fall back to the ID
+	base := time.Now().Add(-time.Hour)
+	times := []time.Duration{3, 1, 1, 2, 4}
+	for i, offset := range times {
+		attendee := models.Attendee{FullName: "A", Email: fmt.Sprintf("a%d@example.com", i), RegisteredAt: base.Add(offset * time.Minute)}
+		if err := attendees.Create(ctx, &attendee, 0); err != nil {
+			t.Fatalf("Create failed: %v", err)
+		}
+	}
+
+	var seen []models.Attendee
+	err := attendees.Each(ctx, func(attendee models.Attendee) error {
+		seen = append(seen, attendee)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Each failed: %v", err)
+	}
+	if len(seen) != len(times) {
+		t.Fatalf("Expected %d attendees, got %d", len(times), len(seen))
+	}
+	for i := 1; i < len(seen); i++ {
+		if seen[i].RegisteredAt.Before(seen[i-1].RegisteredAt) {
+			t.Errorf("Expected registration order, got %v before %v", seen[i-1].RegisteredAt, seen[i].RegisteredAt)
+		}
+	}
+
+	stop := errors.New("stop")
+	calls := 0
+	if err := attendees.Each(ctx, func(models.Attendee) error { calls++; return stop }); err != stop || calls != 1 {
+		t.Errorf("Expected Each to stop at the first error, got %v after %d calls", err, calls)
+	}
+}
+
+func TestAttendeeStore_Counts(t *testing.T) {
+	ctx := context.Background()
+	attendees := openTestDB(t, testDSN(t), "client-"+t.Name()).Stores().Attendees
+
+	for i, designation := range []string{"Developer", "Designer", "Developer", "Manager"} {
+		attendee := models.Attendee{FullName: "A", Email: fmt.Sprintf("a%d@example.com", i), Designation: designation, RegisteredAt: time.Now()}
+		if err := attendees.Create(ctx, &attendee, 2); err != nil {
+			t.Fatalf("Create failed: %v", err)
+		}
+	}
+	pending := models.Attendee{FullName: "P", Email: "p@example.com", Designation: "Developer", Status: models.AttendeePending, RegisteredAt: time.Now()}
+	if err := attendees.Create(ctx, &pending, 2); err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+
+	counts, err := attendees.CountByStatus(ctx)
+	if err != nil {
+		t.Fatalf("CountByStatus failed: %v", err)
+	}
+	if counts[models.AttendeeConfirmed] != 2 || counts[models.AttendeeWaitlisted] != 2 || counts[models.AttendeePending] != 1 || counts[models.AttendeeCancelled] != 0 {
+		t.Errorf("Unexpected status counts: %v", counts)
+	}
+
+	byDesignation, err := attendees.CountByDesignation(ctx, models.AttendeeWaitlisted)
+	if err != nil {
+		t.Fatalf("CountByDesignation failed: %v", err)
+	}
+	if len(byDesignation) != 2 || byDesignation["Developer"] != 1 || byDesignation["Manager"] != 1 {
+		t.Errorf("Unexpected waitlisted designations: %v", byDesignation)
+	}
+}
+
+func TestAttendeeStore_Search(t *testing.T) {
+	ctx := context.Background()
+	attendees := openTestDB(t, testDSN(t), "client-"+t.Name()).Stores().Attendees
+
+	// Bob and Cat share a registration time so paging must fall back to the ID
+	base := time.Now().Add(-time.Hour).Truncate(time.Second)
+	for _, a := range []models.Attendee{
+		{FullName: "Ada Lovelace", Email: "ada@example.com", Designation: "Developer", RegisteredAt: base},
+		{FullName: "Bob 100% Real", Email: "bob@example.com", Designation: "Designer", RegisteredAt: base.Add(time.Minute)},
+		{FullName: "Cat", Email: "cat@lovelace.dev", Designation: "developer", RegisteredAt: base.Add(time.Minute)},
+		{FullName: "Ada Byron", Email: "byron@example.com", Designation: "Manager", RegisteredAt: base.Add(2 * time.Minute)},
+	} {
+		a := a
+		if err := attendees.Create(ctx, &a, 0); err != nil {
+			t.Fatalf("Create %s failed: %v", a.Email, err)
+		}
+	}
+
+	names := func(q store.AttendeeQuery) string {
+		t.Helper()
+		page, err := attendees.Search(ctx, q)
+		if err != nil {
+			t.Fatalf("Search failed: %v", err)
+		}
+		var names []string
+		for _, a := range page {
+			names = append(names, a.FullName)
+		}
+		return strings.Join(names, ", ")
+	}
+
+	testCases := []struct {
+		name     string
+		query    store.AttendeeQuery
+		expected string
+	}{
+		{"name or email", store.AttendeeQuery{AttendeeFilter: store.AttendeeFilter{Search: "LOVELACE"}}, "Ada Lovelace, Cat"},
+		{"wildcards are literal", store.AttendeeQuery{AttendeeFilter: store.AttendeeFilter{Search: "0%"}}, "Bob 100% Real"},
+		{"designation ignores case", store.AttendeeQuery{AttendeeFilter: store.AttendeeFilter{Designations: []string{"DEVELOPER"}}}, "Ada Lovelace, Cat"},
+		{"date range", store.AttendeeQuery{AttendeeFilter: store.AttendeeFilter{RegisteredFrom: base.Add(time.Minute), RegisteredTo: base.Add(2 * time.Minute)}, Sort: store.SortFullName}, "Bob 100% Real, Cat"},
+		{"sort by name", store.AttendeeQuery{Sort: store.SortFullName}, "Ada Byron, Ada Lovelace, Bob 100% Real, Cat"},
+		{"descending with limit", store.AttendeeQuery{Sort: store.SortEmail, Descending: true, Limit: 2}, "Cat, Ada Byron"},
+	}
+	for _, tc := range testCases {
+		if got := names(tc.query); got != tc.expected {
+			t.Errorf("%s: expected %q, got %q", tc.name, tc.expected, got)
+		}
+	}
+
+	// Paging one at a time visits everyone once, in order
+	for _, descending := range []bool{false, true} {
+		q := store.AttendeeQuery{Descending: descending, Limit: 1}
+		var seen []string
+		for {
+			page, err := attendees.Search(ctx, q)
+			if err != nil {
+				t.Fatalf("Search failed: %v", err)
+			}
+			if len(page) == 0 {
+				break
+			}
+			seen = append(seen, page[0].Email)
+			cursor := store.CursorAfter(page[0], store.SortRegisteredAt)
+			q.After = &cursor
+		}
+		if len(seen) != 4 || (seen[0] == "ada@example.com") == descending {
+			t.Errorf("Descending %v: expected all 4 attendees in order, got %v", descending, seen)
+		}
+	}
+}
+
+func TestSessionAndSpeakerStore(t *testing.T) {
+	ctx := context.Background()
+	stores := openTestDB(t, testDSN(t), "client-"+t.Name()).Stores()
+
+	speaker := models.Speaker{Name: "Grace Hopper", Bio: "Admiral"}
+	if err := stores.Speakers.Create(ctx, &speaker); err != nil {
+		t.Fatalf("Create speaker failed: %v", err)
+	}
+	speaker.Bio = "Computer scientist"
+	if err := stores.Speakers.Save(ctx, &speaker); err != nil {
+		t.Fatalf("Save speaker failed: %v", err)
+	}
+	got, err := stores.Speakers.Get(ctx, speaker.ID)
+	if err != nil || got.Bio != "Computer scientist" {
+		t.Errorf("Expected updated bio, got %+v (err %v)", got, err)
+	}
+
+	session := models.Session{Title: "COBOL", Description: "History", Time: "10:00", SpeakerID: speaker.ID}
+	if err := stores.Sessions.Create(ctx, &session); err != nil {
+		t.Fatalf("Create session failed: %v", err)
+	}
+	if sessions, err := stores.Sessions.List(ctx); err != nil || len(sessions) != 1 || sessions[0].SpeakerID != speaker.ID {
+		t.Errorf("Unexpected sessions: %+v (err %v)", sessions, err)
+	}
+	if _, err := stores.Sessions.Get(ctx, "missing"); err != store.ErrNotFound {
+		t.Errorf("Expected ErrNotFound for unknown session, got %v", err)
+	}
+}
+
+func TestFieldStore(t *testing.T) {
+	ctx := context.Background()
+	stores := openTestDB(t, testDSN(t), "client-"+t.Name()).Stores()
+
+	if fields, err := stores.Fields.List(ctx); err != nil || len(fields) != 0 {
+		t.Fatalf("Expected an empty form, got %+v (err %v)", fields, err)
+	}
+	form := []models.RegistrationField{
+		{ID: "tshirtSize", Type: models.FieldSelect, Label: "T-shirt size", Required: true, Options: []string{"S", "M"}},
+		{ID: "github", Type: models.FieldText, Label: "GitHub", Pattern: "[a-z-]+"},
+	}
+	if err := stores.Fields.Replace(ctx, form); err != nil {
+		t.Fatalf("Replace failed: %v", err)
+	}
+	if err := stores.Fields.Replace(ctx, form[:1]); err != nil {
+		t.Fatalf("Replace failed: %v", err)
+	}
+	fields, err := stores.Fields.List(ctx)
+	if err != nil || len(fields) != 1 || !fields[0].Required || len(fields[0].Options) != 2 || fields[0].Options[1] != "M" {
+		t.Errorf("Expected the replaced form, got %+v (err %v)", fields, err)
+	}
+
+	for i, size := range []string{"M", "M", "S", ""} {
+		attendee := models.Attendee{FullName: "A", Email: fmt.Sprintf("a%d@example.com", i), Designation: "Developer", RegisteredAt: time.Now()}
+		if size != "" {
+			attendee.Fields = map[string]string{"tshirtSize": size}
+		}
+		if err := stores.Attendees.Create(ctx, &attendee, 0); err != nil {
+			t.Fatalf("Create failed: %v", err)
+		}
+	}
+	got, err := stores.Attendees.Get(ctx, store.AttendeeID("a0@example.com"))
+	if err != nil || got.Fields["tshirtSize"] != "M" {
+		t.Errorf("Expected the answer to be stored, got %+v (err %v)", got, err)
+	}
+	counts, err := stores.Attendees.CountByField(ctx, models.AttendeeConfirmed, "tshirtSize", []string{"S", "M", "L"})
+	if err != nil || len(counts) != 3 || counts["M"] != 2 || counts["S"] != 1 || counts["L"] != 0 {
+		t.Errorf("Unexpected answer counts: %v (err %v)", counts, err)
+	}
+}
+
+func TestTenantIsolation(t *testing.T) {
+	ctx := context.Background()
+	dsn := testDSN(t)
+	tenantA := openTestDB(t, dsn, "tenant-a-"+t.Name()).Stores()
+	tenantB := openTestDB(t, dsn, "tenant-b-"+t.Name()).Stores()
+
+	speaker := models.Speaker{Name: "Ada"}
+	if err := tenantA.Speakers.Create(ctx, &speaker); err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+
+	if _, err := tenantB.Speakers.Get(ctx, speaker.ID); err != store.ErrNotFound {
+		t.Errorf("Expected other tenant's speaker to be invisible, got %v", err)
+	}
+	if speakers, _ := tenantB.Speakers.List(ctx); len(speakers) != 0 {
+		t.Errorf("Expected no speakers for other tenant, got %d", len(speakers))
+	}
+}
+
+func TestLockInstance(t *testing.T) {
+	ctx := context.Background()
+	dsn := testDSN(t)
+	first := openTestDB(t, dsn, "client-"+t.Name())
+	if err := first.LockInstance(ctx); err != nil {
+		t.Fatalf("LockInstance failed: %v", err)
+	}
+
+	second := openTestDB(t, dsn, "client-"+t.Name())
+	err := second.LockInstance(ctx)
+	if second.driver == DriverSQLite {
+		if err != nil {
+			t.Errorf("Expected SQLite not to lock, got %v", err)
+		}
+		return
+	}
+	if err != ErrInstanceRunning {
+		t.Fatalf("Expected ErrInstanceRunning for a second instance, got %v", err)
+	}
+	if err := openTestDB(t, dsn, "other-"+t.Name()).LockInstance(ctx); err != nil {
+		t.Errorf("Expected another tenant to lock independently, got %v", err)
+	}
+	first.Close()
+	if err := second.LockInstance(ctx); err != nil {
+		t.Errorf("Expected the lock to be free after Close, got %v", err)
+	}
+}
+
+func TestMigrateIsIdempotent(t *testing.T) {
+	dsn := testDSN(t)
+	openTestDB(t, dsn, "client")
+	db := openTestDB(t, dsn, "client")
+
+	var version int
+	if err := db.db.QueryRow(`SELECT MAX(version) FROM schema_migrations`).Scan(&version); err != nil {
+		t.Fatalf("Failed to read schema version: %v", err)
+	}
+	if version != len(migrations) {
+		t.Errorf("Expected schema version %d, got %d", len(migrations), version)
+	}
+}
@@ -0,0 +1,111 @@
+package store
+
+import (
+	"strings"
+	"time"
+
+	"event-registration-backend/models"
+)
+
+// AttendeeFilter selects attendees for admin listings and exports. The zero
+// value matches everyone.
+type AttendeeFilter struct {
+	// Search matches a case-insensitive substring of the name or email
+	Search string
+	// Designations and Statuses match any of their values, ignoring case;
+	// empty matches all
+	Designations []string
+	Statuses     []string
+	// RegisteredFrom and RegisteredTo bound the registration time, inclusive
+	// and exclusive respectively; zero values leave that side open
+	RegisteredFrom time.Time
+	RegisteredTo   time.Time
+}
+
+// Match reports whether attendee passes the filter
+func (f AttendeeFilter) Match(attendee models.Attendee) bool {
+	if f.Search != "" {
+		search := strings.ToLower(f.Search)
+		if !strings.Contains(strings.ToLower(attendee.FullName), search) && !strings.Contains(strings.ToLower(attendee.Email), search) {
+			return false
+		}
+	}
+	if len(f.Designations) > 0 && !containsFold(f.Designations, attendee.Designation) {
+		return false
+	}
+	if len(f.Statuses) > 0 && !containsFold(f.Statuses, attendee.Status) {
+		return false
+	}
+	if !f.RegisteredFrom.IsZero() && attendee.RegisteredAt.Before(f.RegisteredFrom) {
+		return false
+	}
+	if !f.RegisteredTo.IsZero() && !attendee.RegisteredAt.Before(f.RegisteredTo) {
+		return false
+	}
+	return true
+}
+
+// Apply returns the attendees that pass the filter
+func (f AttendeeFilter) Apply(attendees []models.Attendee) []models.Attendee {
+	var matched []models.Attendee
+	for _, attendee := range attendees {
+		if f.Match(attendee) {
+			matched = append(matched, attendee)
+		}
+	}
+	return matched
+}
+
+func containsFold(values []string, s string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// Fields an attendee query can sort by
+const (
+	SortRegisteredAt = "registeredAt"
+	SortFullName     = "fullName"
+	SortEmail        = "email"
+)
+
+// AttendeeQuery is one page of a filtered, sorted attendee listing. Attendees
+// that tie on the sort field are ordered by ID, so every attendee has a fixed
+// position and pages neither repeat nor skip anyone as others register.
+type AttendeeQuery struct {
+	AttendeeFilter
+	// Sort is one of the Sort constants; empty sorts by SortRegisteredAt
+	Sort       string
+	Descending bool
+	// After, if set, starts the page after this position
+	After *AttendeeCursor
+	// Limit caps the page size; 0 returns every match
+	Limit int
+}
+
+// AttendeeCursor is a position in a sorted listing: the sort field value and
+// ID of the attendee just before it
+type AttendeeCursor struct {
+	// Key is a time.Time for SortRegisteredAt and a string otherwise
+	Key interface{}
+	ID  string
+}
+
+// CursorAfter returns the position just after attendee in a listing sorted by sort
+func CursorAfter(attendee models.Attendee, sort string) AttendeeCursor {
+	return AttendeeCursor{Key: SortKey(attendee, sort), ID: attendee.ID}
+}
+
+// SortKey returns the value of attendee's sort field
+func SortKey(attendee models.Attendee, sort string) interface{} {
+	switch sort {
+	case SortFullName:
+		return attendee.FullName
+	case SortEmail:
+		return attendee.Email
+	}
+	return attendee.RegisteredAt
+}
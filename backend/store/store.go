@@ -0,0 +1,132 @@
+// Package store defines the domain repositories the handlers depend on.
+// Implementations live with their backend, e.g. the firestore package.
+package store
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"time"
+
+	"event-registration-backend/models"
+)
+
+var (
+	// ErrNotFound is returned when the requested record does not exist
+	ErrNotFound = errors.New("record not found")
+	// ErrAlreadyExists is returned when creating a record that must be unique
+	ErrAlreadyExists = errors.New("record already exists")
+	// ErrAlreadyCheckedIn is returned when checking in an attendee twice
+	ErrAlreadyCheckedIn = errors.New("attendee already checked in")
+)
+
+// AttendeeID derives an attendee's ID from their email, so that the store's
+// primary key enforces one registration per address
+func AttendeeID(email string) string {
+	sum := sha256.Sum256([]byte(strings.ToLower(strings.TrimSpace(email))))
+	return hex.EncodeToString(sum[:])
+}
+
+// AttendeeStore persists event registrations. Capacity is the number of
+// confirmed seats, 0 meaning unlimited; see Seat and Cancel for the rules.
+type AttendeeStore interface {
+	// Create stores a new attendee under AttendeeID(attendee.Email), confirmed
+	// or waitlisted depending on capacity, and sets its ID, Status and
+	// WaitlistPosition. An attendee created with status pending stays pending
+	// until Verify. A cancelled registration with the same email is replaced.
+	// It returns ErrAlreadyExists if the email is already registered, pending
+	// verification included.
+	Create(ctx context.Context, attendee *models.Attendee, capacity int) error
+	// CheckAvailable returns ErrAlreadyExists if Create would refuse a
+	// registration for email, without writing anything
+	CheckAvailable(ctx context.Context, email string) error
+	// Get returns the attendee with id, or ErrNotFound
+	Get(ctx context.Context, id string) (*models.Attendee, error)
+	List(ctx context.Context) ([]models.Attendee, error)
+	// CountByStatus returns the number of attendees with each status,
+	// counted by the backend without reading the registrations
+	CountByStatus(ctx context.Context) (map[string]int, error)
+	// CountByDesignation returns the number of attendees with status per
+	// designation
+	CountByDesignation(ctx context.Context, status string) (map[string]int, error)
+	// CountByField returns the number of attendees with status who gave each
+	// of options as their answer to the registration field with id, listing
+	// every option
+	CountByField(ctx context.Context, status, id string, options []string) (map[string]int, error)
+	// Search returns the attendees matching q in its sort order, starting
+	// after q.After and at most q.Limit of them
+	Search(ctx context.Context, q AttendeeQuery) ([]models.Attendee, error)
+	// Each calls fn for every attendee in registration order, reading them
+	// incrementally rather than all at once. It stops at and returns the
+	// first error from fn.
+	Each(ctx context.Context, fn func(models.Attendee) error) error
+	// UpdateDetails changes the name and designation of the attendee with id
+	// and returns the result. It returns ErrNotFound if there is no such
+	// active registration.
+	UpdateDetails(ctx context.Context, id, fullName, designation string) (*models.Attendee, error)
+	// Cancel cancels the attendee with id and promotes waitlisted attendees
+	// into freed seats, returning the cancelled attendee and those promoted.
+	// It returns ErrNotFound if there is no such active registration.
+	Cancel(ctx context.Context, id string, capacity int) (*models.Attendee, []models.Attendee, error)
+	// Verify confirms or waitlists the pending attendee with id, storing
+	// manageTokenHash as its manage token, and returns the result, reporting
+	// whether it was pending; verified attendees are returned unchanged. It
+	// returns ErrNotFound if there is no such active registration.
+	Verify(ctx context.Context, id, manageTokenHash string, capacity int) (*models.Attendee, bool, error)
+	// MarkVerificationSent records that the pending attendee with id is sent
+	// its verification link at, unless it was sent less than cooldown
+	// earlier, and reports whether it recorded the send. It returns
+	// ErrNotFound if there is no such pending registration.
+	MarkVerificationSent(ctx context.Context, id string, at time.Time, cooldown time.Duration) (bool, error)
+	// PurgePending deletes pending attendees registered before cutoff and
+	// returns how many were deleted
+	PurgePending(ctx context.Context, cutoff time.Time) (int, error)
+	// CheckIn records that the confirmed attendee with id arrived at and
+	// returns the result. It returns ErrNotFound if there is no such confirmed
+	// registration, and the attendee with ErrAlreadyCheckedIn if it was
+	// checked in before.
+	CheckIn(ctx context.Context, id string, at time.Time) (*models.Attendee, error)
+	// CheckInStats counts the confirmed attendees and those of them checked
+	// in, without reading the registrations
+	CheckInStats(ctx context.Context) (*models.CheckInStats, error)
+}
+
+// SessionStore persists agenda sessions
+type SessionStore interface {
+	// Get returns the session with id, or ErrNotFound
+	Get(ctx context.Context, id string) (*models.Session, error)
+	List(ctx context.Context) ([]models.Session, error)
+	// Create stores a new session and sets its ID
+	Create(ctx context.Context, session *models.Session) error
+	// Save creates or replaces the session with session.ID
+	Save(ctx context.Context, session *models.Session) error
+}
+
+// SpeakerStore persists speaker profiles
+type SpeakerStore interface {
+	// Get returns the speaker with id, or ErrNotFound
+	Get(ctx context.Context, id string) (*models.Speaker, error)
+	List(ctx context.Context) ([]models.Speaker, error)
+	// Create stores a new speaker and sets its ID
+	Create(ctx context.Context, speaker *models.Speaker) error
+	// Save creates or replaces the speaker with speaker.ID
+	Save(ctx context.Context, speaker *models.Speaker) error
+}
+
+// FieldStore persists the event's extra registration fields
+type FieldStore interface {
+	// List returns the fields in form order
+	List(ctx context.Context) ([]models.RegistrationField, error)
+	// Replace sets the form to fields, in order
+	Replace(ctx context.Context, fields []models.RegistrationField) error
+}
+
+// Stores bundles the repositories of one storage backend
+type Stores struct {
+	Attendees AttendeeStore
+	Sessions  SessionStore
+	Speakers  SpeakerStore
+	Fields    FieldStore
+}
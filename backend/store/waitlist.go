@@ -0,0 +1,120 @@
+package store
+
+import (
+	"time"
+
+	"event-registration-backend/models"
+)
+
+// The functions below implement the capacity and waitlist rules shared by every
+// backend. Callers run them inside a transaction, keep count of the confirmed
+// and waitlisted attendees and persist the attendees they modify. A capacity
+// of 0 means unlimited seats.
+
+// Tally counts confirmed and waitlisted attendees; cancelled and unverified
+// ones are skipped
+func Tally(attendees []models.Attendee) (confirmed, waitlisted int) {
+	for _, attendee := range attendees {
+		switch attendee.Status {
+		case models.AttendeeWaitlisted:
+			waitlisted++
+		case models.AttendeeCancelled, models.AttendeePending:
+		default:
+			confirmed++
+		}
+	}
+	return confirmed, waitlisted
+}
+
+// HasSeat reports whether another attendee can be confirmed while confirmed
+// seats are taken
+func HasSeat(confirmed, capacity int) bool {
+	return capacity <= 0 || confirmed < capacity
+}
+
+// Seat sets the status of a new registration: confirmed while seats remain
+// and nobody is waiting, otherwise at the back of the waitlist. A pending
+// registration holds no seat until it is verified.
+func Seat(attendee *models.Attendee, confirmed, waitlisted, capacity int) {
+	attendee.CancelledAt = nil
+	if attendee.Status == models.AttendeePending {
+		attendee.WaitlistPosition = 0
+		return
+	}
+	if HasSeat(confirmed, capacity) && waitlisted == 0 {
+		attendee.Status = models.AttendeeConfirmed
+		attendee.WaitlistPosition = 0
+		return
+	}
+	attendee.Status = models.AttendeeWaitlisted
+	attendee.WaitlistPosition = waitlisted + 1
+}
+
+// Verify seats the pending attendee as if it registered now and reports
+// whether it changed. An attendee that is already verified is left unchanged.
+func Verify(attendee *models.Attendee, confirmed, waitlisted, capacity int) bool {
+	if attendee.Status != models.AttendeePending {
+		return false
+	}
+	attendee.Status = ""
+	Seat(attendee, confirmed, waitlisted, capacity)
+	return true
+}
+
+// Expired returns the pending attendees registered before cutoff
+func Expired(attendees []models.Attendee, cutoff time.Time) []models.Attendee {
+	var expired []models.Attendee
+	for _, attendee := range attendees {
+		if attendee.Status == models.AttendeePending && attendee.RegisteredAt.Before(cutoff) {
+			expired = append(expired, attendee)
+		}
+	}
+	return expired
+}
+
+// Cancel marks attendee cancelled and returns the waitlist position it
+// vacated, or 0 if it was not waitlisted
+func Cancel(attendee *models.Attendee, now time.Time) int {
+	vacated := 0
+	if attendee.Status == models.AttendeeWaitlisted {
+		vacated = attendee.WaitlistPosition
+	}
+	attendee.Status = models.AttendeeCancelled
+	attendee.WaitlistPosition = 0
+	attendee.CancelledAt = &now
+	return vacated
+}
+
+// Promote confirms a waitlisted attendee and returns the waitlist position
+// it vacated
+func Promote(attendee *models.Attendee) int {
+	vacated := attendee.WaitlistPosition
+	attendee.Status = models.AttendeeConfirmed
+	attendee.WaitlistPosition = 0
+	return vacated
+}
+
+// Renumber closes the gaps left at the vacated waitlist positions. waitlist
+// holds the waitlisted attendees behind the first vacated position; those at
+// a vacated position are skipped. It returns the attendees whose position
+// changed.
+func Renumber(waitlist []models.Attendee, vacated []int) []models.Attendee {
+	var changed []models.Attendee
+	for _, attendee := range waitlist {
+		shift := 0
+		for _, position := range vacated {
+			if position == attendee.WaitlistPosition {
+				shift = -1
+				break
+			}
+			if position < attendee.WaitlistPosition {
+				shift++
+			}
+		}
+		if shift > 0 {
+			attendee.WaitlistPosition -= shift
+			changed = append(changed, attendee)
+		}
+	}
+	return changed
+}
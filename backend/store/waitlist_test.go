@@ -0,0 +1,126 @@
+package store
+
+import (
+	"testing"
+	"time"
+
+	"event-registration-backend/models"
+)
+
+// seat registers attendees with the given IDs in order, as the stores do
+func seat(capacity int, ids ...string) []models.Attendee {
+	var attendees []models.Attendee
+	for _, id := range ids {
+		attendee := models.Attendee{ID: id}
+		confirmed, waitlisted := Tally(attendees)
+		Seat(&attendee, confirmed, waitlisted, capacity)
+		attendees = append(attendees, attendee)
+	}
+	return attendees
+}
+
+func TestSeat(t *testing.T) {
+	attendees := seat(2, "a", "b", "c", "d")
+
+	want := []struct {
+		status   string
+		position int
+	}{
+		{models.AttendeeConfirmed, 0},
+		{models.AttendeeConfirmed, 0},
+		{models.AttendeeWaitlisted, 1},
+		{models.AttendeeWaitlisted, 2},
+	}
+	for i, w := range want {
+		if attendees[i].Status != w.status || attendees[i].WaitlistPosition != w.position {
+			t.Errorf("Attendee %s: got %s/%d, want %s/%d", attendees[i].ID, attendees[i].Status, attendees[i].WaitlistPosition, w.status, w.position)
+		}
+	}
+
+	if unlimited := seat(0, "a", "b", "c"); unlimited[2].Status != models.AttendeeConfirmed {
+		t.Errorf("Expected unlimited capacity to confirm everyone, got %+v", unlimited[2])
+	}
+}
+
+func TestTally_LegacyAttendeesCountAsConfirmed(t *testing.T) {
+	attendees := []models.Attendee{{ID: "legacy"}, {ID: "gone", Status: models.AttendeeCancelled}}
+	if confirmed, waitlisted := Tally(attendees); confirmed != 1 || waitlisted != 0 {
+		t.Errorf("Expected an attendee without status to hold a seat, got %d/%d", confirmed, waitlisted)
+	}
+}
+
+func TestCancel(t *testing.T) {
+	attendees := seat(1, "a", "b")
+	now := time.Now()
+
+	if vacated := Cancel(&attendees[0], now); vacated != 0 {
+		t.Errorf("Expected a confirmed attendee to vacate no waitlist position, got %d", vacated)
+	}
+	if attendees[0].Status != models.AttendeeCancelled || !attendees[0].CancelledAt.Equal(now) {
+		t.Errorf("Expected a cancelled, got %+v", attendees[0])
+	}
+	if vacated := Cancel(&attendees[1], now); vacated != 1 || attendees[1].WaitlistPosition != 0 {
+		t.Errorf("Expected b to vacate position 1, got %d and %+v", vacated, attendees[1])
+	}
+}
+
+func TestRenumber(t *testing.T) {
+	attendees := seat(1, "a", "b", "c", "d", "e")
+
+	// b is promoted and d cancels: c moves up one place and e two
+	vacated := []int{Promote(&attendees[1]), Cancel(&attendees[3], time.Now())}
+	waitlist := []models.Attendee{
+		{ID: "b", Status: models.AttendeeWaitlisted, WaitlistPosition: 1},
+		attendees[2],
+		{ID: "d", Status: models.AttendeeWaitlisted, WaitlistPosition: 3},
+		attendees[4],
+	}
+	changed := Renumber(waitlist, vacated)
+	if len(changed) != 2 || changed[0].ID != "c" || changed[0].WaitlistPosition != 1 || changed[1].ID != "e" || changed[1].WaitlistPosition != 2 {
+		t.Errorf("Expected c and e renumbered to 1 and 2, got %+v", changed)
+	}
+	if attendees[1].Status != models.AttendeeConfirmed || attendees[1].WaitlistPosition != 0 {
+		t.Errorf("Expected b confirmed, got %+v", attendees[1])
+	}
+	if HasSeat(1, 1) || !HasSeat(0, 1) || !HasSeat(5, 0) {
+		t.Error("Unexpected HasSeat result")
+	}
+}
+
+func TestVerify_SeatsPendingAttendee(t *testing.T) {
+	attendees := seat(1, "a")
+	pending := models.Attendee{ID: "b", Status: models.AttendeePending}
+	Seat(&pending, 1, 0, 1)
+	if pending.Status != models.AttendeePending {
+		t.Fatalf("Expected a pending registration to stay pending, got %s", pending.Status)
+	}
+	attendees = append(attendees, pending)
+	confirmed, waitlisted := Tally(attendees)
+	if confirmed != 1 || waitlisted != 0 {
+		t.Errorf("Expected pending attendees not to be counted, got %d/%d", confirmed, waitlisted)
+	}
+
+	if !Verify(&pending, confirmed, waitlisted, 1) {
+		t.Fatal("Expected verifying a pending attendee to change it")
+	}
+	if pending.Status != models.AttendeeWaitlisted || pending.WaitlistPosition != 1 {
+		t.Errorf("Expected b to be waitlisted at 1 after verifying, got %s/%d", pending.Status, pending.WaitlistPosition)
+	}
+	if Verify(&pending, confirmed, waitlisted+1, 1) || pending.WaitlistPosition != 1 {
+		t.Errorf("Expected verifying twice to be a no-op, got %+v", pending)
+	}
+}
+
+func TestExpired(t *testing.T) {
+	now := time.Now()
+	attendees := []models.Attendee{
+		{ID: "old", Status: models.AttendeePending, RegisteredAt: now.Add(-2 * time.Hour)},
+		{ID: "fresh", Status: models.AttendeePending, RegisteredAt: now},
+		{ID: "confirmed", Status: models.AttendeeConfirmed, RegisteredAt: now.Add(-2 * time.Hour)},
+	}
+
+	expired := Expired(attendees, now.Add(-time.Hour))
+	if len(expired) != 1 || expired[0].ID != "old" {
+		t.Errorf("Expected only the old pending attendee to expire, got %+v", expired)
+	}
+}
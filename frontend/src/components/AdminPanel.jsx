@@ -1,14 +1,22 @@
 import { useState, useEffect } from 'react';
-import { adminLogin, getAttendees, getAttendeeStats, createOrUpdateSpeaker, createOrUpdateSession, getSpeakers, getSessions } from '../services/api';
+import { adminLogin, adminSSOLoginURL, setAuthToken, getAttendees, getAttendeeStats, getCheckInStats, downloadBadges, exportAttendees, importAttendees, createOrUpdateSpeaker, createOrUpdateSession, getSpeakers, getSessions, getRegistrationFields, updateRegistrationFields } from '../services/api';
 import PieChart from './PieChart';
 
 function AdminPanel({ onClose }) {
   const [isAuthenticated, setIsAuthenticated] = useState(false);
+  const [username, setUsername] = useState('');
   const [password, setPassword] = useState('');
   const [loginError, setLoginError] = useState('');
   const [loading, setLoading] = useState(false);
   const [attendees, setAttendees] = useState([]);
+  const [attendeeQuery, setAttendeeQuery] = useState({ q: '', sort: 'registeredAt', order: 'asc' });
+  const [nextPageToken, setNextPageToken] = useState(null);
   const [stats, setStats] = useState({});
+  const [statsField, setStatsField] = useState('');
+  const [checkInStats, setCheckInStats] = useState(null);
+  const [importFile, setImportFile] = useState(null);
+  const [importResult, setImportResult] = useState(null);
+  const [importError, setImportError] = useState('');
   const [speakers, setSpeakers] = useState([]);
   const [sessions, setSessions] = useState([]);
   const [activeTab, setActiveTab] = useState('attendees');
@@ -21,6 +29,21 @@ function AdminPanel({ onClose }) {
   const [sessionForm, setSessionForm] = useState({ id: '', title: '', description: '', time: '', speakerId: '' });
   const [sessionFormError, setSessionFormError] = useState('');
 
+  // Registration form fields; select options are edited as comma-separated text
+  const [formFields, setFormFields] = useState([]);
+  const [formFieldsError, setFormFieldsError] = useState('');
+
+  // Pick up a session token handed back by single sign-on in the URL fragment
+  useEffect(() => {
+    const params = new URLSearchParams(window.location.hash.slice(1));
+    const token = params.get('token');
+    if (token) {
+      setAuthToken(token);
+      setIsAuthenticated(true);
+      window.history.replaceState(null, '', window.location.pathname + window.location.search);
+    }
+  }, []);
+
   useEffect(() => {
     if (isAuthenticated) {
       fetchData();
@@ -35,8 +58,36 @@ function AdminPanel({ onClose }) {
       fetchSessions();
       fetchSpeakers(); // Load speakers for dropdown
     }
+    if (isAuthenticated && (activeTab === 'form' || activeTab === 'stats')) {
+      fetchFormFields();
+    }
   }, [isAuthenticated, activeTab]);
 
+  useEffect(() => {
+    if (isAuthenticated) {
+      fetchStats(statsField);
+    }
+  }, [isAuthenticated, statsField]);
+
+  const fetchFormFields = async () => {
+    try {
+      const response = await getRegistrationFields();
+      setFormFields(response.data.fields.map((field) => ({ ...field, optionsText: (field.options || []).join(', ') })));
+    } catch (err) {
+      console.error('Failed to fetch registration fields:', err);
+    }
+  };
+
+  // Loads the designation breakdown, or the answers to a select field when one is chosen
+  const fetchStats = async (field) => {
+    try {
+      const response = await getAttendeeStats(field ? { field } : undefined);
+      setStats(response.data);
+    } catch (err) {
+      console.error('Failed to fetch attendee stats:', err);
+    }
+  };
+
   const fetchSpeakers = async () => {
     try {
       const response = await getSpeakers();
@@ -57,28 +108,90 @@ function AdminPanel({ onClose }) {
 
   const fetchData = async () => {
     try {
-      const [attendeesRes, statsRes] = await Promise.all([
-        getAttendees(),
-        getAttendeeStats(),
+      const [attendeesRes, checkInRes] = await Promise.all([
+        getAttendees(attendeeQuery),
+        getCheckInStats(),
       ]);
       setAttendees(attendeesRes.data);
-      setStats(statsRes.data);
+      setNextPageToken(attendeesRes.headers['x-next-page-token'] || null);
+      setCheckInStats(checkInRes.data);
+      fetchStats(statsField);
     } catch (err) {
       console.error('Failed to fetch admin data:', err);
     }
   };
 
+  // Loads the first page for the current search, or the next page when a token is given
+  const loadAttendees = async (pageToken) => {
+    try {
+      const response = await getAttendees({ ...attendeeQuery, pageToken });
+      setAttendees(pageToken ? [...attendees, ...response.data] : response.data);
+      setNextPageToken(response.headers['x-next-page-token'] || null);
+    } catch (err) {
+      console.error('Failed to fetch attendees:', err);
+    }
+  };
+
+  const handleAttendeeSearch = (e) => {
+    e.preventDefault();
+    loadAttendees();
+  };
+
+  const saveFile = (blob, filename) => {
+    const url = URL.createObjectURL(blob);
+    const link = document.createElement('a');
+    link.href = url;
+    link.download = filename;
+    link.click();
+    URL.revokeObjectURL(url);
+  };
+
+  const handleDownloadBadges = async () => {
+    try {
+      const response = await downloadBadges();
+      saveFile(response.data, 'badges.pdf');
+    } catch (err) {
+      console.error('Failed to download badges:', err);
+    }
+  };
+
+  const handleExport = async (format) => {
+    try {
+      const tz = Intl.DateTimeFormat().resolvedOptions().timeZone;
+      const response = await exportAttendees({ format, tz });
+      saveFile(response.data, `attendees.${format}`);
+    } catch (err) {
+      console.error('Failed to export attendees:', err);
+    }
+  };
+
+  const handleImport = async (dryRun) => {
+    setImportError('');
+    setImportResult(null);
+    try {
+      const response = await importAttendees(importFile, { dryRun });
+      setImportResult(response.data);
+      if (!dryRun) {
+        fetchData();
+      }
+    } catch (err) {
+      const fields = err.response?.data?.fields;
+      setImportError(fields ? Object.values(fields).join('. ') : (err.response?.data || 'Import failed'));
+    }
+  };
+
   const handleLogin = async (e) => {
     e.preventDefault();
     setLoginError('');
     setLoading(true);
 
     try {
-      const response = await adminLogin(password);
+      const response = await adminLogin(username, password);
       if (response.data.success) {
+        setAuthToken(response.data.token);
         setIsAuthenticated(true);
       } else {
-        setLoginError(response.data.message || 'Invalid password');
+        setLoginError(response.data.message || 'Invalid username or password');
       }
     } catch (err) {
       setLoginError('Login failed. Please try again.');
@@ -121,6 +234,31 @@ function AdminPanel({ onClose }) {
     }
   };
 
+  const updateFormField = (index, changes) => {
+    setFormFields(formFields.map((field, i) => (i === index ? { ...field, ...changes } : field)));
+  };
+
+  const handleFormFieldsSubmit = async (e) => {
+    e.preventDefault();
+    setFormFieldsError('');
+    setLoading(true);
+
+    const fields = formFields.map(({ optionsText, ...field }) => ({
+      ...field,
+      options: field.type === 'select' ? optionsText.split(',').map((option) => option.trim()).filter(Boolean) : undefined,
+      pattern: field.type === 'text' ? field.pattern : undefined,
+    }));
+    try {
+      await updateRegistrationFields(fields);
+      fetchFormFields();
+    } catch (err) {
+      const errors = err.response?.data?.fields;
+      setFormFieldsError(errors ? Object.entries(errors).map(([field, msg]) => `${field} ${msg}`).join('. ') : 'Failed to save registration form');
+    } finally {
+      setLoading(false);
+    }
+  };
+
   const editSpeaker = (speaker) => {
     setSpeakerForm({
       id: speaker.id,
@@ -151,6 +289,17 @@ function AdminPanel({ onClose }) {
             <button className="modal-close" onClick={onClose}>×</button>
           </div>
           <form onSubmit={handleLogin} className="admin-login-form">
+            <div className="form-group">
+              <label htmlFor="admin-username">Username</label>
+              <input
+                type="text"
+                id="admin-username"
+                value={username}
+                onChange={(e) => setUsername(e.target.value)}
+                autoComplete="username"
+                required
+              />
+            </div>
             <div className="form-group">
               <label htmlFor="admin-password">Password</label>
               <input
@@ -165,6 +314,7 @@ function AdminPanel({ onClose }) {
             <button type="submit" className="admin-login-button" disabled={loading}>
               {loading ? 'Logging in...' : 'Login'}
             </button>
+            <a href={adminSSOLoginURL} className="admin-sso-link">Sign in with company SSO</a>
           </form>
         </div>
       </div>
@@ -202,6 +352,12 @@ function AdminPanel({ onClose }) {
           >
             Sessions
           </button>
+          <button
+            className={activeTab === 'form' ? 'active' : ''}
+            onClick={() => setActiveTab('form')}
+          >
+            Registration Form
+          </button>
           <button
             className={activeTab === 'stats' ? 'active' : ''}
             onClick={() => setActiveTab('stats')}
@@ -213,6 +369,48 @@ function AdminPanel({ onClose }) {
           {activeTab === 'attendees' && (
             <div className="admin-section">
               <h3>Attendee Details</h3>
+              <button className="admin-submit-button" onClick={handleDownloadBadges}>Download badges (PDF)</button>
+              <button className="admin-submit-button" onClick={() => handleExport('csv')}>Export CSV</button>
+              <button className="admin-submit-button" onClick={() => handleExport('xlsx')}>Export Excel</button>
+              <div>
+                <input type="file" accept=".csv,text/csv" onChange={(e) => { setImportFile(e.target.files[0] || null); setImportResult(null); }} />
+                <button className="admin-submit-button" disabled={!importFile} onClick={() => handleImport(true)}>Check CSV</button>
+                <button className="admin-submit-button" disabled={!importFile} onClick={() => handleImport(false)}>Import CSV</button>
+                {importError && <div className="error-message">{importError}</div>}
+                {importResult && (
+                  <div>
+                    <p>
+                      {importResult.dryRun ? 'Would import' : 'Imported'} {importResult.imported} of {importResult.rows} rows
+                      {importResult.errors.length > 0 && `; ${importResult.errors.length} rows have errors`}
+                    </p>
+                    <ul>
+                      {importResult.errors.map((rowErr) => (
+                        <li key={rowErr.line}>
+                          Line {rowErr.line}{rowErr.email && ` (${rowErr.email})`}: {rowErr.error || Object.entries(rowErr.fields).map(([field, msg]) => `${field} ${msg}`).join(', ')}
+                        </li>
+                      ))}
+                    </ul>
+                  </div>
+                )}
+              </div>
+              <form onSubmit={handleAttendeeSearch}>
+                <input
+                  type="search"
+                  placeholder="Search name or email"
+                  value={attendeeQuery.q}
+                  onChange={(e) => setAttendeeQuery({ ...attendeeQuery, q: e.target.value })}
+                />
+                <select value={attendeeQuery.sort} onChange={(e) => setAttendeeQuery({ ...attendeeQuery, sort: e.target.value })}>
+                  <option value="registeredAt">Registration time</option>
+                  <option value="fullName">Name</option>
+                  <option value="email">Email</option>
+                </select>
+                <select value={attendeeQuery.order} onChange={(e) => setAttendeeQuery({ ...attendeeQuery, order: e.target.value })}>
+                  <option value="asc">Ascending</option>
+                  <option value="desc">Descending</option>
+                </select>
+                <button type="submit" className="admin-submit-button">Search</button>
+              </form>
               <div className="table-container">
                 <table className="admin-table">
                   <thead>
@@ -241,6 +439,9 @@ function AdminPanel({ onClose }) {
                   </tbody>
                 </table>
               </div>
+              {nextPageToken && (
+                <button className="admin-submit-button" onClick={() => loadAttendees(nextPageToken)}>Load more</button>
+              )}
             </div>
           )}
           {activeTab === 'speakers' && (
@@ -355,9 +556,89 @@ function AdminPanel({ onClose }) {
               )}
             </div>
           )}
+          {activeTab === 'form' && (
+            <div className="admin-section">
+              <h3>Extra Registration Fields</h3>
+              <form onSubmit={handleFormFieldsSubmit} className="admin-form">
+                {formFields.map((field, index) => (
+                  <div key={index} className="existing-item">
+                    <input
+                      type="text"
+                      placeholder="id (e.g. tshirtSize)"
+                      value={field.id}
+                      onChange={(e) => updateFormField(index, { id: e.target.value })}
+                      required
+                    />
+                    <input
+                      type="text"
+                      placeholder="Label"
+                      value={field.label}
+                      onChange={(e) => updateFormField(index, { label: e.target.value })}
+                      required
+                    />
+                    <select value={field.type} onChange={(e) => updateFormField(index, { type: e.target.value })}>
+                      <option value="text">Text</option>
+                      <option value="number">Number</option>
+                      <option value="select">Select</option>
+                      <option value="checkbox">Checkbox</option>
+                    </select>
+                    {field.type === 'select' && (
+                      <input
+                        type="text"
+                        placeholder="Options, comma-separated"
+                        value={field.optionsText}
+                        onChange={(e) => updateFormField(index, { optionsText: e.target.value })}
+                        required
+                      />
+                    )}
+                    {field.type === 'text' && (
+                      <input
+                        type="text"
+                        placeholder="Validation regex (optional)"
+                        value={field.pattern || ''}
+                        onChange={(e) => updateFormField(index, { pattern: e.target.value })}
+                      />
+                    )}
+                    <label>
+                      <input
+                        type="checkbox"
+                        checked={field.required}
+                        onChange={(e) => updateFormField(index, { required: e.target.checked })}
+                      />
+                      Required
+                    </label>
+                    <button type="button" onClick={() => setFormFields(formFields.filter((_, i) => i !== index))}>Remove</button>
+                  </div>
+                ))}
+                <button
+                  type="button"
+                  onClick={() => setFormFields([...formFields, { id: '', label: '', type: 'text', required: false, optionsText: '', pattern: '' }])}
+                >
+                  Add Field
+                </button>
+                {formFieldsError && <div className="error-message">{formFieldsError}</div>}
+                <button type="submit" className="admin-submit-button" disabled={loading}>
+                  Save Registration Form
+                </button>
+              </form>
+            </div>
+          )}
           {activeTab === 'stats' && (
             <div className="admin-section">
-              <h3>Attendee Breakdown by Designation</h3>
+              {checkInStats && (
+                <p className="checkin-summary">
+                  Checked in: {checkInStats.checkedIn} of {checkInStats.confirmed} ({checkInStats.remaining} still to arrive)
+                </p>
+              )}
+              <h3>Attendee Breakdown by {formFields.find((field) => field.id === statsField)?.label || 'Designation'}</h3>
+              <select value={statsField} onChange={(e) => setStatsField(e.target.value)}>
+                <option value="">Designation</option>
+                {formFields.filter((field) => field.type === 'select').map((field) => (
+                  <option key={field.id} value={field.id}>
+                    {field.label}
+                  </option>
+                ))}
+              </select>
               {chartData.length > 0 ? (
                 <PieChart data={chartData} />
               ) : (
@@ -1,13 +1,26 @@
-function ConfirmationModal({ onClose }) {
+function ConfirmationModal({ onClose, attendee }) {
+  const waitlisted = attendee?.status === 'waitlisted';
+  const pending = attendee?.status === 'pending';
+
+  let title = 'Registration Successful!';
+  if (pending) title = 'Check Your Email';
+  else if (waitlisted) title = "You're on the Waitlist";
+
   return (
     <div className="modal-overlay" onClick={onClose}>
       <div className="modal-content" onClick={(e) => e.stopPropagation()}>
         <div className="modal-header">
-          <h3>Registration Successful!</h3>
+          <h3>{title}</h3>
           <button className="modal-close" onClick={onClose}>×</button>
         </div>
         <div className="modal-body">
-          <p>Thank you for registering. We look forward to seeing you at the event!</p>
+          {pending ? (
+            <p>We sent a confirmation link to {attendee.email}. Follow it to complete your registration.</p>
+          ) : waitlisted ? (
+            <p>The event is full. You are {attendee.waitlistPosition ? `number ${attendee.waitlistPosition}` : 'now'} on the waitlist and will be confirmed automatically if a seat frees up.</p>
+          ) : (
+            <p>Thank you for registering. We look forward to seeing you at the event!</p>
+          )}
         </div>
         <div className="modal-footer">
           <button className="modal-button" onClick={onClose}>Close</button>
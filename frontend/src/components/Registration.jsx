@@ -1,5 +1,5 @@
 import { useState, useEffect } from 'react';
-import { getAttendeeCount, registerAttendee } from '../services/api';
+import { getAttendeeCount, getRegistrationFields, registerAttendee } from '../services/api';
 import ConfirmationModal from './ConfirmationModal';
 
 const DESIGNATIONS = [
@@ -13,17 +13,85 @@ const DESIGNATIONS = [
   'Other',
 ];
 
+// renderFieldInput returns the input for one organiser-defined field. Answers
+// are sent as strings, checkboxes as "true" or "false".
+function renderFieldInput(field, value, onChange) {
+  const id = `field-${field.id}`;
+  switch (field.type) {
+    case 'select':
+      return (
+        <select id={id} value={value} onChange={(e) => onChange(e.target.value)} required={field.required}>
+          <option value="">Select {field.label}</option>
+          {field.options.map((option) => (
+            <option key={option} value={option}>
+              {option}
+            </option>
+          ))}
+        </select>
+      );
+    case 'checkbox':
+      return (
+        <input
+          type="checkbox"
+          id={id}
+          checked={value === 'true'}
+          onChange={(e) => onChange(String(e.target.checked))}
+          required={field.required}
+        />
+      );
+    case 'number':
+      return <input type="number" step="any" id={id} value={value} onChange={(e) => onChange(e.target.value)} required={field.required} />;
+    default:
+      return (
+        <input
+          type="text"
+          id={id}
+          value={value}
+          onChange={(e) => onChange(e.target.value)}
+          pattern={field.pattern || undefined}
+          required={field.required}
+        />
+      );
+  }
+}
+
 function Registration() {
   const [attendeeCount, setAttendeeCount] = useState(0);
+  const [registered, setRegistered] = useState(null);
   const [formData, setFormData] = useState({
     fullName: '',
     email: '',
     designation: '',
   });
+  // Extra questions defined by the organisers, answered by field id
+  const [customFields, setCustomFields] = useState([]);
+  const [answers, setAnswers] = useState({});
   const [loading, setLoading] = useState(false);
   const [error, setError] = useState(null);
   const [showConfirmation, setShowConfirmation] = useState(false);
 
+  // The emailed verification link redirects here with the outcome
+  useEffect(() => {
+    const params = new URLSearchParams(window.location.search);
+    const verification = params.get('verification');
+    if (!verification) return;
+    window.history.replaceState(null, '', window.location.pathname);
+    if (verification === 'expired') {
+      setError('Your verification link has expired. Please register again.');
+    } else if (verification === 'invalid') {
+      setError('This verification link is not valid. Please register again.');
+    } else {
+      setRegistered({ status: verification });
+      setShowConfirmation(true);
+    }
+  }, []);
+
+  useEffect(() => {
+    getRegistrationFields()
+      .then((response) => setCustomFields(response.data?.fields || []))
+      .catch((err) => console.error('Failed to fetch registration fields:', err));
+  }, []);
+
   useEffect(() => {
     fetchCount();
     const interval = setInterval(fetchCount, 3000); // Poll every 3 seconds
@@ -61,13 +129,22 @@ function Registration() {
     }
 
     try {
-      await registerAttendee(formData);
+      const response = await registerAttendee({ ...formData, fields: answers });
+      setRegistered(response.data);
       setShowConfirmation(true);
       setFormData({ fullName: '', email: '', designation: '' });
+      setAnswers({});
       fetchCount(); // Refresh count immediately
     } catch (err) {
+      const fields = err.response?.data?.fields;
       if (err.response?.status === 409) {
         setError('This email is already registered');
+      } else if (err.response?.status === 400 && fields) {
+        const labels = { fullName: 'Full name', email: 'Email', designation: 'Designation' };
+        customFields.forEach((field) => {
+          labels[`fields.${field.id}`] = field.label;
+        });
+        setError(Object.entries(fields).map(([field, msg]) => `${labels[field] || field} ${msg}`).join('. '));
       } else {
         setError('Registration failed. Please try again.');
       }
@@ -129,6 +206,14 @@ function Registration() {
                   ))}
                 </select>
               </div>
+              {customFields.map((field) => (
+                <div className="form-group" key={field.id}>
+                  <label htmlFor={`field-${field.id}`}>{field.label}</label>
+                  {renderFieldInput(field, answers[field.id] || '', (value) =>
+                    setAnswers({ ...answers, [field.id]: value })
+                  )}
+                </div>
+              ))}
               {error && <div className="error-message">{error}</div>}
               <button type="submit" className="register-button" disabled={loading}>
                 {loading ? 'Registering...' : 'Register'}
@@ -138,7 +223,7 @@ function Registration() {
         </div>
       </div>
       {showConfirmation && (
-        <ConfirmationModal onClose={() => setShowConfirmation(false)} />
+        <ConfirmationModal attendee={registered} onClose={() => setShowConfirmation(false)} />
       )}
     </section>
   );
@@ -37,14 +37,32 @@ api.interceptors.response.use(
 export const getSessions = () => api.get('/api/sessions');
 export const getSpeakers = () => api.get('/api/speakers');
 export const getAttendeeCount = () => api.get('/api/attendees/count');
+export const getRegistrationFields = () => api.get('/api/registration/fields');
 export const registerAttendee = (data) => api.post('/api/attendees/register', data);
 
+// Admin session token, sent as a Bearer token on every request once set
+export const setAuthToken = (token) => {
+  if (token) {
+    api.defaults.headers.common['Authorization'] = `Bearer ${token}`;
+  } else {
+    delete api.defaults.headers.common['Authorization'];
+  }
+};
+
 // Admin APIs
-export const adminLogin = (password) => api.post('/api/admin/login', { password });
-export const getAttendees = () => api.get('/api/admin/attendees');
-export const getAttendeeStats = () => api.get('/api/admin/stats');
+export const adminLogin = (username, password) => api.post('/api/admin/login', { username, password });
+export const adminLogout = () => api.post('/api/admin/logout');
+export const adminSSOLoginURL = `${API_URL}/api/admin/oidc/login`;
+export const getAttendees = (params) => api.get('/api/admin/attendees', { params });
+export const getAttendeeStats = (params) => api.get('/api/admin/stats', { params });
+export const getCheckInStats = () => api.get('/api/admin/checkin/stats');
+export const downloadBadges = (params) => api.get('/api/admin/attendees/badges', { params, responseType: 'blob' });
+export const exportAttendees = (params) => api.get('/api/admin/attendees/export', { params, responseType: 'blob' });
+export const importAttendees = (file, params) => api.post('/api/admin/attendees/import', file, { params, headers: { 'Content-Type': 'text/csv' } });
+export const checkInAttendee = (code) => api.post('/api/admin/checkin', { code });
 export const createOrUpdateSpeaker = (data) => api.post('/api/admin/speakers', data);
 export const createOrUpdateSession = (data) => api.post('/api/admin/sessions', data);
+export const updateRegistrationFields = (fields) => api.put('/api/admin/registration/fields', { fields });
 
 export default api;
 